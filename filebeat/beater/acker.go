@@ -2,6 +2,7 @@ package beater
 
 import (
 	"github.com/elastic/beats/filebeat/input/file"
+	"github.com/elastic/beats/filebeat/util"
 	"github.com/elastic/beats/libbeat/logp"
 )
 
@@ -36,6 +37,9 @@ func (a *eventACKer) ackEvents(data []interface{}) {
 
 		st, ok := datum.(file.State)
 		if !ok {
+			if acker, ok := datum.(util.EventACKer); ok {
+				acker.ACK()
+			}
 			stateless++
 			continue
 		}
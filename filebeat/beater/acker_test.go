@@ -25,6 +25,14 @@ func (sl *mockStatelessLogger) Published(count int) bool {
 	return true
 }
 
+type mockEventACKer struct {
+	count int
+}
+
+func (a *mockEventACKer) ACK() {
+	a.count++
+}
+
 func TestACKer(t *testing.T) {
 	tests := []struct {
 		name      string
@@ -54,6 +62,12 @@ func TestACKer(t *testing.T) {
 			data:      []interface{}{struct{}{}, nil},
 			stateless: 2,
 		},
+		{
+			name:      "event ACKer",
+			data:      []interface{}{&mockEventACKer{}, file.State{Source: "-"}, &mockEventACKer{}},
+			stateful:  []file.State{file.State{Source: "-"}},
+			stateless: 2,
+		},
 	}
 
 	for _, test := range tests {
@@ -66,6 +80,12 @@ func TestACKer(t *testing.T) {
 			h.ackEvents(test.data)
 			assert.Equal(t, test.stateless, sl.count)
 			assert.Equal(t, test.stateful, sf.states)
+
+			for _, datum := range test.data {
+				if acker, ok := datum.(*mockEventACKer); ok {
+					assert.Equal(t, 1, acker.count)
+				}
+			}
 		})
 	}
 }
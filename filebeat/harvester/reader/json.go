@@ -22,6 +22,13 @@ func NewJSON(r Reader, cfg *JSONConfig) *JSON {
 	return &JSON{reader: r, cfg: cfg}
 }
 
+// DecodeJSON decodes a single JSON document, like the JSON reader does for
+// every line. It returns the text of the message key and the decoded fields.
+func DecodeJSON(text []byte, cfg *JSONConfig) ([]byte, common.MapStr) {
+	r := JSON{cfg: cfg}
+	return r.decodeJSON(text)
+}
+
 // decodeJSON unmarshals the text parameter into a MapStr and
 // returns the new text column if one was requested.
 func (r *JSON) decodeJSON(text []byte) ([]byte, common.MapStr) {
@@ -9,6 +9,9 @@ package include
 import (
 	// This list is automatically generated by `make imports`
 	_ "github.com/elastic/beats/filebeat/input/docker"
+	_ "github.com/elastic/beats/filebeat/input/http_endpoint"
+	_ "github.com/elastic/beats/filebeat/input/journald"
+	_ "github.com/elastic/beats/filebeat/input/kafka"
 	_ "github.com/elastic/beats/filebeat/input/log"
 	_ "github.com/elastic/beats/filebeat/input/redis"
 	_ "github.com/elastic/beats/filebeat/input/stdin"
@@ -21,6 +21,7 @@ type State struct {
 	TTL         time.Duration     `json:"ttl"`
 	Type        string            `json:"type"`
 	Meta        map[string]string `json:"meta"`
+	Cursor      string            `json:"cursor,omitempty"` // position of inputs not reading files
 	FileStateOS file.StateOS
 }
 
@@ -0,0 +1,95 @@
+package http_endpoint
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/libbeat/common/cfgtype"
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+type config struct {
+	harvester.ForwarderConfig `config:",inline"`
+
+	ListenAddress  string                   `config:"listen_address"`
+	ListenPort     int                      `config:"listen_port" validate:"min=0,max=65535"`
+	Path           string                   `config:"path"`
+	TLS            *outputs.TLSServerConfig `config:"ssl"`
+	Username       string                   `config:"username"`
+	Password       string                   `config:"password"`
+	HMAC           hmacConfig               `config:"hmac"`
+	MaxBodySize    cfgtype.ByteSize         `config:"max_body_size" validate:"nonzero,positive"`
+	PublishTimeout time.Duration            `config:"publish_timeout" validate:"nonzero,positive"`
+	JSON           jsonConfig               `config:"json"`
+}
+
+// hmacConfig configures the verification of request signatures. The
+// signature is the hex encoded HMAC of the request body. Signatures are
+// verified if a key is configured.
+type hmacConfig struct {
+	Header string `config:"header"`
+	Key    string `config:"key"`
+	Type   string `config:"type"`
+	Prefix string `config:"prefix"`
+}
+
+type jsonConfig struct {
+	KeysUnderRoot bool `config:"keys_under_root"`
+	OverwriteKeys bool `config:"overwrite_keys"`
+}
+
+var defaultConfig = config{
+	ForwarderConfig: harvester.ForwarderConfig{
+		Type: "http_endpoint",
+	},
+	ListenAddress:  "localhost",
+	ListenPort:     8080,
+	Path:           "/",
+	MaxBodySize:    10 * humanize.MiByte,
+	PublishTimeout: 5 * time.Second,
+	HMAC: hmacConfig{
+		Header: "X-Signature",
+		Type:   "sha256",
+	},
+}
+
+var hmacTypes = map[string]func() hash.Hash{
+	"sha1":   sha1.New,
+	"sha256": sha256.New,
+	"sha512": sha512.New,
+}
+
+func (c *config) Validate() error {
+	if !strings.HasPrefix(c.Path, "/") {
+		return fmt.Errorf("path '%v' must start with /", c.Path)
+	}
+
+	if c.Username != "" && c.Password == "" {
+		return errors.New("password must be set when username is configured")
+	}
+	if c.Username == "" && c.Password != "" {
+		return errors.New("username must be set when password is configured")
+	}
+
+	if _, ok := hmacTypes[c.HMAC.Type]; !ok {
+		return fmt.Errorf("invalid hmac.type '%v', must be one of sha1, sha256 or sha512", c.HMAC.Type)
+	}
+	if c.HMAC.Key != "" && c.HMAC.Header == "" {
+		return errors.New("hmac.header must be set when hmac.key is configured")
+	}
+
+	return nil
+}
+
+func (c *config) address() string {
+	return fmt.Sprintf("%v:%v", c.ListenAddress, c.ListenPort)
+}
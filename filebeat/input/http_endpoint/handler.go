@@ -0,0 +1,220 @@
+package http_endpoint
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/jsontransform"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+var (
+	errEmptyBody     = errors.New("request body contains no JSON objects")
+	errNotAnObject   = errors.New("request body must contain JSON objects only")
+	errBodyTooLarge  = errors.New("request body too large")
+	errUnauthorized  = errors.New("invalid username or password")
+	errBadSignature  = errors.New("invalid request signature")
+	errPipelineBlock = errors.New("events can not be published at the moment, retry later")
+)
+
+// handler decodes the JSON objects of POST requests and passes the events to
+// the publish loop of the input.
+type handler struct {
+	config  *config
+	publish chan<- []*util.Data
+	done    <-chan struct{}
+	log     *logp.Logger
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != h.config.Path {
+		sendResponse(w, http.StatusNotFound, "not found")
+		return
+	}
+
+	if r.Method != "POST" {
+		w.Header().Set("Allow", "POST")
+		sendResponse(w, http.StatusMethodNotAllowed, "only POST requests are supported")
+		return
+	}
+
+	if !h.authorized(r) {
+		w.Header().Set("WWW-Authenticate", `Basic realm="filebeat"`)
+		sendError(w, http.StatusUnauthorized, errUnauthorized)
+		return
+	}
+
+	body, err := h.readBody(r.Body)
+	if err != nil {
+		status := http.StatusBadRequest
+		if err == errBodyTooLarge {
+			status = http.StatusRequestEntityTooLarge
+		}
+		sendError(w, status, err)
+		return
+	}
+
+	if !h.validSignature(r, body) {
+		sendError(w, http.StatusUnauthorized, errBadSignature)
+		return
+	}
+
+	objs, err := decodeBody(body)
+	if err != nil {
+		sendError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	events := make([]*util.Data, len(objs))
+	for i, obj := range objs {
+		events[i] = h.createEvent(obj, r.RemoteAddr)
+	}
+
+	// The publish loop only accepts new events after the previous events
+	// have been passed to the pipeline. If the pipeline is blocked the
+	// client is asked to retry.
+	timer := time.NewTimer(h.config.PublishTimeout)
+	defer timer.Stop()
+
+	select {
+	case h.publish <- events:
+		sendResponse(w, http.StatusAccepted, fmt.Sprintf("%d events accepted", len(events)))
+	case <-timer.C:
+		h.log.Debugw("Pipeline blocked, rejecting request", "events", len(events))
+		w.Header().Set("Retry-After", "1")
+		sendError(w, http.StatusServiceUnavailable, errPipelineBlock)
+	case <-h.done:
+		sendError(w, http.StatusServiceUnavailable, errPipelineBlock)
+	}
+}
+
+func (h *handler) authorized(r *http.Request) bool {
+	if h.config.Username == "" {
+		return true
+	}
+
+	username, password, ok := r.BasicAuth()
+	if !ok {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(username), []byte(h.config.Username)) == 1 &&
+		subtle.ConstantTimeCompare([]byte(password), []byte(h.config.Password)) == 1
+}
+
+// validSignature verifies the HMAC of the request body, if a key is configured.
+func (h *handler) validSignature(r *http.Request, body []byte) bool {
+	cfg := h.config.HMAC
+	if cfg.Key == "" {
+		return true
+	}
+
+	value := r.Header.Get(cfg.Header)
+	if !strings.HasPrefix(value, cfg.Prefix) {
+		return false
+	}
+	signature, err := hex.DecodeString(strings.TrimPrefix(value, cfg.Prefix))
+	if err != nil {
+		return false
+	}
+
+	mac := hmac.New(hmacTypes[cfg.Type], []byte(cfg.Key))
+	mac.Write(body)
+	return hmac.Equal(signature, mac.Sum(nil))
+}
+
+func (h *handler) readBody(body io.Reader) ([]byte, error) {
+	max := int64(h.config.MaxBodySize)
+	b, err := ioutil.ReadAll(io.LimitReader(body, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(b)) > max {
+		return nil, errBodyTooLarge
+	}
+	return b, nil
+}
+
+func (h *handler) createEvent(obj common.MapStr, remoteAddr string) *util.Data {
+	data := util.NewData()
+	data.Event = beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"source": remoteAddr,
+		},
+	}
+
+	if h.config.JSON.KeysUnderRoot {
+		jsontransform.WriteJSONKeys(&data.Event, obj, h.config.JSON.OverwriteKeys)
+	} else {
+		data.Event.Fields["json"] = obj
+	}
+	return data
+}
+
+// decodeBody decodes a single JSON object, an array of objects or newline
+// delimited objects.
+func decodeBody(body []byte) ([]common.MapStr, error) {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 {
+		return nil, errEmptyBody
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+
+	var values []interface{}
+	if body[0] == '[' {
+		if err := dec.Decode(&values); err != nil {
+			return nil, fmt.Errorf("failed to decode JSON array: %v", err)
+		}
+		if dec.More() {
+			return nil, errors.New("unexpected data after JSON array")
+		}
+	} else {
+		for dec.More() {
+			var value interface{}
+			if err := dec.Decode(&value); err != nil {
+				return nil, fmt.Errorf("failed to decode JSON object: %v", err)
+			}
+			values = append(values, value)
+		}
+	}
+
+	if len(values) == 0 {
+		return nil, errEmptyBody
+	}
+
+	objs := make([]common.MapStr, len(values))
+	for i, value := range values {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return nil, errNotAnObject
+		}
+		jsontransform.TransformNumbers(obj)
+		objs[i] = obj
+	}
+	return objs, nil
+}
+
+func sendError(w http.ResponseWriter, status int, err error) {
+	sendResponse(w, status, err.Error())
+}
+
+func sendResponse(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(common.MapStr{"message": message})
+}
@@ -0,0 +1,163 @@
+package http_endpoint
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/input"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+func init() {
+	err := input.Register("http_endpoint", NewInput)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Input receives JSON events pushed via HTTP POST requests.
+type Input struct {
+	sync.Mutex
+	config    *config
+	server    *http.Server
+	tlsConfig *tls.Config
+	outlet    channel.Outleter
+	forwarder *harvester.Forwarder
+	publish   chan []*util.Data
+	started   bool
+	stopOnce  sync.Once
+	done      chan struct{}
+	wg        sync.WaitGroup
+	log       *logp.Logger
+}
+
+// NewInput creates a new http_endpoint input.
+func NewInput(
+	cfg *common.Config,
+	outlet channel.Factory,
+	context input.Context,
+) (input.Input, error) {
+	cfgwarn.Experimental("HTTP endpoint input type is used")
+
+	config := defaultConfig
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+
+	tlsConfig, err := outputs.LoadTLSServerConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := outlet(cfg, context.DynamicFields)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Input{
+		config:    &config,
+		outlet:    out,
+		forwarder: harvester.NewForwarder(out),
+		publish:   make(chan []*util.Data),
+		done:      make(chan struct{}),
+		log:       logp.NewLogger("http_endpoint input").With("address", config.address()),
+	}
+	if tlsConfig != nil {
+		p.tlsConfig = tlsConfig.BuildServerConfig()
+	}
+
+	p.server = &http.Server{
+		Addr: config.address(),
+		Handler: &handler{
+			config:  p.config,
+			publish: p.publish,
+			done:    p.done,
+			log:     p.log,
+		},
+	}
+	return p, nil
+}
+
+// Run starts listening for HTTP requests.
+func (p *Input) Run() {
+	p.Lock()
+	defer p.Unlock()
+
+	if p.started {
+		return
+	}
+
+	p.log.Infow("Starting HTTP endpoint input", "path", p.config.Path, "tls", p.tlsConfig != nil)
+	listener, err := net.Listen("tcp", p.server.Addr)
+	if err != nil {
+		p.log.Errorw("Error starting the HTTP server", "error", err)
+		return
+	}
+	if p.tlsConfig != nil {
+		listener = tls.NewListener(listener, p.tlsConfig)
+	}
+	p.started = true
+
+	p.wg.Add(2)
+	go func() {
+		defer p.wg.Done()
+		err := p.server.Serve(listener)
+		if err != nil && err != http.ErrServerClosed {
+			p.log.Errorw("HTTP server failed", "error", err)
+		}
+	}()
+	go func() {
+		defer p.wg.Done()
+		p.run()
+	}()
+}
+
+// run passes the events received by the handler to the pipeline. Sending
+// blocks while the pipeline is full, such that the handler can tell the
+// pipeline is blocked.
+func (p *Input) run() {
+	for {
+		select {
+		case <-p.done:
+			return
+		case events := <-p.publish:
+			for _, data := range events {
+				if err := p.forwarder.Send(data); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Stop stops the HTTP server. Requests in progress are finished first.
+func (p *Input) Stop() {
+	p.stopOnce.Do(func() {
+		p.log.Info("Stopping HTTP endpoint input")
+		close(p.done)
+
+		ctx, cancel := context.WithTimeout(context.Background(), p.config.PublishTimeout+time.Second)
+		defer cancel()
+		if err := p.server.Shutdown(ctx); err != nil {
+			p.log.Errorw("Failed to shut down the HTTP server", "error", err)
+		}
+
+		p.outlet.Close()
+		p.wg.Wait()
+	})
+}
+
+// Wait stops the HTTP server.
+func (p *Input) Wait() {
+	p.Stop()
+}
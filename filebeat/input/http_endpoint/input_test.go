@@ -0,0 +1,291 @@
+// +build !integration
+
+package http_endpoint
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/input"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs/transport/transptest"
+)
+
+type testOutlet struct {
+	events chan *util.Data
+}
+
+func (o *testOutlet) OnEvent(data *util.Data) bool {
+	o.events <- data
+	return true
+}
+
+func (o *testOutlet) Close() error {
+	return nil
+}
+
+func newTestHandler(t *testing.T, settings map[string]interface{}) (*handler, chan []*util.Data) {
+	cfg, err := common.NewConfigFrom(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := defaultConfig
+	if err := cfg.Unpack(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	publish := make(chan []*util.Data, 1)
+	return &handler{
+		config:  &config,
+		publish: publish,
+		done:    make(chan struct{}),
+		log:     logp.NewLogger("http_endpoint test"),
+	}, publish
+}
+
+func post(h http.Handler, path, body string, header map[string]string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("POST", path, strings.NewReader(body))
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	return w
+}
+
+func freePort(t *testing.T) int {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port
+}
+
+func TestDecodeBody(t *testing.T) {
+	tests := map[string]int{
+		`{"a": 1}`:                     1,
+		`[{"a": 1}, {"b": 2}]`:         2,
+		"{\"a\": 1}\n{\"b\": 2}\n":     2,
+		"\n  {\"a\": 1}\r\n{\"b\": 2}": 2,
+	}
+	for body, expected := range tests {
+		objs, err := decodeBody([]byte(body))
+		if err != nil {
+			t.Fatalf("%v: %v", body, err)
+		}
+		assert.Len(t, objs, expected, body)
+	}
+
+	objs, err := decodeBody([]byte(`{"a": 1, "b": {"c": 1.5}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, int64(1), objs[0]["a"])
+	c, _ := objs[0].GetValue("b.c")
+	assert.Equal(t, 1.5, c)
+
+	for _, body := range []string{``, ` `, `[]`, `"a"`, `[{"a": 1}, 2]`, `{"a": 1} x`, `[{"a": 1}] {}`, `{"a":`} {
+		_, err := decodeBody([]byte(body))
+		assert.Error(t, err, body)
+	}
+}
+
+func TestHandlerPublishesEvents(t *testing.T) {
+	h, publish := newTestHandler(t, map[string]interface{}{"path": "/logs"})
+
+	w := post(h, "/logs", `[{"message": "a"}, {"message": "b"}]`, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	events := <-publish
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %v", len(events))
+	}
+	message, _ := events[1].Event.GetValue("json.message")
+	assert.Equal(t, "b", message)
+	source, _ := events[1].Event.GetValue("source")
+	assert.NotEmpty(t, source)
+}
+
+func TestHandlerKeysUnderRoot(t *testing.T) {
+	h, publish := newTestHandler(t, map[string]interface{}{
+		"json.keys_under_root": true,
+		"json.overwrite_keys":  true,
+	})
+
+	w := post(h, "/", `{"message": "a", "@timestamp": "2018-06-01T10:00:00.000Z"}`, nil)
+	assert.Equal(t, http.StatusAccepted, w.Code)
+
+	events := <-publish
+	message, _ := events[0].Event.GetValue("message")
+	assert.Equal(t, "a", message)
+	assert.Equal(t, time.Date(2018, 6, 1, 10, 0, 0, 0, time.UTC), events[0].Event.Timestamp.UTC())
+}
+
+func TestHandlerRejectsRequests(t *testing.T) {
+	h, _ := newTestHandler(t, map[string]interface{}{
+		"path":          "/logs",
+		"max_body_size": 20,
+	})
+
+	assert.Equal(t, http.StatusNotFound, post(h, "/other", `{}`, nil).Code)
+	assert.Equal(t, http.StatusBadRequest, post(h, "/logs", `not json`, nil).Code)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, post(h, "/logs", `{"message": "too large"}`, nil).Code)
+
+	req := httptest.NewRequest("GET", "/logs", nil)
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestHandlerBasicAuth(t *testing.T) {
+	h, _ := newTestHandler(t, map[string]interface{}{
+		"username": "beats",
+		"password": "secret",
+	})
+
+	tests := map[string]int{
+		"":             http.StatusUnauthorized,
+		"beats:wrong":  http.StatusUnauthorized,
+		"beats:secret": http.StatusAccepted,
+	}
+	for credentials, expected := range tests {
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{}`))
+		if parts := strings.SplitN(credentials, ":", 2); len(parts) == 2 {
+			req.SetBasicAuth(parts[0], parts[1])
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+		assert.Equal(t, expected, w.Code, credentials)
+	}
+}
+
+func TestHandlerHMAC(t *testing.T) {
+	h, _ := newTestHandler(t, map[string]interface{}{
+		"hmac.key":    "secret",
+		"hmac.header": "X-Hub-Signature-256",
+		"hmac.prefix": "sha256=",
+	})
+
+	body := `{"message": "a"}`
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(body))
+	signature := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	assert.Equal(t, http.StatusUnauthorized, post(h, "/", body, nil).Code)
+	assert.Equal(t, http.StatusUnauthorized, post(h, "/", body, map[string]string{"X-Hub-Signature-256": "sha256=00"}).Code)
+	assert.Equal(t, http.StatusUnauthorized, post(h, "/", body, map[string]string{"X-Hub-Signature-256": signature[7:]}).Code)
+	assert.Equal(t, http.StatusAccepted, post(h, "/", body, map[string]string{"X-Hub-Signature-256": signature}).Code)
+}
+
+func TestHandlerPipelineBlocked(t *testing.T) {
+	h, publish := newTestHandler(t, map[string]interface{}{
+		"publish_timeout": "10ms",
+	})
+
+	// fill the publish channel, nobody is reading
+	publish <- nil
+
+	w := post(h, "/", `{"message": "a"}`, nil)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+}
+
+func TestInputTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "http_endpoint")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	certName := filepath.Join(dir, "server")
+	transptest.GenCertForTestingPurpose(t, "127.0.0.1", certName, "")
+
+	port := freePort(t)
+	cfg, err := common.NewConfigFrom(map[string]interface{}{
+		"listen_address":  "127.0.0.1",
+		"listen_port":     port,
+		"ssl.certificate": certName + ".pem",
+		"ssl.key":         certName + ".key",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &testOutlet{events: make(chan *util.Data, 10)}
+	factory := func(*common.Config, *common.MapStrPointer) (channel.Outleter, error) {
+		return out, nil
+	}
+
+	p, err := NewInput(cfg, factory, input.Context{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p.Run()
+	defer p.Stop()
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+		},
+	}
+	resp, err := client.Post("https://127.0.0.1:"+strconv.Itoa(port)+"/", "application/json",
+		bytes.NewBufferString("{\"message\": \"a\"}\n{\"message\": \"b\"}\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	assert.Equal(t, http.StatusAccepted, resp.StatusCode)
+
+	for _, expected := range []string{"a", "b"} {
+		select {
+		case data := <-out.events:
+			message, _ := data.Event.GetValue("json.message")
+			assert.Equal(t, expected, message)
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for event")
+		}
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"invalid path":         {"path": "logs"},
+		"missing password":     {"username": "beats"},
+		"missing username":     {"password": "secret"},
+		"invalid hmac type":    {"hmac.key": "secret", "hmac.type": "md5"},
+		"empty hmac header":    {"hmac.key": "secret", "hmac.header": ""},
+		"missing ssl key":      {"ssl.certificate": "cert.pem"},
+		"invalid port":         {"listen_port": 70000},
+		"zero publish timeout": {"publish_timeout": 0},
+	}
+
+	for name, settings := range tests {
+		cfg, err := common.NewConfigFrom(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c := defaultConfig
+		assert.Error(t, cfg.Unpack(&c), name)
+	}
+}
@@ -0,0 +1,79 @@
+// +build linux
+
+package journald
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/input/journald/journal"
+)
+
+// Seek modes
+const (
+	seekCursor = "cursor"
+	seekHead   = "head"
+	seekTail   = "tail"
+)
+
+var defaultConfig = config{
+	ForwarderConfig: harvester.ForwarderConfig{
+		Type: "journald",
+	},
+	Paths:              []string{"/var/log/journal", "/run/log/journal"},
+	Seek:               seekCursor,
+	CursorSeekFallback: seekHead,
+	Backoff:            1 * time.Second,
+	MaxBackoff:         20 * time.Second,
+}
+
+type config struct {
+	harvester.ForwarderConfig `config:",inline"`
+
+	// ID identifies the input in the registry. Defaults to a value derived
+	// from paths and matches.
+	ID string `config:"id"`
+
+	Paths              []string      `config:"paths"`
+	Seek               string        `config:"seek"`
+	CursorSeekFallback string        `config:"cursor_seek_fallback"`
+	Matches            []string      `config:"matches"`
+	Backoff            time.Duration `config:"backoff" validate:"min=0,nonzero"`
+	MaxBackoff         time.Duration `config:"max_backoff" validate:"min=0,nonzero"`
+}
+
+func (c *config) Validate() error {
+	if len(c.Paths) == 0 {
+		return fmt.Errorf("No paths were defined for the journald input")
+	}
+
+	switch c.Seek {
+	case seekCursor, seekHead, seekTail:
+	default:
+		return fmt.Errorf("Invalid seek mode '%v', must be one of cursor, head or tail", c.Seek)
+	}
+
+	switch c.CursorSeekFallback {
+	case seekHead, seekTail:
+	default:
+		return fmt.Errorf("Invalid cursor_seek_fallback '%v', must be head or tail", c.CursorSeekFallback)
+	}
+
+	if c.MaxBackoff < c.Backoff {
+		return fmt.Errorf("max_backoff must be greater or equal to backoff")
+	}
+
+	_, err := parseMatches(c.Matches)
+	return err
+}
+
+// parseMatches parses the configured matches. Matches can use journal field
+// names or the names of the event fields they are mapped to.
+func parseMatches(matches []string) (journal.Matches, error) {
+	translated := make([]string, len(matches))
+	for i, m := range matches {
+		translated[i] = translateMatch(m)
+	}
+	return journal.ParseMatches(translated)
+}
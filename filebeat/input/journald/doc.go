@@ -0,0 +1,5 @@
+/*
+Package journald implements the journald input, reading the systemd journal
+files directly. The input is only supported on Linux.
+*/
+package journald
@@ -0,0 +1,217 @@
+// +build linux
+
+package journald
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/mitchellh/hashstructure"
+
+	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/input"
+	"github.com/elastic/beats/filebeat/input/file"
+	"github.com/elastic/beats/filebeat/input/journald/journal"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+func init() {
+	err := input.Register("journald", NewInput)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Input reads entries from the systemd journal. The cursor of the last
+// published entry is stored in the registry, such that reading continues
+// after the last acknowledged entry on restart.
+type Input struct {
+	sync.Mutex
+	config    config
+	id        string
+	matches   journal.Matches
+	cursor    string // cursor loaded from the registry
+	outlet    channel.Outleter
+	forwarder *harvester.Forwarder
+	started   bool
+	done      chan struct{}
+	stopOnce  sync.Once
+	wg        sync.WaitGroup
+	log       *logp.Logger
+}
+
+// NewInput creates a new journald input.
+func NewInput(
+	cfg *common.Config,
+	outlet channel.Factory,
+	context input.Context,
+) (input.Input, error) {
+	cfgwarn.Experimental("Journald input type is used")
+
+	config := defaultConfig
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+
+	matches, err := parseMatches(config.Matches)
+	if err != nil {
+		return nil, err
+	}
+
+	id := config.ID
+	if id == "" {
+		hash, err := hashstructure.Hash(struct {
+			Paths   []string
+			Matches []string
+		}{config.Paths, config.Matches}, nil)
+		if err != nil {
+			return nil, err
+		}
+		id = fmt.Sprintf("%x", hash)
+	}
+
+	out, err := outlet(cfg, context.DynamicFields)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &Input{
+		config:    config,
+		id:        id,
+		matches:   matches,
+		outlet:    out,
+		forwarder: harvester.NewForwarder(out),
+		done:      make(chan struct{}),
+		log:       logp.NewLogger("journald").With("id", id),
+	}
+	p.loadCursor(context.States)
+	return p, nil
+}
+
+// loadCursor loads the cursor of the last published entry from the registry
+// states.
+func (p *Input) loadCursor(states []file.State) {
+	source := p.source()
+	for _, state := range states {
+		if state.Source == source && state.Cursor != "" {
+			p.cursor = state.Cursor
+		}
+	}
+}
+
+func (p *Input) source() string {
+	return "journald::" + p.id
+}
+
+// Run starts reading the journal.
+func (p *Input) Run() {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.started {
+		p.log.Infow("Starting journald input", "paths", p.config.Paths)
+		p.started = true
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.run()
+		}()
+	}
+}
+
+func (p *Input) run() {
+	reader := journal.NewReader(p.config.Paths, p.matches)
+	defer reader.Close()
+
+	p.seek(reader)
+
+	backoff := p.config.Backoff
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			select {
+			case <-p.done:
+				return
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+			if backoff > p.config.MaxBackoff {
+				backoff = p.config.MaxBackoff
+			}
+			continue
+		}
+		if err != nil {
+			p.log.Errorw("Failed to read journal entry", "error", err)
+			continue
+		}
+		backoff = p.config.Backoff
+
+		if err := p.forwarder.Send(p.createData(entry)); err != nil {
+			return
+		}
+	}
+}
+
+func (p *Input) seek(reader *journal.Reader) {
+	mode := p.config.Seek
+	if mode == seekCursor {
+		if p.cursor != "" {
+			cursor, err := journal.ParseCursor(p.cursor)
+			if err == nil {
+				p.log.Debugw("Seeking to cursor", "cursor", p.cursor)
+				reader.SeekCursor(cursor)
+				return
+			}
+			p.log.Errorw("Failed to parse cursor from registry", "cursor", p.cursor, "error", err)
+		}
+		mode = p.config.CursorSeekFallback
+	}
+
+	if mode == seekTail {
+		reader.SeekTail()
+	} else {
+		reader.SeekHead()
+	}
+}
+
+func (p *Input) createData(entry *journal.Entry) *util.Data {
+	data := util.NewData()
+	data.Event = beat.Event{
+		Timestamp: entry.Realtime(),
+		Fields:    toEventFields(entry),
+	}
+
+	state := file.State{
+		Source:    p.source(),
+		Timestamp: time.Now(),
+		TTL:       -1,
+		Type:      p.config.Type,
+		Meta:      map[string]string{"journald": p.id},
+		Cursor:    entry.Cursor.String(),
+	}
+	data.SetState(state)
+	return data
+}
+
+// Stop stops the journald input.
+func (p *Input) Stop() {
+	p.stopOnce.Do(func() {
+		p.log.Info("Stopping journald input")
+		close(p.done)
+		p.outlet.Close()
+		p.wg.Wait()
+	})
+}
+
+// Wait stops the journald input.
+func (p *Input) Wait() {
+	p.Stop()
+}
@@ -0,0 +1,201 @@
+// +build !integration
+// +build linux
+
+package journald
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/input"
+	"github.com/elastic/beats/filebeat/input/file"
+	"github.com/elastic/beats/filebeat/input/journald/journal"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+type testOutlet struct {
+	events chan *util.Data
+}
+
+func (o *testOutlet) OnEvent(data *util.Data) bool {
+	o.events <- data
+	return true
+}
+
+func (o *testOutlet) Close() error {
+	return nil
+}
+
+func newTestInput(t *testing.T, settings map[string]interface{}, states []file.State) (input.Input, *testOutlet) {
+	cfg, err := common.NewConfigFrom(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out := &testOutlet{events: make(chan *util.Data, 10)}
+	factory := func(*common.Config, *common.MapStrPointer) (channel.Outleter, error) {
+		return out, nil
+	}
+
+	p, err := NewInput(cfg, factory, input.Context{States: states})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, out
+}
+
+func receive(t *testing.T, out *testOutlet, n int) []*util.Data {
+	var received []*util.Data
+	for len(received) < n {
+		select {
+		case data := <-out.events:
+			received = append(received, data)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout, received %v of %v events", len(received), n)
+		}
+	}
+	return received
+}
+
+func message(data *util.Data) interface{} {
+	msg, _ := data.Event.GetValue("message")
+	return msg
+}
+
+func TestInputCursor(t *testing.T) {
+	settings := map[string]interface{}{
+		"paths":   []string{"journal/testdata/compact"},
+		"matches": []string{"process.program=web"},
+	}
+
+	p, out := newTestInput(t, settings, nil)
+	p.Run()
+	received := receive(t, out, 3)
+	p.Stop()
+
+	assert.Equal(t, "hello from stdout", message(received[0]))
+	assert.Equal(t, "an error happened", message(received[1]))
+	assert.Equal(t, "second file", message(received[2]))
+
+	state := received[0].GetState()
+	assert.Equal(t, "journald", state.Type)
+	assert.Equal(t, time.Duration(-1), state.TTL)
+	_, err := journal.ParseCursor(state.Cursor)
+	assert.NoError(t, err)
+
+	// restart after the first event has been acknowledged
+	p, out = newTestInput(t, settings, []file.State{state})
+	p.Run()
+	received = receive(t, out, 2)
+	p.Stop()
+
+	assert.Equal(t, "an error happened", message(received[0]))
+	assert.Equal(t, "second file", message(received[1]))
+	last := received[1].GetState()
+	assert.Equal(t, state.ID(), last.ID())
+}
+
+func TestInputSeekTail(t *testing.T) {
+	p, out := newTestInput(t, map[string]interface{}{
+		"paths": []string{"journal/testdata/compact"},
+		"seek":  "tail",
+	}, nil)
+	p.Run()
+	defer p.Stop()
+
+	select {
+	case data := <-out.events:
+		t.Fatalf("unexpected event %v", data.Event)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestToEventFields(t *testing.T) {
+	entry := &journal.Entry{
+		Fields: map[string]string{
+			"MESSAGE":            "native message",
+			"PRIORITY":           "5",
+			"SYSLOG_FACILITY":    "3",
+			"SYSLOG_IDENTIFIER":  "app",
+			"SYSLOG_PID":         "12",
+			"CODE_LINE":          "42",
+			"CUSTOM_FIELD":       "custom value",
+			"_TRANSPORT":         "journal",
+			"_SYSTEMD_UNIT":      "app.service",
+			"_SYSTEMD_OWNER_UID": "not a number",
+			"_HOSTNAME":          "host",
+		},
+	}
+
+	assert.Equal(t, common.MapStr{
+		"message":  "native message",
+		"hostname": "host",
+		"event": common.MapStr{
+			"severity": int64(5),
+		},
+		"syslog": common.MapStr{
+			"facility":       int64(3),
+			"priority":       29,
+			"severity_label": "Notice",
+			"facility_label": "system",
+		},
+		"process": common.MapStr{
+			"program": "app",
+			"pid":     int64(12),
+		},
+		"systemd": common.MapStr{
+			"unit":      "app.service",
+			"transport": "journal",
+		},
+		"journald": common.MapStr{
+			"code": common.MapStr{
+				"line": int64(42),
+			},
+			"custom": common.MapStr{
+				"custom_field":       "custom value",
+				"systemd_owner_uid": "not a number",
+			},
+		},
+	}, toEventFields(entry))
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"no paths":         {"paths": []string{}},
+		"invalid seek":     {"seek": "end"},
+		"invalid fallback": {"cursor_seek_fallback": "cursor"},
+		"invalid match":    {"matches": []string{"systemd.unit"}},
+		"invalid backoff":  {"backoff": "10s", "max_backoff": "1s"},
+	}
+
+	for name, settings := range tests {
+		cfg, err := common.NewConfigFrom(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c := defaultConfig
+		assert.Error(t, cfg.Unpack(&c), name)
+	}
+}
+
+func TestTranslateMatch(t *testing.T) {
+	matches, err := parseMatches([]string{
+		"systemd.unit=nginx.service",
+		"event.severity=3",
+		"_TRANSPORT=syslog",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, journal.Matches{
+		"_SYSTEMD_UNIT": {"nginx.service"},
+		"PRIORITY":      {"3"},
+		"_TRANSPORT":    {"syslog"},
+	}, matches)
+}
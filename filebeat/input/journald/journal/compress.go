@@ -0,0 +1,53 @@
+package journal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/pierrec/lz4"
+	"github.com/ulikunitz/xz"
+)
+
+// decompress decompresses the payload of a data object, based on the
+// object flags.
+func decompress(flags uint8, payload []byte) ([]byte, error) {
+	switch {
+	case flags&objectCompressedZSTD != 0:
+		return decompressZSTD(payload)
+
+	case flags&objectCompressedLZ4 != 0:
+		// LZ4 compressed data is prefixed with the uncompressed size
+		if len(payload) < 8 {
+			return nil, errTruncated
+		}
+		size := binary.LittleEndian.Uint64(payload)
+		if size > maxObjectSize {
+			return nil, fmt.Errorf("invalid uncompressed size %v", size)
+		}
+
+		out := make([]byte, size)
+		n, err := lz4.UncompressBlock(payload[8:], out, 0)
+		if err != nil {
+			return nil, err
+		}
+		return out[:n], nil
+
+	case flags&objectCompressedXZ != 0:
+		r, err := xz.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, err
+		}
+		out, err := ioutil.ReadAll(io.LimitReader(r, maxObjectSize+1))
+		if err != nil {
+			return nil, err
+		}
+		if len(out) > maxObjectSize {
+			return nil, fmt.Errorf("uncompressed size exceeds %v bytes", maxObjectSize)
+		}
+		return out, nil
+	}
+	return payload, nil
+}
@@ -0,0 +1,9 @@
+// +build cgo
+
+package journal
+
+import "github.com/DataDog/zstd"
+
+func decompressZSTD(payload []byte) ([]byte, error) {
+	return zstd.Decompress(nil, payload)
+}
@@ -0,0 +1,11 @@
+// +build !cgo
+
+package journal
+
+import "errors"
+
+var errZSTDCgo = errors.New("zstd compressed journal data requires building with cgo enabled")
+
+func decompressZSTD(payload []byte) ([]byte, error) {
+	return nil, errZSTDCgo
+}
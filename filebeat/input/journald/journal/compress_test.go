@@ -0,0 +1,53 @@
+// +build !integration
+
+package journal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"strings"
+	"testing"
+
+	"github.com/pierrec/lz4"
+	"github.com/stretchr/testify/assert"
+	"github.com/ulikunitz/xz"
+)
+
+func TestDecompress(t *testing.T) {
+	data := []byte(strings.Repeat("lz4 compressed journal data ", 20))
+
+	compressed := make([]byte, 8+lz4.CompressBlockBound(len(data)))
+	binary.LittleEndian.PutUint64(compressed, uint64(len(data)))
+	n, err := lz4.CompressBlock(data, compressed[8:], 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	out, err := decompress(objectCompressedLZ4, compressed[:8+n])
+	if assert.NoError(t, err) {
+		assert.Equal(t, data, out)
+	}
+
+	// journald writes xz streams without checksum
+	var buf bytes.Buffer
+	w, err := xz.WriterConfig{NoCheckSum: true}.NewWriter(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Write(data)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	out, err = decompress(objectCompressedXZ, buf.Bytes())
+	if assert.NoError(t, err) {
+		assert.Equal(t, data, out)
+	}
+
+	_, err = decompress(objectCompressedXZ, data)
+	assert.Error(t, err)
+
+	out, err = decompress(0, data)
+	assert.NoError(t, err)
+	assert.Equal(t, data, out)
+}
@@ -0,0 +1,104 @@
+package journal
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cursor identifies the position of an entry in the journal. The string
+// representation is compatible with the cursors used by journalctl.
+type Cursor struct {
+	SeqnumID  id128
+	Seqnum    uint64
+	BootID    id128
+	Monotonic uint64
+	Realtime  uint64
+	XORHash   uint64
+}
+
+// Entry is a journal entry with all fields.
+type Entry struct {
+	Fields map[string]string
+	Cursor Cursor
+}
+
+// Realtime returns the time the entry has been received by journald.
+func (e *Entry) Realtime() time.Time {
+	return usecToTime(e.Cursor.Realtime)
+}
+
+func usecToTime(usec uint64) time.Time {
+	return time.Unix(int64(usec/1000000), int64(usec%1000000)*1000)
+}
+
+// String formats the cursor as 's=<seqnum id>;i=<seqnum>;b=<boot id>;
+// m=<monotonic>;t=<realtime>;x=<xor hash>'.
+func (c Cursor) String() string {
+	return fmt.Sprintf("s=%v;i=%x;b=%v;m=%x;t=%x;x=%x",
+		c.SeqnumID, c.Seqnum, c.BootID, c.Monotonic, c.Realtime, c.XORHash)
+}
+
+// ParseCursor parses a cursor created by Cursor.String or journalctl.
+func ParseCursor(s string) (Cursor, error) {
+	var c Cursor
+	seen := map[string]bool{}
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return c, fmt.Errorf("invalid cursor '%v'", s)
+		}
+
+		var err error
+		key, value := kv[0], kv[1]
+		switch key {
+		case "s":
+			err = parseID128(value, &c.SeqnumID)
+		case "i":
+			c.Seqnum, err = strconv.ParseUint(value, 16, 64)
+		case "b":
+			err = parseID128(value, &c.BootID)
+		case "m":
+			c.Monotonic, err = strconv.ParseUint(value, 16, 64)
+		case "t":
+			c.Realtime, err = strconv.ParseUint(value, 16, 64)
+		case "x":
+			c.XORHash, err = strconv.ParseUint(value, 16, 64)
+		default:
+			continue
+		}
+		if err != nil {
+			return c, fmt.Errorf("invalid cursor field '%v': %v", part, err)
+		}
+		seen[key] = true
+	}
+
+	if !seen["s"] || !seen["i"] || !seen["t"] {
+		return c, fmt.Errorf("invalid cursor '%v', seqnum ID, seqnum and realtime are required", s)
+	}
+	return c, nil
+}
+
+func parseID128(s string, id *id128) error {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return err
+	}
+	if len(b) != len(id) {
+		return fmt.Errorf("invalid ID length %v", len(b))
+	}
+	copy(id[:], b)
+	return nil
+}
+
+// before returns true if the entry at c has been written before the entry at
+// other. Entries with the same seqnum ID are compared by sequence number,
+// otherwise the realtime timestamps are compared.
+func (c *Cursor) before(other Cursor) bool {
+	if c.SeqnumID == other.SeqnumID {
+		return c.Seqnum < other.Seqnum
+	}
+	return c.Realtime < other.Realtime
+}
@@ -0,0 +1,401 @@
+package journal
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+var (
+	errTruncated        = errors.New("journal object is truncated")
+	errInvalidSignature = errors.New("invalid journal file signature")
+
+	// errNotWritten is returned if an entry has been announced in the header,
+	// but is not yet linked into the entry arrays.
+	errNotWritten = errors.New("journal entry not yet written")
+)
+
+// maxObjectSize limits the size of objects read into memory.
+const maxObjectSize = 64 * 1024 * 1024
+
+// File reads the entries of a single journal file. Entries are read in the
+// order of the files entry arrays, which is the order of the entries
+// sequence numbers. Files being written by journald are supported, new
+// entries become visible after refresh.
+type File struct {
+	path   string
+	file   *os.File
+	header header
+
+	arrays []entryArray // entry arrays read so far
+	next   uint64       // index of the next entry to read
+
+	peeked       bool
+	peekedMeta   entryMeta
+	peekedOffset uint64
+}
+
+type entryArray struct {
+	offset   uint64
+	first    uint64 // index of the first entry in the array
+	capacity uint64
+}
+
+// OpenFile opens a journal file and reads its header.
+func OpenFile(path string) (*File, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &File{path: path, file: file}
+	if err := f.refresh(); err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read journal header of %v: %v", path, err)
+	}
+	return f, nil
+}
+
+// Path returns the path the file has been opened with.
+func (f *File) Path() string {
+	return f.path
+}
+
+// Close closes the journal file.
+func (f *File) Close() error {
+	return f.file.Close()
+}
+
+// refresh re-reads the file header, updating the number of entries.
+func (f *File) refresh() error {
+	buf := make([]byte, minimumHeaderSize)
+	if _, err := f.file.ReadAt(buf, 0); err != nil {
+		if err == io.EOF {
+			return errTruncated
+		}
+		return err
+	}
+
+	h, err := parseHeader(buf)
+	if err != nil {
+		return err
+	}
+	f.header = h
+	return nil
+}
+
+// archived returns true if journald will not write to the file anymore.
+func (f *File) archived() bool {
+	return f.header.state == stateArchived
+}
+
+func (f *File) itemSize() uint64 {
+	if f.header.compact() {
+		return 4
+	}
+	return 8
+}
+
+func (f *File) readAt(offset, size uint64) ([]byte, error) {
+	buf := make([]byte, size)
+	if _, err := f.file.ReadAt(buf, int64(offset)); err != nil {
+		if err == io.EOF {
+			return nil, errTruncated
+		}
+		return nil, err
+	}
+	return buf, nil
+}
+
+// readObject reads the object at offset, checking the object type.
+func (f *File) readObject(offset uint64, typ uint8) (flags uint8, obj []byte, err error) {
+	if offset == 0 || offset%8 != 0 {
+		return 0, nil, fmt.Errorf("invalid object offset %v", offset)
+	}
+
+	hdr, err := f.readAt(offset, objectHeaderSize)
+	if err != nil {
+		return 0, nil, err
+	}
+	if hdr[0] != typ {
+		return 0, nil, fmt.Errorf("unexpected object type %v at offset %v, expected %v", hdr[0], offset, typ)
+	}
+
+	size := binary.LittleEndian.Uint64(hdr[8:])
+	if size < objectHeaderSize || size > maxObjectSize {
+		return 0, nil, fmt.Errorf("invalid object size %v at offset %v", size, offset)
+	}
+
+	obj, err = f.readAt(offset, size)
+	return hdr[1], obj, err
+}
+
+// entryOffset returns the offset of the entry object with the given index.
+func (f *File) entryOffset(index uint64) (uint64, error) {
+	if index >= f.header.nEntries {
+		return 0, io.EOF
+	}
+
+	arr, err := f.entryArrayFor(index)
+	if err != nil {
+		return 0, err
+	}
+
+	itemSize := f.itemSize()
+	item, err := f.readAt(arr.offset+entryArrayItemsOffset+(index-arr.first)*itemSize, itemSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var offset uint64
+	if itemSize == 4 {
+		offset = uint64(binary.LittleEndian.Uint32(item))
+	} else {
+		offset = binary.LittleEndian.Uint64(item)
+	}
+	if offset == 0 {
+		return 0, errNotWritten
+	}
+	return offset, nil
+}
+
+// entryArrayFor returns the entry array containing the entry index. The
+// chain of entry arrays is followed as far as required.
+func (f *File) entryArrayFor(index uint64) (entryArray, error) {
+	for i := range f.arrays {
+		arr := f.arrays[i]
+		if index < arr.first+arr.capacity {
+			return arr, nil
+		}
+	}
+
+	var offset, first uint64
+	if n := len(f.arrays); n == 0 {
+		offset = f.header.entryArrayOffset
+	} else {
+		// the next array might have been linked since the last read
+		last := f.arrays[n-1]
+		_, next, err := f.readEntryArrayHeader(last.offset)
+		if err != nil {
+			return entryArray{}, err
+		}
+		offset, first = next, last.first+last.capacity
+	}
+
+	for offset != 0 {
+		size, next, err := f.readEntryArrayHeader(offset)
+		if err != nil {
+			return entryArray{}, err
+		}
+
+		arr := entryArray{
+			offset:   offset,
+			first:    first,
+			capacity: (size - entryArrayItemsOffset) / f.itemSize(),
+		}
+		f.arrays = append(f.arrays, arr)
+		if index < arr.first+arr.capacity {
+			return arr, nil
+		}
+
+		offset, first = next, first+arr.capacity
+	}
+	return entryArray{}, errNotWritten
+}
+
+// readEntryArrayHeader reads the size of the entry array object at offset and
+// the offset of the next entry array.
+func (f *File) readEntryArrayHeader(offset uint64) (size, next uint64, err error) {
+	if offset%8 != 0 {
+		return 0, 0, fmt.Errorf("invalid object offset %v", offset)
+	}
+
+	hdr, err := f.readAt(offset, entryArrayItemsOffset)
+	if err != nil {
+		return 0, 0, err
+	}
+	if hdr[0] != objectEntryArray {
+		return 0, 0, fmt.Errorf("unexpected object type %v at offset %v, expected %v", hdr[0], offset, objectEntryArray)
+	}
+
+	size = binary.LittleEndian.Uint64(hdr[8:])
+	if size < entryArrayItemsOffset {
+		return 0, 0, errTruncated
+	}
+	return size, binary.LittleEndian.Uint64(hdr[16:]), nil
+}
+
+// entryMeta reads the fixed size fields of the entry with the given index.
+func (f *File) entryMeta(index uint64) (entryMeta, uint64, error) {
+	offset, err := f.entryOffset(index)
+	if err != nil {
+		return entryMeta{}, 0, err
+	}
+
+	_, obj, err := f.readObject(offset, objectEntry)
+	if err != nil {
+		return entryMeta{}, 0, err
+	}
+	if len(obj) < entryItemsOffset {
+		return entryMeta{}, 0, errTruncated
+	}
+	return parseEntryMeta(obj), offset, nil
+}
+
+// peek returns the next entry to be read, without advancing the file. If
+// no more entries are available, io.EOF is returned.
+func (f *File) peek() (entryMeta, uint64, error) {
+	if f.peeked {
+		return f.peekedMeta, f.peekedOffset, nil
+	}
+
+	if f.next >= f.header.nEntries {
+		if err := f.refresh(); err != nil {
+			return entryMeta{}, 0, err
+		}
+	}
+
+	meta, offset, err := f.entryMeta(f.next)
+	if err == errNotWritten {
+		err = io.EOF
+	}
+	if err != nil {
+		return meta, offset, err
+	}
+
+	f.peeked, f.peekedMeta, f.peekedOffset = true, meta, offset
+	return meta, offset, nil
+}
+
+// advance skips the entry returned by peek.
+func (f *File) advance() {
+	f.next++
+	f.peeked = false
+}
+
+// seekAfter positions the file at the first entry after pos. The file is
+// positioned at the first entry if pos is nil.
+func (f *File) seekAfter(pos *Cursor) error {
+	f.peeked = false
+	if err := f.refresh(); err != nil {
+		return err
+	}
+
+	if pos == nil {
+		f.next = 0
+		return nil
+	}
+
+	// entries are ordered by sequence number, search for the first entry
+	// after pos
+	lo, hi := uint64(0), f.header.nEntries
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		meta, _, err := f.entryMeta(mid)
+		if err == errNotWritten {
+			hi = mid
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		if pos.before(f.cursor(meta)) {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+	f.next = lo
+	return nil
+}
+
+// last returns the cursor of the last entry in the file.
+func (f *File) last() (*Cursor, error) {
+	if err := f.refresh(); err != nil {
+		return nil, err
+	}
+
+	for i := f.header.nEntries; i > 0; i-- {
+		meta, _, err := f.entryMeta(i - 1)
+		if err == errNotWritten {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		c := f.cursor(meta)
+		return &c, nil
+	}
+	return nil, nil
+}
+
+func (f *File) cursor(meta entryMeta) Cursor {
+	return Cursor{
+		SeqnumID:  f.header.seqnumID,
+		Seqnum:    meta.seqnum,
+		BootID:    meta.bootID,
+		Monotonic: meta.monotonic,
+		Realtime:  meta.realtime,
+		XORHash:   meta.xorHash,
+	}
+}
+
+// readEntry reads the entry object at offset including all fields.
+func (f *File) readEntry(offset uint64, meta entryMeta) (*Entry, error) {
+	_, obj, err := f.readObject(offset, objectEntry)
+	if err != nil {
+		return nil, err
+	}
+
+	itemSize := uint64(16)
+	if f.header.compact() {
+		itemSize = 4
+	}
+
+	items := obj[entryItemsOffset:]
+	fields := make(map[string]string, uint64(len(items))/itemSize)
+	for i := uint64(0); i+itemSize <= uint64(len(items)); i += itemSize {
+		var dataOffset uint64
+		if itemSize == 4 {
+			dataOffset = uint64(binary.LittleEndian.Uint32(items[i:]))
+		} else {
+			dataOffset = binary.LittleEndian.Uint64(items[i:])
+		}
+
+		payload, err := f.readData(dataOffset)
+		if err != nil {
+			return nil, err
+		}
+
+		idx := bytes.IndexByte(payload, '=')
+		if idx <= 0 {
+			continue
+		}
+		fields[string(payload[:idx])] = string(payload[idx+1:])
+	}
+
+	return &Entry{Fields: fields, Cursor: f.cursor(meta)}, nil
+}
+
+// readData reads the payload of a data object, decompressing the payload
+// if required.
+func (f *File) readData(offset uint64) ([]byte, error) {
+	flags, obj, err := f.readObject(offset, objectData)
+	if err != nil {
+		return nil, err
+	}
+
+	payloadOffset := dataPayloadOffset
+	if f.header.compact() {
+		payloadOffset = dataCompactPayloadOffset
+	}
+	if len(obj) < payloadOffset {
+		return nil, errTruncated
+	}
+
+	return decompress(flags, obj[payloadOffset:])
+}
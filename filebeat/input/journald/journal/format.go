@@ -0,0 +1,135 @@
+// Package journal reads systemd journal files without depending on the
+// systemd libraries. Files being written by journald can be read as well.
+// Reading zstd compressed data requires building with cgo.
+package journal
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// The journal file format is documented in
+// https://systemd.io/JOURNAL_FILE_FORMAT/. All integers are little endian.
+
+var signature = [8]byte{'L', 'P', 'K', 'S', 'H', 'H', 'R', 'H'}
+
+// file states
+const (
+	stateOffline  = 0
+	stateOnline   = 1
+	stateArchived = 2
+)
+
+// incompatible header flags
+const (
+	flagCompressedXZ   = 1 << 0
+	flagCompressedLZ4  = 1 << 1
+	flagKeyedHash      = 1 << 2
+	flagCompressedZSTD = 1 << 3
+	flagCompact        = 1 << 4
+
+	supportedIncompatibleFlags = flagCompressedXZ | flagCompressedLZ4 |
+		flagKeyedHash | flagCompressedZSTD | flagCompact
+)
+
+// object types
+const (
+	objectData        = 1
+	objectEntry       = 3
+	objectEntryArray  = 6
+	objectHeaderSize  = 16
+	minimumHeaderSize = 208
+)
+
+// object flags
+const (
+	objectCompressedXZ   = 1 << 0
+	objectCompressedLZ4  = 1 << 1
+	objectCompressedZSTD = 1 << 2
+)
+
+// offsets into the objects, relative to the start of the object
+const (
+	dataPayloadOffset        = 64
+	dataCompactPayloadOffset = 72
+	entryItemsOffset         = 64
+	entryArrayItemsOffset    = 24
+)
+
+type id128 [16]byte
+
+func (id id128) String() string {
+	return fmt.Sprintf("%x", id[:])
+}
+
+// header contains the fields of the journal file header used by the reader.
+type header struct {
+	incompatibleFlags uint32
+	state             uint8
+	fileID            id128
+	seqnumID          id128
+	headerSize        uint64
+	arenaSize         uint64
+	nEntries          uint64
+	tailEntrySeqnum   uint64
+	headEntrySeqnum   uint64
+	entryArrayOffset  uint64
+	headEntryRealtime uint64
+	tailEntryRealtime uint64
+}
+
+func parseHeader(b []byte) (header, error) {
+	var h header
+	if len(b) < minimumHeaderSize {
+		return h, errTruncated
+	}
+	if string(b[:8]) != string(signature[:]) {
+		return h, errInvalidSignature
+	}
+
+	le := binary.LittleEndian
+	h.incompatibleFlags = le.Uint32(b[12:])
+	h.state = b[16]
+	copy(h.fileID[:], b[24:40])
+	copy(h.seqnumID[:], b[72:88])
+	h.headerSize = le.Uint64(b[88:])
+	h.arenaSize = le.Uint64(b[96:])
+	h.nEntries = le.Uint64(b[152:])
+	h.tailEntrySeqnum = le.Uint64(b[160:])
+	h.headEntrySeqnum = le.Uint64(b[168:])
+	h.entryArrayOffset = le.Uint64(b[176:])
+	h.headEntryRealtime = le.Uint64(b[184:])
+	h.tailEntryRealtime = le.Uint64(b[192:])
+
+	if h.headerSize < minimumHeaderSize {
+		return h, fmt.Errorf("unsupported header size %v", h.headerSize)
+	}
+	if unknown := h.incompatibleFlags &^ supportedIncompatibleFlags; unknown != 0 {
+		return h, fmt.Errorf("unsupported incompatible flags 0x%x", unknown)
+	}
+	return h, nil
+}
+
+func (h *header) compact() bool {
+	return h.incompatibleFlags&flagCompact != 0
+}
+
+// entryMeta contains the fixed size fields of an entry object.
+type entryMeta struct {
+	seqnum    uint64
+	realtime  uint64
+	monotonic uint64
+	bootID    id128
+	xorHash   uint64
+}
+
+func parseEntryMeta(b []byte) entryMeta {
+	le := binary.LittleEndian
+	var m entryMeta
+	m.seqnum = le.Uint64(b[16:])
+	m.realtime = le.Uint64(b[24:])
+	m.monotonic = le.Uint64(b[32:])
+	copy(m.bootID[:], b[40:56])
+	m.xorHash = le.Uint64(b[56:])
+	return m
+}
@@ -0,0 +1,226 @@
+// +build !integration
+// +build cgo
+
+package journal
+
+import (
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// The test journals are zstd compressed, reading them requires cgo.
+const (
+	compactArchive = "testdata/compact/system@d0ff60f8fb5848cfbc82b42d833b037f-0000000000000001-00065dffd9e4f2d9.journal"
+	compactActive  = "testdata/compact/system.journal"
+	regularArchive = "testdata/regular/system@ee44987836f847f58aa53f7becf4e3d6-0000000000000001-00065dffda240787.journal"
+)
+
+// readAll returns all entries, skipping entries written by journald itself.
+func readAll(t *testing.T, r *Reader) []*Entry {
+	var entries []*Entry
+	for {
+		entry, err := r.Next()
+		if err == io.EOF {
+			return entries
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if entry.Fields["SYSLOG_IDENTIFIER"] != "systemd-journald" {
+			entries = append(entries, entry)
+		}
+	}
+}
+
+func messages(entries []*Entry) []string {
+	var msgs []string
+	for _, e := range entries {
+		msg := e.Fields["MESSAGE"]
+		if len(msg) > 20 {
+			msg = msg[:20]
+		}
+		msgs = append(msgs, msg)
+	}
+	return msgs
+}
+
+func TestReadDirectory(t *testing.T) {
+	r := NewReader([]string{"testdata/compact"}, nil)
+	defer r.Close()
+
+	entries := readAll(t, r)
+	assert.Equal(t, []string{
+		"hello from stdout",
+		"an error happened",
+		"native message with ",
+		"syslog transport mes",
+		"long message long me",
+		"second file",
+	}, messages(entries))
+
+	for i := 1; i < len(entries); i++ {
+		assert.True(t, entries[i-1].Cursor.before(entries[i].Cursor))
+	}
+}
+
+func TestReadFields(t *testing.T) {
+	for _, path := range []string{compactArchive, regularArchive} {
+		r := NewReader([]string{path}, Matches{"SYSLOG_IDENTIFIER": {"app"}})
+		entries := readAll(t, r)
+		r.Close()
+
+		if !assert.Len(t, entries, 1, path) {
+			continue
+		}
+
+		fields := entries[0].Fields
+		assert.Equal(t, "native message with fields", fields["MESSAGE"], path)
+		assert.Equal(t, "5", fields["PRIORITY"], path)
+		assert.Equal(t, "3", fields["SYSLOG_FACILITY"], path)
+		assert.Equal(t, "custom value", fields["CUSTOM_FIELD"], path)
+		assert.Equal(t, "main.go", fields["CODE_FILE"], path)
+		assert.Equal(t, "42", fields["CODE_LINE"], path)
+		assert.Equal(t, "main.run", fields["CODE_FUNC"], path)
+		assert.Equal(t, "journal", fields["_TRANSPORT"], path)
+		assert.Equal(t, "3c3558f09433458ca18ece8b38b4da3b", fields["_BOOT_ID"], path)
+		assert.Equal(t, fields["_BOOT_ID"], entries[0].Cursor.BootID.String(), path)
+	}
+}
+
+func TestReadCompressed(t *testing.T) {
+	r := NewReader([]string{compactActive}, Matches{"SYSLOG_IDENTIFIER": {"app"}})
+	defer r.Close()
+
+	entries := readAll(t, r)
+	if !assert.Len(t, entries, 1) {
+		return
+	}
+
+	msg := entries[0].Fields["MESSAGE"]
+	assert.Len(t, msg, 1299)
+	assert.Equal(t, 100, strings.Count(msg, "long message"))
+}
+
+func TestMatches(t *testing.T) {
+	tests := []struct {
+		matches  []string
+		expected []string
+	}{
+		{
+			[]string{"SYSLOG_IDENTIFIER=web"},
+			[]string{"hello from stdout", "an error happened", "second file"},
+		},
+		{
+			[]string{"SYSLOG_IDENTIFIER=web", "PRIORITY=3"},
+			[]string{"an error happened"},
+		},
+		{
+			[]string{"PRIORITY=3", "PRIORITY=4"},
+			[]string{"an error happened", "syslog transport mes"},
+		},
+		{
+			[]string{"_TRANSPORT=syslog"},
+			[]string{"syslog transport mes"},
+		},
+		{
+			[]string{"SYSLOG_IDENTIFIER=unknown"},
+			nil,
+		},
+	}
+
+	for _, test := range tests {
+		matches, err := ParseMatches(test.matches)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		r := NewReader([]string{"testdata/compact"}, matches)
+		assert.Equal(t, test.expected, messages(readAll(t, r)), "%v", test.matches)
+		r.Close()
+	}
+
+	_, err := ParseMatches([]string{"PRIORITY"})
+	assert.Error(t, err)
+}
+
+func TestCursor(t *testing.T) {
+	s := "s=d0ff60f8fb5848cfbc82b42d833b037f;i=5;b=3c3558f09433458ca18ece8b38b4da3b;m=1469def89;t=65dffd9f4568a;x=df8e1127dcbbffcd"
+
+	c, err := ParseCursor(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, uint64(5), c.Seqnum)
+	assert.Equal(t, uint64(0x65dffd9f4568a), c.Realtime)
+	assert.Equal(t, s, c.String())
+
+	for _, invalid := range []string{"", "i=5", "s=abc;i=5;t=1", "s=d0ff60f8fb5848cfbc82b42d833b037f;i=x;t=1"} {
+		_, err := ParseCursor(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestSeekCursor(t *testing.T) {
+	r := NewReader([]string{"testdata/compact"}, nil)
+	defer r.Close()
+
+	entries := readAll(t, r)
+	if len(entries) < 3 {
+		t.Fatalf("expected at least 3 entries, got %v", len(entries))
+	}
+
+	c, err := ParseCursor(entries[2].Cursor.String())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r.SeekCursor(c)
+	assert.Equal(t, messages(entries[3:]), messages(readAll(t, r)))
+}
+
+func TestSeekTail(t *testing.T) {
+	r := NewReader([]string{"testdata/compact"}, nil)
+	defer r.Close()
+
+	r.SeekTail()
+	_, err := r.Next()
+	assert.Equal(t, io.EOF, err)
+
+	r.SeekHead()
+	assert.Len(t, readAll(t, r), 6)
+}
+
+func TestNewFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	copyFile(t, compactArchive, filepath.Join(dir, filepath.Base(compactArchive)))
+
+	r := NewReader([]string{dir}, nil)
+	defer r.Close()
+
+	assert.Len(t, readAll(t, r), 4)
+
+	// new files are picked up once all entries have been read
+	copyFile(t, compactActive, filepath.Join(dir, "system.journal"))
+	assert.Equal(t, []string{"long message long me", "second file"}, messages(readAll(t, r)))
+}
+
+func copyFile(t *testing.T, src, dst string) {
+	b, err := ioutil.ReadFile(src)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(dst, b, 0644); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,47 @@
+package journal
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Matches filters journal entries by field values. Values of the same field
+// are combined using OR, different fields are combined using AND, like the
+// matches of journalctl.
+type Matches map[string][]string
+
+// ParseMatches parses a list of matches in the form 'FIELD=value'.
+func ParseMatches(matches []string) (Matches, error) {
+	m := Matches{}
+	for _, match := range matches {
+		idx := strings.IndexByte(match, '=')
+		if idx <= 0 {
+			return nil, fmt.Errorf("invalid match '%v', expected 'FIELD=value'", match)
+		}
+
+		field := match[:idx]
+		m[field] = append(m[field], match[idx+1:])
+	}
+	return m, nil
+}
+
+// Match returns true if the fields match all configured fields. Any entry
+// matches if no matches are configured.
+func (m Matches) Match(fields map[string]string) bool {
+	for field, values := range m {
+		value, ok := fields[field]
+		if !ok || !contains(values, value) {
+			return false
+		}
+	}
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
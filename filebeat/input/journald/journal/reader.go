@@ -0,0 +1,213 @@
+package journal
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/elastic/beats/libbeat/common/file"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// Reader reads the entries of all journal files found in the configured
+// paths in order. Files created by journald after the reader has been
+// created are picked up when all known entries have been read.
+type Reader struct {
+	paths   []string
+	matches Matches
+
+	files []*File
+	known map[file.StateOS]bool // files already opened, by inode
+	pos   *Cursor               // last entry returned or skipped
+
+	log *logp.Logger
+}
+
+// NewReader creates a new reader for the journal files in paths. Paths can
+// be journal directories or single journal files. Only entries matching
+// matches are returned.
+func NewReader(paths []string, matches Matches) *Reader {
+	return &Reader{
+		paths:   paths,
+		matches: matches,
+		known:   map[file.StateOS]bool{},
+		log:     logp.NewLogger("journald"),
+	}
+}
+
+// Next returns the next entry matching the configured matches. io.EOF is
+// returned if no new entries are available. If an entry can not be read, the
+// error is returned and the entry is skipped, such that the next call
+// continues with the following entry.
+func (r *Reader) Next() (*Entry, error) {
+	scanned := false
+	for {
+		f, meta, offset := r.nextFile()
+		if f == nil {
+			if scanned {
+				return nil, io.EOF
+			}
+			r.scan()
+			scanned = true
+			continue
+		}
+
+		f.advance()
+		cursor := f.cursor(meta)
+		r.pos = &cursor
+
+		entry, err := f.readEntry(offset, meta)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read journal entry %v from %v: %v", cursor, f.Path(), err)
+		}
+		if r.matches.Match(entry.Fields) {
+			return entry, nil
+		}
+	}
+}
+
+// nextFile returns the file with the oldest unread entry. Archived files
+// without unread entries are closed.
+func (r *Reader) nextFile() (*File, entryMeta, uint64) {
+	var (
+		next       *File
+		nextMeta   entryMeta
+		nextOffset uint64
+		nextCursor Cursor
+	)
+
+	files := r.files[:0]
+	for _, f := range r.files {
+		meta, offset, err := f.peek()
+		if err != nil {
+			if err != io.EOF {
+				r.log.Errorf("Failed to read journal file %v: %v", f.Path(), err)
+			}
+			if f.archived() {
+				r.log.Debugf("Closing journal file %v", f.Path())
+				f.Close()
+				continue
+			}
+			files = append(files, f)
+			continue
+		}
+
+		files = append(files, f)
+		if c := f.cursor(meta); next == nil || c.before(nextCursor) {
+			next, nextMeta, nextOffset, nextCursor = f, meta, offset, c
+		}
+	}
+	r.files = files
+
+	return next, nextMeta, nextOffset
+}
+
+// SeekHead positions the reader at the oldest entry.
+func (r *Reader) SeekHead() {
+	r.Close()
+	r.pos = nil
+	r.scan()
+}
+
+// SeekTail positions the reader after the newest entry, such that only new
+// entries are returned.
+func (r *Reader) SeekTail() {
+	r.SeekHead()
+	for _, f := range r.files {
+		last, err := f.last()
+		if err != nil {
+			r.log.Errorf("Failed to read last entry of journal file %v: %v", f.Path(), err)
+			continue
+		}
+		if last != nil && (r.pos == nil || r.pos.before(*last)) {
+			r.pos = last
+		}
+	}
+
+	for _, f := range r.files {
+		if err := f.seekAfter(r.pos); err != nil {
+			r.log.Errorf("Failed to seek in journal file %v: %v", f.Path(), err)
+		}
+	}
+}
+
+// SeekCursor positions the reader after the entry at cursor.
+func (r *Reader) SeekCursor(cursor Cursor) {
+	r.Close()
+	r.pos = &cursor
+	r.scan()
+}
+
+// scan opens journal files not known yet. New files are positioned after the
+// current position.
+func (r *Reader) scan() {
+	seen := map[file.StateOS]bool{}
+
+	for _, path := range r.journalFiles() {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+
+		state := file.GetOSState(info)
+		seen[state] = true
+		if r.known[state] {
+			continue
+		}
+		r.known[state] = true
+
+		f, err := OpenFile(path)
+		if err != nil {
+			r.log.Errorf("Failed to open journal file: %v", err)
+			continue
+		}
+		if err := f.seekAfter(r.pos); err != nil {
+			r.log.Errorf("Failed to seek in journal file %v: %v", path, err)
+			f.Close()
+			continue
+		}
+
+		r.log.Debugf("Opened journal file %v", path)
+		r.files = append(r.files, f)
+	}
+
+	// forget about removed files
+	for state := range r.known {
+		if !seen[state] {
+			delete(r.known, state)
+		}
+	}
+}
+
+// journalFiles returns the paths of all journal files in the configured
+// paths. Journal directories can contain a subdirectory per machine ID.
+func (r *Reader) journalFiles() []string {
+	var files []string
+	for _, path := range r.paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			files = append(files, path)
+			continue
+		}
+
+		for _, pattern := range []string{"*.journal", "*/*.journal"} {
+			matches, _ := filepath.Glob(filepath.Join(path, pattern))
+			files = append(files, matches...)
+		}
+	}
+	return files
+}
+
+// Close closes all open journal files.
+func (r *Reader) Close() error {
+	for _, f := range r.files {
+		f.Close()
+	}
+	r.files = nil
+	r.known = map[file.StateOS]bool{}
+	return nil
+}
@@ -0,0 +1,184 @@
+// +build linux
+
+package journald
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/elastic/beats/filebeat/input/journald/journal"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// fieldConversion maps a journal field to an event field.
+type fieldConversion struct {
+	name      string
+	isInteger bool
+}
+
+// journaldFields defines the event fields of well known journal fields, see
+// systemd.journal-fields(7). Journal fields not listed here are reported in
+// journald.custom.
+var journaldFields = map[string]fieldConversion{
+	"MESSAGE":           {"message", false},
+	"MESSAGE_ID":        {"journald.message_id", false},
+	"PRIORITY":          {"event.severity", true},
+	"SYSLOG_FACILITY":   {"syslog.facility", true},
+	"SYSLOG_IDENTIFIER": {"process.program", false},
+	"CODE_FILE":         {"journald.code.file", false},
+	"CODE_LINE":         {"journald.code.line", true},
+	"CODE_FUNC":         {"journald.code.func", false},
+
+	// trusted fields added by journald
+	"_PID":                   {"process.pid", true},
+	"_UID":                   {"process.uid", true},
+	"_GID":                   {"process.gid", true},
+	"_COMM":                  {"process.name", false},
+	"_EXE":                   {"process.executable", false},
+	"_CMDLINE":               {"process.cmdline", false},
+	"_CAP_EFFECTIVE":         {"process.capabilities", false},
+	"_SYSTEMD_UNIT":          {"systemd.unit", false},
+	"_SYSTEMD_USER_UNIT":     {"systemd.user_unit", false},
+	"_SYSTEMD_SLICE":         {"systemd.slice", false},
+	"_SYSTEMD_CGROUP":        {"systemd.cgroup", false},
+	"_SYSTEMD_OWNER_UID":     {"systemd.owner_uid", true},
+	"_SYSTEMD_SESSION":       {"systemd.session", false},
+	"_SYSTEMD_INVOCATION_ID": {"systemd.invocation_id", false},
+	"_TRANSPORT":             {"systemd.transport", false},
+	"_HOSTNAME":              {"hostname", false},
+	"_MACHINE_ID":            {"journald.host.machine_id", false},
+	"_BOOT_ID":               {"journald.host.boot_id", false},
+}
+
+// eventFieldNames maps event field names back to journal fields, for
+// translating matches.
+var eventFieldNames = func() map[string]string {
+	m := make(map[string]string, len(journaldFields))
+	for field, conv := range journaldFields {
+		m[conv.name] = field
+	}
+	return m
+}()
+
+// translateMatch translates a match on an event field to a match on the
+// journal field.
+func translateMatch(match string) string {
+	idx := strings.IndexByte(match, '=')
+	if idx <= 0 {
+		return match
+	}
+	if field, ok := eventFieldNames[match[:idx]]; ok {
+		return field + match[idx:]
+	}
+	return match
+}
+
+// toEventFields converts the fields of a journal entry to event fields.
+func toEventFields(entry *journal.Entry) common.MapStr {
+	fields := common.MapStr{}
+	custom := common.MapStr{}
+
+	for name, value := range entry.Fields {
+		conv, ok := journaldFields[name]
+		if !ok {
+			custom[normalizeFieldName(name)] = value
+			continue
+		}
+
+		if !conv.isInteger {
+			fields.Put(conv.name, value)
+			continue
+		}
+
+		i, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			custom[normalizeFieldName(name)] = value
+			continue
+		}
+		fields.Put(conv.name, i)
+	}
+
+	// use the PID reported by the client, if journald could not determine it
+	if has, _ := fields.HasKey("process.pid"); !has {
+		if pid, err := strconv.ParseInt(entry.Fields["SYSLOG_PID"], 10, 64); err == nil {
+			fields.Put("process.pid", pid)
+			delete(custom, "syslog_pid")
+		}
+	}
+
+	addSyslogLabels(fields)
+
+	if len(custom) > 0 {
+		fields.Put("journald.custom", custom)
+	}
+	return fields
+}
+
+// addSyslogLabels adds the syslog priority and the human readable severity and
+// facility, using the same fields as the syslog input.
+func addSyslogLabels(fields common.MapStr) {
+	severity, err := fields.GetValue("event.severity")
+	if err != nil {
+		return
+	}
+	s := int(severity.(int64))
+	if s >= 0 && s < len(severityLabels) {
+		fields.Put("syslog.severity_label", severityLabels[s])
+	}
+
+	facility, err := fields.GetValue("syslog.facility")
+	if err != nil {
+		return
+	}
+	f := int(facility.(int64))
+	if f >= 0 && f < len(facilityLabels) {
+		fields.Put("syslog.facility_label", facilityLabels[f])
+	}
+	fields.Put("syslog.priority", f*8+s)
+}
+
+// normalizeFieldName converts a journal field name to an event field name,
+// e.g. _SOURCE_REALTIME_TIMESTAMP becomes source_realtime_timestamp.
+func normalizeFieldName(name string) string {
+	return strings.ToLower(strings.TrimLeft(name, "_"))
+}
+
+var (
+	severityLabels = []string{
+		"Emergency",
+		"Alert",
+		"Critical",
+		"Error",
+		"Warning",
+		"Notice",
+		"Informational",
+		"Debug",
+	}
+
+	facilityLabels = []string{
+		"kernel",
+		"user-level",
+		"mail",
+		"system",
+		"security/authorization",
+		"syslogd",
+		"line printer",
+		"network news",
+		"UUCP",
+		"clock",
+		"security/authorization",
+		"FTP",
+		"NTP",
+		"log audit",
+		"log alert",
+		"clock",
+		"local0",
+		"local1",
+		"local2",
+		"local3",
+		"local4",
+		"local5",
+		"local6",
+		"local7",
+	}
+)
@@ -0,0 +1,121 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/harvester/reader"
+	libkafka "github.com/elastic/beats/libbeat/common/kafka"
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+type kafkaInputConfig struct {
+	harvester.ForwarderConfig `config:",inline"`
+
+	Hosts          []string            `config:"hosts" validate:"required"`
+	Topics         []string            `config:"topics" validate:"required"`
+	GroupID        string              `config:"group_id" validate:"required"`
+	ClientID       string              `config:"client_id"`
+	Version        string              `config:"version"`
+	InitialOffset  string              `config:"initial_offset"`
+	ConnectBackoff time.Duration       `config:"connect_backoff" validate:"min=0"`
+	ConsumeBackoff time.Duration       `config:"consume_backoff" validate:"min=0"`
+	WaitClose      time.Duration       `config:"wait_close" validate:"min=0"`
+	MaxWaitTime    time.Duration       `config:"max_wait_time" validate:"min=1"`
+	IsolationLevel string              `config:"isolation_level"`
+	Fetch          fetchConfig         `config:"fetch"`
+	Rebalance      rebalanceConfig     `config:"rebalance"`
+	TLS            *outputs.TLSConfig  `config:"ssl"`
+	Username       string              `config:"username"`
+	Password       string              `config:"password"`
+	Sasl           libkafka.SaslConfig `config:"sasl"`
+	JSON           *reader.JSONConfig  `config:"json"`
+}
+
+type fetchConfig struct {
+	Min     int32 `config:"min" validate:"min=1"`
+	Default int32 `config:"default" validate:"min=1"`
+	Max     int32 `config:"max" validate:"min=0"`
+}
+
+type rebalanceConfig struct {
+	Strategy     string        `config:"strategy"`
+	Timeout      time.Duration `config:"timeout" validate:"min=1"`
+	MaxRetries   int           `config:"max_retries" validate:"min=0"`
+	RetryBackoff time.Duration `config:"retry_backoff" validate:"min=0"`
+}
+
+var defaultConfig = kafkaInputConfig{
+	ForwarderConfig: harvester.ForwarderConfig{
+		Type: "kafka",
+	},
+	ClientID:       "filebeat",
+	Version:        "1.0.0",
+	InitialOffset:  "oldest",
+	ConnectBackoff: 30 * time.Second,
+	ConsumeBackoff: 2 * time.Second,
+	WaitClose:      2 * time.Second,
+	MaxWaitTime:    250 * time.Millisecond,
+	IsolationLevel: "read_uncommitted",
+	Fetch: fetchConfig{
+		Min:     1,
+		Default: 1024 * 1024,
+		Max:     0,
+	},
+	Rebalance: rebalanceConfig{
+		Strategy:     "range",
+		Timeout:      60 * time.Second,
+		MaxRetries:   4,
+		RetryBackoff: 2 * time.Second,
+	},
+}
+
+var (
+	initialOffsets = map[string]int64{
+		"oldest": sarama.OffsetOldest,
+		"newest": sarama.OffsetNewest,
+	}
+
+	isolationLevels = map[string]sarama.IsolationLevel{
+		"read_uncommitted": sarama.ReadUncommitted,
+		"read_committed":   sarama.ReadCommitted,
+	}
+
+	rebalanceStrategies = map[string]sarama.BalanceStrategy{
+		"range":      sarama.BalanceStrategyRange,
+		"roundrobin": sarama.BalanceStrategyRoundRobin,
+	}
+)
+
+func (c *kafkaInputConfig) Validate() error {
+	if len(c.Hosts) == 0 {
+		return errors.New("no hosts configured")
+	}
+
+	if len(c.Topics) == 0 {
+		return errors.New("no topics configured")
+	}
+
+	// consumer groups require Kafka 0.10.2 or newer
+	if version, ok := libkafka.Version(c.Version); !ok || !version.IsAtLeast(sarama.V0_10_2_0) {
+		return fmt.Errorf("unknown/unsupported kafka version '%v', consumer groups require 0.10.2 or newer", c.Version)
+	}
+
+	if _, ok := initialOffsets[c.InitialOffset]; !ok {
+		return fmt.Errorf("invalid initial_offset '%v', must be oldest or newest", c.InitialOffset)
+	}
+
+	if _, ok := isolationLevels[c.IsolationLevel]; !ok {
+		return fmt.Errorf("invalid isolation_level '%v', must be read_uncommitted or read_committed", c.IsolationLevel)
+	}
+
+	if _, ok := rebalanceStrategies[c.Rebalance.Strategy]; !ok {
+		return fmt.Errorf("invalid rebalance.strategy '%v', must be range or roundrobin", c.Rebalance.Strategy)
+	}
+
+	return libkafka.ValidateAuth(c.Username, c.Password, c.Sasl)
+}
@@ -0,0 +1,169 @@
+package kafka
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/harvester/reader"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// groupHandler publishes the messages of the partitions claimed by a consumer
+// group session. Messages are marked as consumed once the events have been
+// acknowledged, marked offsets are committed by sarama.
+type groupHandler struct {
+	forwarder *harvester.Forwarder
+	config    *kafkaInputConfig
+	log       *logp.Logger
+
+	pending *pendingACKs // events of the current session not ACKed yet
+}
+
+// eventACK marks a message as consumed, once the event has been acknowledged.
+type eventACK struct {
+	session sarama.ConsumerGroupSession
+	msg     *sarama.ConsumerMessage
+	pending *pendingACKs
+}
+
+func (a *eventACK) ACK() {
+	a.session.MarkMessage(a.msg, "")
+	a.pending.done()
+}
+
+// Setup is called by sarama when a new session starts, after a rebalance.
+func (h *groupHandler) Setup(session sarama.ConsumerGroupSession) error {
+	h.log.Debugw("Consumer group session started",
+		"member_id", session.MemberID(), "generation_id", session.GenerationID(), "claims", session.Claims())
+	h.pending = newPendingACKs()
+	return nil
+}
+
+// Cleanup is called by sarama before the offsets of the session are committed
+// and the claimed partitions are released. Events published in the session
+// are waited for, such that their offsets can still be committed.
+func (h *groupHandler) Cleanup(session sarama.ConsumerGroupSession) error {
+	if !h.pending.wait(h.config.WaitClose) {
+		h.log.Infow("Not all events have been acknowledged before the consumer group session ended, messages will be consumed again",
+			"pending", h.pending.count())
+	}
+	return nil
+}
+
+// ConsumeClaim publishes the messages of a single partition, until the
+// session ends.
+func (h *groupHandler) ConsumeClaim(session sarama.ConsumerGroupSession, claim sarama.ConsumerGroupClaim) error {
+	for msg := range claim.Messages() {
+		data := util.NewData()
+		data.Event = h.createEvent(msg)
+		data.Event.Private = &eventACK{session: session, msg: msg, pending: h.pending}
+
+		h.pending.add()
+		if err := h.forwarder.Send(data); err != nil {
+			h.pending.done()
+			return err
+		}
+	}
+	return nil
+}
+
+func (h *groupHandler) createEvent(msg *sarama.ConsumerMessage) beat.Event {
+	timestamp := time.Now()
+	if !msg.Timestamp.IsZero() {
+		timestamp = msg.Timestamp
+	}
+
+	kafka := common.MapStr{
+		"topic":     msg.Topic,
+		"partition": msg.Partition,
+		"offset":    msg.Offset,
+	}
+	if len(msg.Key) > 0 {
+		kafka["key"] = string(msg.Key)
+	}
+	if len(msg.Headers) > 0 {
+		headers := common.MapStr{}
+		for _, header := range msg.Headers {
+			headers[string(header.Key)] = string(header.Value)
+		}
+		kafka["headers"] = headers
+	}
+
+	fields := common.MapStr{
+		"kafka": kafka,
+	}
+
+	text := string(msg.Value)
+	if h.config.JSON != nil {
+		content, jsonFields := reader.DecodeJSON(msg.Value, h.config.JSON)
+		if len(jsonFields) > 0 {
+			text = string(content)
+			fields["json"] = jsonFields
+			if ts := reader.MergeJSONFields(fields, jsonFields, &text, *h.config.JSON); !ts.IsZero() {
+				// there was a `@timestamp` key in the event, so overwrite
+				// the resulting timestamp
+				timestamp = ts
+			}
+			return beat.Event{Timestamp: timestamp, Fields: fields}
+		}
+	}
+
+	fields["message"] = text
+	return beat.Event{Timestamp: timestamp, Fields: fields}
+}
+
+// pendingACKs counts the events published, but not yet acknowledged.
+type pendingACKs struct {
+	mutex   sync.Mutex
+	pending int
+	acked   chan struct{}
+}
+
+func newPendingACKs() *pendingACKs {
+	return &pendingACKs{acked: make(chan struct{}, 1)}
+}
+
+func (p *pendingACKs) add() {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	p.pending++
+}
+
+func (p *pendingACKs) done() {
+	p.mutex.Lock()
+	p.pending--
+	p.mutex.Unlock()
+
+	select {
+	case p.acked <- struct{}{}:
+	default:
+	}
+}
+
+func (p *pendingACKs) count() int {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.pending
+}
+
+// wait waits until all events have been acknowledged. It returns false if
+// events are still pending after timeout.
+func (p *pendingACKs) wait(timeout time.Duration) bool {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	for p.count() > 0 {
+		select {
+		case <-p.acked:
+		case <-timer.C:
+			return false
+		}
+	}
+	return true
+}
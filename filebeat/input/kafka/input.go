@@ -0,0 +1,197 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Shopify/sarama"
+
+	"github.com/elastic/beats/filebeat/channel"
+	"github.com/elastic/beats/filebeat/harvester"
+	"github.com/elastic/beats/filebeat/input"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	libkafka "github.com/elastic/beats/libbeat/common/kafka"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+func init() {
+	err := input.Register("kafka", NewInput)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Input consumes messages from Kafka topics as member of a consumer group.
+// Offsets are committed only after the events have been acknowledged by the
+// outputs, such that messages are delivered at least once.
+type Input struct {
+	sync.Mutex
+	config       kafkaInputConfig
+	saramaConfig *sarama.Config
+	outlet       channel.Outleter
+	started      bool
+	ctx          context.Context
+	cancel       context.CancelFunc
+	stopOnce     sync.Once
+	wg           sync.WaitGroup
+	log          *logp.Logger
+}
+
+// NewInput creates a new kafka input.
+func NewInput(
+	cfg *common.Config,
+	outlet channel.Factory,
+	context input.Context,
+) (input.Input, error) {
+	cfgwarn.Experimental("Kafka input type is used")
+
+	config := defaultConfig
+	if err := cfg.Unpack(&config); err != nil {
+		return nil, err
+	}
+
+	saramaConfig, err := newSaramaConfig(&config)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := outlet(cfg, context.DynamicFields)
+	if err != nil {
+		return nil, err
+	}
+
+	return newInput(config, saramaConfig, out), nil
+}
+
+func newInput(config kafkaInputConfig, saramaConfig *sarama.Config, out channel.Outleter) *Input {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Input{
+		config:       config,
+		saramaConfig: saramaConfig,
+		outlet:       out,
+		ctx:          ctx,
+		cancel:       cancel,
+		log:          logp.NewLogger("kafka input").With("hosts", config.Hosts),
+	}
+}
+
+func newSaramaConfig(config *kafkaInputConfig) (*sarama.Config, error) {
+	k := sarama.NewConfig()
+
+	k.ClientID = config.ClientID
+	k.Version, _ = libkafka.Version(config.Version)
+
+	k.Consumer.Return.Errors = true
+	k.Consumer.Offsets.Initial = initialOffsets[config.InitialOffset]
+	k.Consumer.MaxWaitTime = config.MaxWaitTime
+	k.Consumer.IsolationLevel = isolationLevels[config.IsolationLevel]
+	k.Consumer.Fetch.Min = config.Fetch.Min
+	k.Consumer.Fetch.Default = config.Fetch.Default
+	k.Consumer.Fetch.Max = config.Fetch.Max
+
+	k.Consumer.Group.Rebalance.Strategy = rebalanceStrategies[config.Rebalance.Strategy]
+	k.Consumer.Group.Rebalance.Timeout = config.Rebalance.Timeout
+	k.Consumer.Group.Rebalance.Retry.Max = config.Rebalance.MaxRetries
+	k.Consumer.Group.Rebalance.Retry.Backoff = config.Rebalance.RetryBackoff
+
+	tls, err := outputs.LoadTLSConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+	if tls != nil {
+		k.Net.TLS.Enable = true
+		k.Net.TLS.Config = tls.BuildModuleConfig("")
+	}
+
+	libkafka.ConfigureAuth(k, config.Username, config.Password, config.Sasl)
+
+	if err := k.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid kafka configuration: %v", err)
+	}
+	return k, nil
+}
+
+// Run starts consuming messages.
+func (p *Input) Run() {
+	p.Lock()
+	defer p.Unlock()
+
+	if !p.started {
+		p.log.Infow("Starting Kafka input", "topics", p.config.Topics, "group_id", p.config.GroupID)
+		p.started = true
+		p.wg.Add(1)
+		go func() {
+			defer p.wg.Done()
+			p.run()
+		}()
+	}
+}
+
+// run joins the consumer group, reconnecting after connection failures until
+// the input is stopped.
+func (p *Input) run() {
+	for p.ctx.Err() == nil {
+		group, err := sarama.NewConsumerGroup(p.config.Hosts, p.config.GroupID, p.saramaConfig)
+		if err != nil {
+			p.log.Errorw("Failed to connect to Kafka", "error", err)
+			p.wait(p.config.ConnectBackoff)
+			continue
+		}
+
+		p.consume(group)
+
+		if err := group.Close(); err != nil {
+			p.log.Errorw("Failed to close consumer group", "error", err)
+		}
+	}
+}
+
+// consume consumes messages until the input is stopped. Consume returns on
+// every rebalance of the consumer group, so it is called in a loop.
+func (p *Input) consume(group sarama.ConsumerGroup) {
+	go func() {
+		for err := range group.Errors() {
+			p.log.Errorw("Error reading from Kafka", "error", err)
+		}
+	}()
+
+	handler := &groupHandler{
+		forwarder: harvester.NewForwarder(p.outlet),
+		config:    &p.config,
+		log:       p.log,
+	}
+	for p.ctx.Err() == nil {
+		err := group.Consume(p.ctx, p.config.Topics, handler)
+		if err != nil && p.ctx.Err() == nil {
+			p.log.Errorw("Failed to consume from Kafka", "error", err)
+			p.wait(p.config.ConsumeBackoff)
+		}
+	}
+}
+
+func (p *Input) wait(d time.Duration) {
+	select {
+	case <-p.ctx.Done():
+	case <-time.After(d):
+	}
+}
+
+// Stop stops the kafka input. Offsets of events acknowledged within
+// wait_close are committed before the consumer group is left.
+func (p *Input) Stop() {
+	p.stopOnce.Do(func() {
+		p.log.Info("Stopping Kafka input")
+		p.cancel()
+		p.outlet.Close()
+		p.wg.Wait()
+	})
+}
+
+// Wait stops the kafka input.
+func (p *Input) Wait() {
+	p.Stop()
+}
@@ -0,0 +1,286 @@
+//go:build !integration
+// +build !integration
+
+package kafka
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/filebeat/harvester/reader"
+	"github.com/elastic/beats/filebeat/util"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+const (
+	testTopic = "test-topic"
+	testGroup = "test-group"
+)
+
+type testOutlet struct {
+	events chan *util.Data
+}
+
+func (o *testOutlet) OnEvent(data *util.Data) bool {
+	o.events <- data
+	return true
+}
+
+func (o *testOutlet) Close() error {
+	return nil
+}
+
+// memberAssignment encodes the assignment of partition 0 of topic, as sent by
+// the group coordinator.
+func memberAssignment(topic string) []byte {
+	var buf bytes.Buffer
+	write := func(v interface{}) { binary.Write(&buf, binary.BigEndian, v) }
+
+	write(int16(0)) // version
+	write(int32(1)) // number of topics
+	write(int16(len(topic)))
+	buf.WriteString(topic)
+	write(int32(1))  // number of partitions
+	write(int32(0))  // partition
+	write(int32(-1)) // no user data
+	return buf.Bytes()
+}
+
+func newMockBroker(t *testing.T, messages ...string) *sarama.MockBroker {
+	broker := sarama.NewMockBroker(t, 1)
+
+	fetch := sarama.NewMockFetchResponse(t, len(messages)).SetVersion(3)
+	for i, msg := range messages {
+		fetch.SetMessage(testTopic, 0, int64(i), sarama.StringEncoder(msg))
+	}
+	fetch.SetHighWaterMark(testTopic, 0, int64(len(messages)))
+
+	broker.SetHandlerByMap(map[string]sarama.MockResponse{
+		"MetadataRequest": sarama.NewMockMetadataResponse(t).
+			SetBroker(broker.Addr(), broker.BrokerID()).
+			SetLeader(testTopic, 0, broker.BrokerID()),
+		"FindCoordinatorRequest": sarama.NewMockFindCoordinatorResponse(t).
+			SetCoordinator(sarama.CoordinatorGroup, testGroup, broker),
+		"JoinGroupRequest": sarama.NewMockWrapper(&sarama.JoinGroupResponse{
+			GenerationId: 1,
+			LeaderId:     "leader",
+			MemberId:     "member",
+		}),
+		"SyncGroupRequest": sarama.NewMockWrapper(&sarama.SyncGroupResponse{
+			MemberAssignment: memberAssignment(testTopic),
+		}),
+		"HeartbeatRequest":  sarama.NewMockWrapper(&sarama.HeartbeatResponse{}),
+		"LeaveGroupRequest": sarama.NewMockWrapper(&sarama.LeaveGroupResponse{}),
+		"OffsetFetchRequest": sarama.NewMockOffsetFetchResponse(t).
+			SetOffset(testGroup, testTopic, 0, -1, "", sarama.ErrNoError),
+		"OffsetRequest": sarama.NewMockOffsetResponse(t).SetVersion(1).
+			SetOffset(testTopic, 0, sarama.OffsetOldest, 0).
+			SetOffset(testTopic, 0, sarama.OffsetNewest, int64(len(messages))),
+		"FetchRequest":        fetch,
+		"OffsetCommitRequest": sarama.NewMockOffsetCommitResponse(t),
+	})
+	return broker
+}
+
+// committedOffset returns the last offset committed to the broker, or -1 if
+// no offset has been committed.
+func committedOffset(broker *sarama.MockBroker) int64 {
+	offset := int64(-1)
+	for _, rr := range broker.History() {
+		if req, ok := rr.Request.(*sarama.OffsetCommitRequest); ok {
+			if o, _, err := req.Offset(testTopic, 0); err == nil {
+				offset = o
+			}
+		}
+	}
+	return offset
+}
+
+func TestInputCommitsAfterACK(t *testing.T) {
+	broker := newMockBroker(t, "first", "second", "third")
+	defer broker.Close()
+
+	config := defaultConfig
+	config.Hosts = []string{broker.Addr()}
+	config.Topics = []string{testTopic}
+	config.GroupID = testGroup
+	config.Version = "0.10.2"
+
+	saramaConfig, err := newSaramaConfig(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	saramaConfig.Consumer.Offsets.CommitInterval = 50 * time.Millisecond
+
+	out := &testOutlet{events: make(chan *util.Data, 10)}
+	p := newInput(config, saramaConfig, out)
+	p.Run()
+	defer p.Stop()
+
+	var received []*util.Data
+	for len(received) < 3 {
+		select {
+		case data := <-out.events:
+			received = append(received, data)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("timeout, received %v events", len(received))
+		}
+	}
+
+	for i, expected := range []string{"first", "second", "third"} {
+		msg, _ := received[i].Event.GetValue("message")
+		assert.Equal(t, expected, msg)
+		offset, _ := received[i].Event.GetValue("kafka.offset")
+		assert.Equal(t, int64(i), offset)
+	}
+
+	// no offsets are committed before the events are acknowledged
+	time.Sleep(200 * time.Millisecond)
+	assert.Equal(t, int64(-1), committedOffset(broker))
+
+	received[0].Event.Private.(util.EventACKer).ACK()
+	received[1].Event.Private.(util.EventACKer).ACK()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for committedOffset(broker) != 2 && time.Now().Before(deadline) {
+		time.Sleep(20 * time.Millisecond)
+	}
+	assert.Equal(t, int64(2), committedOffset(broker))
+}
+
+func newTestHandler(json *reader.JSONConfig) *groupHandler {
+	config := defaultConfig
+	config.JSON = json
+	return &groupHandler{config: &config, log: logp.NewLogger("kafka input")}
+}
+
+func TestCreateEvent(t *testing.T) {
+	ts := time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)
+	msg := &sarama.ConsumerMessage{
+		Topic:     testTopic,
+		Partition: 2,
+		Offset:    42,
+		Key:       []byte("key"),
+		Value:     []byte("hello"),
+		Timestamp: ts,
+		Headers: []*sarama.RecordHeader{
+			{Key: []byte("trace-id"), Value: []byte("abc")},
+		},
+	}
+
+	event := newTestHandler(nil).createEvent(msg)
+	assert.Equal(t, ts, event.Timestamp)
+	assert.Equal(t, common.MapStr{
+		"message": "hello",
+		"kafka": common.MapStr{
+			"topic":     testTopic,
+			"partition": int32(2),
+			"offset":    int64(42),
+			"key":       "key",
+			"headers": common.MapStr{
+				"trace-id": "abc",
+			},
+		},
+	}, event.Fields)
+}
+
+func TestCreateEventJSON(t *testing.T) {
+	msg := &sarama.ConsumerMessage{
+		Topic: testTopic,
+		Value: []byte(`{"message": "hello", "level": "info", "@timestamp": "2018-10-01T12:00:00.000Z"}`),
+	}
+
+	event := newTestHandler(&reader.JSONConfig{
+		MessageKey:    "message",
+		KeysUnderRoot: true,
+		OverwriteKeys: true,
+	}).createEvent(msg)
+
+	assert.Equal(t, time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC), event.Timestamp)
+	assert.Equal(t, "hello", event.Fields["message"])
+	assert.Equal(t, "info", event.Fields["level"])
+	_, hasJSON := event.Fields["json"]
+	assert.False(t, hasJSON)
+
+	event = newTestHandler(&reader.JSONConfig{}).createEvent(msg)
+	level, _ := event.GetValue("json.level")
+	assert.Equal(t, "info", level)
+
+	// messages that are not JSON are published as is
+	msg.Value = []byte("not json")
+	event = newTestHandler(&reader.JSONConfig{IgnoreDecodingError: true}).createEvent(msg)
+	assert.Equal(t, "not json", event.Fields["message"])
+}
+
+func TestPendingACKs(t *testing.T) {
+	pending := newPendingACKs()
+	assert.True(t, pending.wait(time.Millisecond))
+
+	pending.add()
+	pending.add()
+	assert.False(t, pending.wait(10*time.Millisecond))
+
+	go func() {
+		pending.done()
+		pending.done()
+	}()
+	assert.True(t, pending.wait(5*time.Second))
+}
+
+func TestConfigValidate(t *testing.T) {
+	valid := map[string]interface{}{
+		"hosts":    []string{"localhost:9092"},
+		"topics":   []string{"logs"},
+		"group_id": "filebeat",
+	}
+
+	tests := map[string]map[string]interface{}{
+		"old version":          {"version": "0.10.1"},
+		"invalid offset":       {"initial_offset": "latest"},
+		"invalid isolation":    {"isolation_level": "none"},
+		"invalid strategy":     {"rebalance.strategy": "sticky"},
+		"missing password":     {"username": "beats"},
+		"invalid mechanism":    {"username": "beats", "password": "secret", "sasl.mechanism": "GSSAPI"},
+		"missing sasl user":    {"sasl.mechanism": "PLAIN"},
+		"invalid max wait":     {"max_wait_time": 0},
+		"invalid fetch min":    {"fetch.min": 0},
+		"missing group":        {"group_id": ""},
+		"missing topics":       {"topics": []string{}},
+		"invalid wait_close":   {"wait_close": "-1s"},
+		"invalid retry config": {"rebalance.max_retries": -1},
+	}
+
+	for name, overrides := range tests {
+		settings := map[string]interface{}{}
+		for k, v := range valid {
+			settings[k] = v
+		}
+		for k, v := range overrides {
+			settings[k] = v
+		}
+
+		cfg, err := common.NewConfigFrom(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		c := defaultConfig
+		assert.Error(t, cfg.Unpack(&c), name)
+	}
+
+	cfg, err := common.NewConfigFrom(valid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := defaultConfig
+	if assert.NoError(t, cfg.Unpack(&c)) {
+		_, err := newSaramaConfig(&c)
+		assert.NoError(t, err)
+	}
+}
@@ -16,16 +16,36 @@ import (
 type config struct {
 	harvester.ForwarderConfig `config:",inline"`
 	Protocol                  common.ConfigNamespace `config:"protocol"`
+	Format                    string                 `config:"format"`
 }
 
+// Supported message formats, auto detects the format of every message.
+const (
+	formatAuto    = "auto"
+	formatRFC3164 = "rfc3164"
+	formatRFC5424 = "rfc5424"
+)
+
 var defaultConfig = config{
 	ForwarderConfig: harvester.ForwarderConfig{
 		Type: "syslog",
 	},
+	Format: formatAuto,
+}
+
+// Validate validates the format of the syslog messages.
+func (c *config) Validate() error {
+	switch c.Format {
+	case formatAuto, formatRFC3164, formatRFC5424:
+		return nil
+	default:
+		return fmt.Errorf("unknown format '%s', must be one of %s, %s or %s", c.Format, formatAuto, formatRFC3164, formatRFC5424)
+	}
 }
 
 var defaultTCP = tcp.Config{
 	LineDelimiter:  "\n",
+	Framing:        tcp.FramingNonTransparent,
 	Timeout:        time.Minute * 5,
 	MaxMessageSize: 20 * humanize.MiByte,
 }
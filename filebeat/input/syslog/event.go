@@ -54,6 +54,13 @@ type event struct {
 	nanosecond int
 	year       int
 	loc        *time.Location
+
+	// RFC5424 only
+	version        int
+	procID         string
+	msgID          string
+	timestamp      time.Time
+	structuredData map[string]map[string]interface{}
 }
 
 // newEvent() return a new event.
@@ -235,8 +242,85 @@ func (s *event) Nanosecond() int {
 	return s.nanosecond
 }
 
-// Timestamp return the timestamp in UTC.
+// SetVersion sets the version of the RFC5424 syslog protocol.
+func (s *event) SetVersion(b []byte) {
+	s.version = bytesToInt(b)
+}
+
+// Version returns the version of the RFC5424 syslog protocol, 0 for RFC3164 events.
+func (s *event) Version() int {
+	return s.version
+}
+
+// SetProcID sets the process ID. Numeric process IDs are set as pid.
+func (s *event) SetProcID(b []byte) {
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			s.procID = string(b)
+			return
+		}
+	}
+	s.SetPid(b)
+}
+
+// ProcID returns the process ID, if the process ID is not numeric.
+func (s *event) ProcID() string {
+	return s.procID
+}
+
+// SetMsgID sets the message type.
+func (s *event) SetMsgID(b []byte) {
+	s.msgID = string(b)
+}
+
+// MsgID returns the message type.
+func (s *event) MsgID() string {
+	return s.msgID
+}
+
+// SetTimestamp sets a timestamp including the time zone.
+func (s *event) SetTimestamp(ts time.Time) {
+	s.timestamp = ts
+}
+
+// AddStructuredData adds a parameter of a structured data element. Parameters
+// appearing multiple times in an element are collected in a list.
+func (s *event) AddStructuredData(id, name, value string) {
+	if s.structuredData == nil {
+		s.structuredData = map[string]map[string]interface{}{}
+	}
+
+	params, ok := s.structuredData[id]
+	if !ok {
+		params = map[string]interface{}{}
+		s.structuredData[id] = params
+	}
+	if name == "" {
+		return
+	}
+
+	switch v := params[name].(type) {
+	case nil:
+		params[name] = value
+	case string:
+		params[name] = []string{v, value}
+	case []string:
+		params[name] = append(v, value)
+	}
+}
+
+// StructuredData returns the parameters of the structured data elements by
+// element ID.
+func (s *event) StructuredData() map[string]map[string]interface{} {
+	return s.structuredData
+}
+
+// Timestamp return the timestamp in UTC. Timestamps including the time zone
+// are returned as is, converted to UTC.
 func (s *event) Timestamp(timezone *time.Location) time.Time {
+	if !s.timestamp.IsZero() {
+		return s.timestamp.UTC()
+	}
 	return time.Date(
 		s.Year(),
 		s.Month(),
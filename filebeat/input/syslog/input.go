@@ -108,10 +108,9 @@ func NewInput(
 	forwarder := harvester.NewForwarder(out)
 	cb := func(data []byte, metadata inputsource.NetworkMetadata) {
 		ev := newEvent()
-		Parse(data, ev)
 		var d *util.Data
-		if !ev.IsValid() {
-			log.Errorw("can't not parse event as syslog rfc3164", "message", string(data))
+		if err := parse(data, ev, config.Format); err != nil {
+			log.Errorw("can't parse event as syslog", "error", err, "message", string(data))
 			// On error revert to the raw bytes content, we need a better way to communicate this kind of
 			// error upstream this should be a global effort.
 			d = &util.Data{
@@ -125,6 +124,7 @@ func NewInput(
 					},
 				},
 			}
+			addTLSFields(d.Event.Fields, metadata)
 		} else {
 			event := createEvent(ev, metadata, time.Local, log)
 			d = &util.Data{Event: *event}
@@ -178,6 +178,21 @@ func (p *Input) Wait() {
 	p.Stop()
 }
 
+// parse parses the message in the configured format. With the auto format
+// RFC5424 messages are detected by their version, other messages are parsed
+// as RFC3164.
+func parse(data []byte, ev *event, format string) error {
+	if format == formatRFC5424 || (format == formatAuto && IsRFC5424(data)) {
+		return ParseRFC5424(data, ev)
+	}
+
+	Parse(data, ev)
+	if !ev.IsValid() {
+		return errors.New("invalid rfc3164 message")
+	}
+	return nil
+}
+
 func createEvent(ev *event, metadata inputsource.NetworkMetadata, timezone *time.Location, log *logp.Logger) *beat.Event {
 	f := common.MapStr{
 		"message": strings.TrimRight(ev.Message(), "\n"),
@@ -200,6 +215,26 @@ func createEvent(ev *event, metadata inputsource.NetworkMetadata, timezone *time
 		process["program"] = ev.Program()
 	}
 
+	if ev.ProcID() != "" {
+		syslog["procid"] = ev.ProcID()
+	}
+
+	if ev.Version() > 0 {
+		syslog["version"] = ev.Version()
+	}
+
+	if ev.MsgID() != "" {
+		syslog["msgid"] = ev.MsgID()
+	}
+
+	if sd := ev.StructuredData(); len(sd) > 0 {
+		structuredData := common.MapStr{}
+		for id, params := range sd {
+			structuredData[id] = common.MapStr(params)
+		}
+		syslog["structured_data"] = structuredData
+	}
+
 	if ev.HasPriority() {
 		syslog["priority"] = ev.Priority()
 
@@ -223,6 +258,7 @@ func createEvent(ev *event, metadata inputsource.NetworkMetadata, timezone *time
 	f["syslog"] = syslog
 	f["event"] = event
 	f["process"] = process
+	addTLSFields(f, metadata)
 
 	return &beat.Event{
 		Timestamp: ev.Timestamp(timezone),
@@ -233,6 +269,13 @@ func createEvent(ev *event, metadata inputsource.NetworkMetadata, timezone *time
 	}
 }
 
+// addTLSFields adds the subject of the verified client certificate.
+func addTLSFields(f common.MapStr, metadata inputsource.NetworkMetadata) {
+	if metadata.TLS != nil && metadata.TLS.ClientSubject != "" {
+		f.Put("tls.client.subject", metadata.TLS.ClientSubject)
+	}
+}
+
 func mapValueToName(v int, m mapper) (string, error) {
 	if v < 0 || v >= len(m) {
 		return "", errors.Errorf("value out of bound: %d", v)
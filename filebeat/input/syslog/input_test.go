@@ -65,6 +65,21 @@ func TestWhenPriorityIsNotSet(t *testing.T) {
 	assert.Equal(t, expected, event.Fields)
 }
 
+func TestClientSubject(t *testing.T) {
+	e := newEvent()
+	e.SetMessage([]byte("hello world"))
+
+	m := dummyMetadata()
+	m.TLS = &inputsource.TLSMetadata{ClientSubject: "CN=client,O=elastic"}
+	event := createEvent(e, m, time.Local, logp.NewLogger("syslog"))
+
+	v, err := event.GetValue("tls.client.subject")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "CN=client,O=elastic", v)
+}
+
 func TestPid(t *testing.T) {
 	t.Run("is set", func(t *testing.T) {
 		e := newEvent()
@@ -155,3 +170,64 @@ func dummyMetadata() inputsource.NetworkMetadata {
 	addr := &net.IPAddr{IP: parsedIP, Zone: ""}
 	return inputsource.NetworkMetadata{RemoteAddr: addr}
 }
+
+func TestRFC5424Fields(t *testing.T) {
+	e := newEvent()
+	err := ParseRFC5424([]byte(`<165>1 2003-10-11T22:14:15.003Z mymachine evntslog worker-1 ID47 [exampleSDID@32473 iut="3" eventSource="Application"] hello world`), e)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	m := dummyMetadata()
+	event := createEvent(e, m, time.Local, logp.NewLogger("syslog"))
+
+	expected := common.MapStr{
+		"source":   "127.0.0.1",
+		"message":  "hello world",
+		"hostname": "mymachine",
+		"process": common.MapStr{
+			"program": "evntslog",
+		},
+		"event": common.MapStr{
+			"severity": 5,
+		},
+		"syslog": common.MapStr{
+			"facility":       20,
+			"severity_label": "Notice",
+			"facility_label": "local4",
+			"priority":       165,
+			"version":        1,
+			"procid":         "worker-1",
+			"msgid":          "ID47",
+			"structured_data": common.MapStr{
+				"exampleSDID@32473": common.MapStr{
+					"iut":         "3",
+					"eventSource": "Application",
+				},
+			},
+		},
+	}
+
+	assert.Equal(t, expected, event.Fields)
+	assert.Equal(t, time.Date(2003, 10, 11, 22, 14, 15, 3000000, time.UTC), event.Timestamp)
+}
+
+func TestParseFormat(t *testing.T) {
+	rfc3164 := []byte("<34>Oct 11 22:14:15 mymachine su: 'su root' failed")
+	rfc5424 := []byte("<34>1 2003-10-11T22:14:15.003Z mymachine su - - - 'su root' failed")
+
+	for _, format := range []string{formatAuto, formatRFC3164} {
+		ev := newEvent()
+		assert.NoError(t, parse(rfc3164, ev, format), format)
+		assert.Equal(t, 0, ev.Version(), format)
+	}
+
+	for _, format := range []string{formatAuto, formatRFC5424} {
+		ev := newEvent()
+		assert.NoError(t, parse(rfc5424, ev, format), format)
+		assert.Equal(t, 1, ev.Version(), format)
+	}
+
+	assert.Error(t, parse(rfc3164, newEvent(), formatRFC5424))
+	assert.Error(t, parse([]byte("hello"), newEvent(), formatAuto))
+}
@@ -0,0 +1,301 @@
+package syslog
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+)
+
+// The RFC5424 format is strictly delimited by spaces, the parser reads the
+// header fields one by one.
+// Ref: https://tools.ietf.org/html/rfc5424#section-6
+//
+// Example:
+// <165>1 2003-10-11T22:14:15.003Z mymachine.example.com evntslog - ID47 [exampleSDID@32473 iut="3" eventSource="Application"] An application event log entry...
+
+const nilValue = '-'
+
+var bom = []byte{0xEF, 0xBB, 0xBF}
+
+// Maximum length of the header fields.
+const (
+	maxHostnameLen = 255
+	maxAppNameLen  = 48
+	maxProcIDLen   = 128
+	maxMsgIDLen    = 32
+	maxSDNameLen   = 32
+)
+
+type rfc5424Parser struct {
+	data  []byte
+	pos   int
+	event *event
+}
+
+// IsRFC5424 returns true if the message starts with a priority followed by a
+// version, as RFC3164 messages never do.
+func IsRFC5424(data []byte) bool {
+	i := bytes.IndexByte(data, '>')
+	if len(data) == 0 || data[0] != '<' || i < 2 || i > 4 || !isDigits(data[1:i]) {
+		return false
+	}
+
+	version := data[i+1:]
+	n := 0
+	for n < len(version) && n < 3 && isDigit(version[n]) {
+		n++
+	}
+	return n > 0 && version[0] != '0' && n < len(version) && version[n] == ' '
+}
+
+// ParseRFC5424 parses a RFC5424 syslog message. Optional fields set to the
+// nil value are not set in the event. If the message has no timestamp, the
+// current time is used.
+func ParseRFC5424(data []byte, event *event) error {
+	p := &rfc5424Parser{data: data, event: event}
+	return p.parse()
+}
+
+func (p *rfc5424Parser) parse() error {
+	if err := p.priority(); err != nil {
+		return err
+	}
+
+	version, err := p.field("version", 3)
+	if err != nil {
+		return err
+	}
+	if !isDigits(version) || version[0] == '0' {
+		return fmt.Errorf("invalid version '%s'", version)
+	}
+	p.event.SetVersion(version)
+
+	if err := p.timestamp(); err != nil {
+		return err
+	}
+
+	fields := []struct {
+		name   string
+		maxLen int
+		set    func([]byte)
+	}{
+		{"hostname", maxHostnameLen, p.event.SetHostname},
+		{"app name", maxAppNameLen, p.event.SetProgram},
+		{"process ID", maxProcIDLen, p.event.SetProcID},
+		{"message ID", maxMsgIDLen, p.event.SetMsgID},
+	}
+	for _, f := range fields {
+		value, err := p.field(f.name, f.maxLen)
+		if err != nil {
+			return err
+		}
+		if !isNil(value) {
+			f.set(value)
+		}
+	}
+
+	if err := p.structuredData(); err != nil {
+		return err
+	}
+
+	if p.pos < len(p.data) {
+		if p.data[p.pos] != ' ' {
+			return fmt.Errorf("expected space after structured data at position %v", p.pos)
+		}
+		p.event.SetMessage(bytes.TrimPrefix(p.data[p.pos+1:], bom))
+	}
+	return nil
+}
+
+// priority parses the priority "<PRIVAL>", PRIVAL is in the range 0-191.
+func (p *rfc5424Parser) priority() error {
+	end := bytes.IndexByte(p.data, '>')
+	if len(p.data) == 0 || p.data[0] != '<' || end < 2 || end > 4 {
+		return fmt.Errorf("invalid priority")
+	}
+
+	prio := p.data[1:end]
+	if !isDigits(prio) || bytesToInt(prio) > 191 || (len(prio) > 1 && prio[0] == '0') {
+		return fmt.Errorf("invalid priority '%s'", prio)
+	}
+
+	p.event.SetPriority(prio)
+	p.pos = end + 1
+	return nil
+}
+
+// field returns the next field of printable ASCII characters, terminated by a
+// space.
+func (p *rfc5424Parser) field(name string, maxLen int) ([]byte, error) {
+	start := p.pos
+	for p.pos < len(p.data) && p.data[p.pos] != ' ' {
+		if !isPrintASCII(p.data[p.pos]) {
+			return nil, fmt.Errorf("invalid character in %v at position %v", name, p.pos)
+		}
+		p.pos++
+	}
+
+	if p.pos >= len(p.data) {
+		return nil, fmt.Errorf("unexpected end of message in %v", name)
+	}
+	if p.pos == start {
+		return nil, fmt.Errorf("empty %v at position %v", name, start)
+	}
+	if p.pos-start > maxLen {
+		return nil, fmt.Errorf("%v exceeds %v characters", name, maxLen)
+	}
+
+	value := p.data[start:p.pos]
+	p.pos++ // skip space
+	return value, nil
+}
+
+func (p *rfc5424Parser) timestamp() error {
+	value, err := p.field("timestamp", len(time.RFC3339Nano))
+	if err != nil {
+		return err
+	}
+
+	if isNil(value) {
+		p.event.SetTimestamp(time.Now())
+		return nil
+	}
+
+	ts, err := time.Parse(time.RFC3339Nano, string(value))
+	if err != nil {
+		return fmt.Errorf("invalid timestamp '%s': %v", value, err)
+	}
+	p.event.SetTimestamp(ts)
+	return nil
+}
+
+// structuredData parses the structured data elements or the nil value.
+func (p *rfc5424Parser) structuredData() error {
+	if p.pos >= len(p.data) {
+		return fmt.Errorf("unexpected end of message in structured data")
+	}
+
+	if p.data[p.pos] == nilValue {
+		p.pos++
+		return nil
+	}
+
+	if p.data[p.pos] != '[' {
+		return fmt.Errorf("invalid structured data at position %v", p.pos)
+	}
+	for p.pos < len(p.data) && p.data[p.pos] == '[' {
+		if err := p.element(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// element parses a single structured data element:
+// [SD-ID *(SP PARAM-NAME="PARAM-VALUE")]
+func (p *rfc5424Parser) element() error {
+	p.pos++ // skip '['
+
+	id, err := p.sdName("structured data ID")
+	if err != nil {
+		return err
+	}
+	p.event.AddStructuredData(id, "", "")
+
+	for {
+		if p.pos >= len(p.data) {
+			return fmt.Errorf("unexpected end of message in structured data element %v", id)
+		}
+
+		switch p.data[p.pos] {
+		case ']':
+			p.pos++
+			return nil
+		case ' ':
+			p.pos++
+		default:
+			return fmt.Errorf("invalid character in structured data element %v at position %v", id, p.pos)
+		}
+
+		name, err := p.sdName("parameter name")
+		if err != nil {
+			return err
+		}
+		if p.pos+1 >= len(p.data) || p.data[p.pos] != '=' || p.data[p.pos+1] != '"' {
+			return fmt.Errorf("expected '=\"' after parameter %v at position %v", name, p.pos)
+		}
+		p.pos += 2
+
+		value, err := p.paramValue()
+		if err != nil {
+			return err
+		}
+		p.event.AddStructuredData(id, name, value)
+	}
+}
+
+// sdName parses a SD-ID or a PARAM-NAME.
+func (p *rfc5424Parser) sdName(name string) (string, error) {
+	start := p.pos
+	for p.pos < len(p.data) && isSDNameChar(p.data[p.pos]) {
+		p.pos++
+	}
+
+	if p.pos == start {
+		return "", fmt.Errorf("empty %v at position %v", name, start)
+	}
+	if p.pos-start > maxSDNameLen {
+		return "", fmt.Errorf("%v exceeds %v characters", name, maxSDNameLen)
+	}
+	return string(p.data[start:p.pos]), nil
+}
+
+// paramValue parses a parameter value up to the closing quote. The escaped
+// characters '"', '\' and ']' are unescaped.
+func (p *rfc5424Parser) paramValue() (string, error) {
+	var value []byte
+	for p.pos < len(p.data) {
+		c := p.data[p.pos]
+		switch {
+		case c == '"':
+			p.pos++
+			return string(value), nil
+		case c == '\\' && p.pos+1 < len(p.data) && isEscaped(p.data[p.pos+1]):
+			value = append(value, p.data[p.pos+1])
+			p.pos += 2
+		default:
+			value = append(value, c)
+			p.pos++
+		}
+	}
+	return "", fmt.Errorf("unexpected end of message in parameter value")
+}
+
+func isNil(b []byte) bool {
+	return len(b) == 1 && b[0] == nilValue
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isDigits(b []byte) bool {
+	for _, c := range b {
+		if !isDigit(c) {
+			return false
+		}
+	}
+	return len(b) > 0
+}
+
+func isPrintASCII(c byte) bool {
+	return c >= 33 && c <= 126
+}
+
+func isSDNameChar(c byte) bool {
+	return isPrintASCII(c) && c != '=' && c != ']' && c != '"'
+}
+
+func isEscaped(c byte) bool {
+	return c == '"' || c == '\\' || c == ']'
+}
@@ -0,0 +1,146 @@
+package syslog
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRFC5424(t *testing.T) {
+	tests := map[string]bool{
+		"<165>1 2003-10-11T22:14:15.003Z mymachine - - - - hello": true,
+		"<34>12 - - - - - -": true,
+		"<34>Oct 11 22:14:15 mymachine su: 'su root' failed":    false,
+		"<38>2018-05-08T10:31:24 localhost prg00000[1234]: seq": false,
+		"<34>0 - - - - - -": false,
+		"<34>1":             false,
+		"Oct 11 22:14:15 mymachine su: 'su root' failed": false,
+		"": false,
+	}
+
+	for msg, expected := range tests {
+		assert.Equal(t, expected, IsRFC5424([]byte(msg)), msg)
+	}
+}
+
+func TestParseRFC5424(t *testing.T) {
+	msg := `<165>1 2003-10-11T22:14:15.003-07:00 mymachine.example.com evntslog 1234 ID47 [exampleSDID@32473 iut="3" eventSource="Application" eventID="1011"][examplePriority@32473 class="high"] An application event log entry`
+
+	ev := newEvent()
+	if err := ParseRFC5424([]byte(msg), ev); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 165, ev.Priority())
+	assert.Equal(t, 1, ev.Version())
+	assert.Equal(t, time.Date(2003, 10, 12, 5, 14, 15, 3000000, time.UTC), ev.Timestamp(time.Local))
+	assert.Equal(t, "mymachine.example.com", ev.Hostname())
+	assert.Equal(t, "evntslog", ev.Program())
+	assert.Equal(t, 1234, ev.Pid())
+	assert.Equal(t, "", ev.ProcID())
+	assert.Equal(t, "ID47", ev.MsgID())
+	assert.Equal(t, "An application event log entry", ev.Message())
+	assert.Equal(t, map[string]map[string]interface{}{
+		"exampleSDID@32473": {
+			"iut":         "3",
+			"eventSource": "Application",
+			"eventID":     "1011",
+		},
+		"examplePriority@32473": {
+			"class": "high",
+		},
+	}, ev.StructuredData())
+}
+
+func TestParseRFC5424NilValues(t *testing.T) {
+	ev := newEvent()
+	if err := ParseRFC5424([]byte("<34>1 - - - - - -"), ev); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, 34, ev.Priority())
+	assert.False(t, ev.Timestamp(time.Local).IsZero())
+	assert.Equal(t, "", ev.Hostname())
+	assert.Equal(t, "", ev.Program())
+	assert.False(t, ev.HasPid())
+	assert.Equal(t, "", ev.MsgID())
+	assert.Equal(t, "", ev.Message())
+	assert.Nil(t, ev.StructuredData())
+}
+
+func TestParseRFC5424StructuredData(t *testing.T) {
+	tests := []struct {
+		title    string
+		sd       string
+		expected map[string]map[string]interface{}
+	}{
+		{
+			title:    "element without parameters",
+			sd:       `[timeQuality]`,
+			expected: map[string]map[string]interface{}{"timeQuality": {}},
+		},
+		{
+			title: "escaped characters",
+			sd:    `[id@1 a="quote \" backslash \\ bracket \] other \n"]`,
+			expected: map[string]map[string]interface{}{
+				"id@1": {"a": `quote " backslash \ bracket ] other \n`},
+			},
+		},
+		{
+			title: "repeated parameter",
+			sd:    `[origin ip="10.0.0.1" ip="10.0.0.2" ip="10.0.0.3"]`,
+			expected: map[string]map[string]interface{}{
+				"origin": {"ip": []string{"10.0.0.1", "10.0.0.2", "10.0.0.3"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.title, func(t *testing.T) {
+			ev := newEvent()
+			if err := ParseRFC5424([]byte("<34>1 - host app - - "+test.sd+" msg"), ev); err != nil {
+				t.Fatal(err)
+			}
+			assert.Equal(t, test.expected, ev.StructuredData())
+			assert.Equal(t, "msg", ev.Message())
+		})
+	}
+}
+
+func TestParseRFC5424ProcID(t *testing.T) {
+	ev := newEvent()
+	if err := ParseRFC5424([]byte("<34>1 - host app worker-1 - - msg"), ev); err != nil {
+		t.Fatal(err)
+	}
+	assert.False(t, ev.HasPid())
+	assert.Equal(t, "worker-1", ev.ProcID())
+}
+
+func TestParseRFC5424BOM(t *testing.T) {
+	ev := newEvent()
+	if err := ParseRFC5424([]byte("<34>1 - host app - - - \xEF\xBB\xBFhello"), ev); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "hello", ev.Message())
+}
+
+func TestParseRFC5424Invalid(t *testing.T) {
+	tests := map[string]string{
+		"invalid priority":             "<192>1 - - - - - -",
+		"missing priority":             "1 - - - - - -",
+		"invalid version":              "<34>a - - - - - -",
+		"invalid timestamp":            "<34>1 2003-10-11 - - - - -",
+		"missing structured data":      "<34>1 - - - - ",
+		"truncated header":             "<34>1 - host",
+		"unclosed element":             `<34>1 - - - - - [id a="1"`,
+		"unclosed parameter value":     `<34>1 - - - - - [id a="1]`,
+		"missing parameter value":      `<34>1 - - - - - [id a]`,
+		"invalid structured data":      "<34>1 - - - - hello",
+		"missing space before message": `<34>1 - - - - - [id]hello`,
+	}
+
+	for title, msg := range tests {
+		assert.Error(t, ParseRFC5424([]byte(msg), newEvent()), title)
+	}
+}
@@ -20,6 +20,7 @@ var defaultConfig = config{
 	},
 	Config: tcp.Config{
 		LineDelimiter:  "\n",
+		Framing:        tcp.FramingNonTransparent,
 		Timeout:        time.Minute * 5,
 		MaxMessageSize: 20 * humanize.MiByte,
 	},
@@ -113,5 +113,8 @@ func createEvent(raw []byte, metadata inputsource.NetworkMetadata) *util.Data {
 			"source":  metadata.RemoteAddr.String(),
 		},
 	}
+	if metadata.TLS != nil && metadata.TLS.ClientSubject != "" {
+		data.Event.PutValue("tls.client.subject", metadata.TLS.ClientSubject)
+	}
 	return data
 }
@@ -28,3 +28,17 @@ func TestCreateEvent(t *testing.T) {
 	from, _ := event.GetValue("source")
 	assert.Equal(t, ip, from)
 }
+
+func TestCreateEventWithTLS(t *testing.T) {
+	addr := &net.IPAddr{IP: net.ParseIP("127.0.0.1"), Zone: ""}
+	mt := inputsource.NetworkMetadata{
+		RemoteAddr: addr,
+		TLS:        &inputsource.TLSMetadata{ClientSubject: "CN=client,O=elastic"},
+	}
+
+	event := createEvent([]byte("hello world"), mt).GetEvent()
+
+	subject, err := event.GetValue("tls.client.subject")
+	assert.NoError(t, err)
+	assert.Equal(t, "CN=client,O=elastic", subject)
+}
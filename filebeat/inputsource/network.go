@@ -14,6 +14,14 @@ type Network interface {
 type NetworkMetadata struct {
 	RemoteAddr net.Addr
 	Truncated  bool
+	TLS        *TLSMetadata
+}
+
+// TLSMetadata defines information about a remote connection using TLS.
+type TLSMetadata struct {
+	// ClientSubject is the subject of the verified client certificate, empty
+	// if the client did not present a certificate.
+	ClientSubject string
 }
 
 // NetworkFunc defines callback executed when a new event is received from a network source.
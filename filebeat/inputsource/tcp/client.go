@@ -2,6 +2,7 @@ package tcp
 
 import (
 	"bufio"
+	"crypto/tls"
 	"net"
 	"time"
 
@@ -47,6 +48,12 @@ func newClient(
 }
 
 func (c *client) handle() error {
+	if conn, ok := c.conn.(*tls.Conn); ok {
+		if err := c.handshake(conn); err != nil {
+			return errors.Wrap(err, "tls handshake failed")
+		}
+	}
+
 	r := NewResetableLimitedReader(NewDeadlineReader(c.conn, c.timeout), c.maxMessageSize)
 	buf := bufio.NewReader(r)
 	scanner := bufio.NewScanner(buf)
@@ -73,6 +80,25 @@ func (c *client) handle() error {
 	return nil
 }
 
+// handshake runs the TLS handshake, such that the client certificate is
+// available before the first message is read.
+func (c *client) handshake(conn *tls.Conn) error {
+	if c.timeout > 0 {
+		conn.SetDeadline(time.Now().Add(c.timeout))
+		defer conn.SetDeadline(time.Time{})
+	}
+
+	if err := conn.Handshake(); err != nil {
+		return err
+	}
+
+	c.metadata.TLS = &inputsource.TLSMetadata{}
+	if chains := conn.ConnectionState().VerifiedChains; len(chains) > 0 && len(chains[0]) > 0 {
+		c.metadata.TLS.ClientSubject = chains[0][0].Subject.String()
+	}
+	return nil
+}
+
 func (c *client) close() {
 	close(c.done)
 	c.conn.Close()
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"github.com/elastic/beats/libbeat/common/cfgtype"
+	"github.com/elastic/beats/libbeat/outputs"
 )
 
 // Name is the human readable name and identifier.
@@ -12,12 +13,24 @@ const Name = "tcp"
 
 type size uint64
 
+// Supported framings of the messages.
+const (
+	// FramingNonTransparent splits messages with the line delimiter (RFC 6587, 3.4.2).
+	FramingNonTransparent = "non_transparent"
+
+	// FramingOctetCounting splits octet counted messages (RFC 6587, 3.4.1), messages
+	// without octet count are split with the line delimiter.
+	FramingOctetCounting = "octet_counting"
+)
+
 // Config exposes the tcp configuration.
 type Config struct {
-	Host           string           `config:"host"`
-	LineDelimiter  string           `config:"line_delimiter" validate:"nonzero"`
-	Timeout        time.Duration    `config:"timeout" validate:"nonzero,positive"`
-	MaxMessageSize cfgtype.ByteSize `config:"max_message_size" validate:"nonzero,positive"`
+	Host           string                   `config:"host"`
+	LineDelimiter  string                   `config:"line_delimiter" validate:"nonzero"`
+	Framing        string                   `config:"framing"`
+	Timeout        time.Duration            `config:"timeout" validate:"nonzero,positive"`
+	MaxMessageSize cfgtype.ByteSize         `config:"max_message_size" validate:"nonzero,positive"`
+	TLS            *outputs.TLSServerConfig `config:"ssl"`
 }
 
 // Validate validates the Config option for the tcp input.
@@ -25,5 +38,8 @@ func (c *Config) Validate() error {
 	if len(c.Host) == 0 {
 		return fmt.Errorf("need to specify the host using the `host:port` syntax")
 	}
+	if c.Framing != FramingNonTransparent && c.Framing != FramingOctetCounting {
+		return fmt.Errorf("unknown framing '%s', must be %s or %s", c.Framing, FramingNonTransparent, FramingOctetCounting)
+	}
 	return nil
 }
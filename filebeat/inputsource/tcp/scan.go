@@ -3,8 +3,13 @@ package tcp
 import (
 	"bufio"
 	"bytes"
+	"fmt"
+	"strconv"
 )
 
+// maxOctetCountLen is the maximum number of digits of an octet count.
+const maxOctetCountLen = 10
+
 // factoryDelimiter return a function to split line using a custom delimiter supporting multibytes
 // delimiter, the delimiter is stripped from the returned value.
 func factoryDelimiter(delimiter []byte) bufio.SplitFunc {
@@ -29,3 +34,39 @@ func dropDelimiter(data []byte, delimiter []byte) []byte {
 	}
 	return data
 }
+
+// factoryOctetCounting return a function to split octet counted messages as described in
+// RFC6587, "MSG-LEN SP MSG". Messages not starting with an octet count are split by the delimiter split
+// function, such that both framings can be used on the same connection.
+func factoryOctetCounting(maxMessageSize int, delimiter bufio.SplitFunc) bufio.SplitFunc {
+	return func(data []byte, eof bool) (int, []byte, error) {
+		if len(data) == 0 || data[0] < '1' || data[0] > '9' {
+			return delimiter(data, eof)
+		}
+
+		i := 0
+		for i < len(data) && i <= maxOctetCountLen && data[i] >= '0' && data[i] <= '9' {
+			i++
+		}
+		if i == len(data) && !eof {
+			return 0, nil, nil
+		}
+		if i == len(data) || i > maxOctetCountLen || data[i] != ' ' {
+			return delimiter(data, eof)
+		}
+
+		length, _ := strconv.Atoi(string(data[:i]))
+		if length > maxMessageSize {
+			return 0, nil, fmt.Errorf("octet count %d exceeds the maximum message size of %d", length, maxMessageSize)
+		}
+
+		end := i + 1 + length
+		if len(data) < end {
+			if eof {
+				return 0, nil, fmt.Errorf("incomplete octet counted frame, expected %d bytes got %d", length, len(data)-i-1)
+			}
+			return 0, nil, nil
+		}
+		return end, data[i+1 : end], nil
+	}
+}
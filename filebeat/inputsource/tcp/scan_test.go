@@ -89,3 +89,62 @@ func TestCustomDelimiter(t *testing.T) {
 		})
 	}
 }
+
+func TestRFC6587(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "Octet counted messages",
+			text:     "5 hello7 bonjour4 hola",
+			expected: []string{"hello", "bonjour", "hola"},
+		},
+		{
+			name:     "Octet counted messages containing the delimiter",
+			text:     "11 hello\nworld3 hey",
+			expected: []string{"hello\nworld", "hey"},
+		},
+		{
+			name:     "Mixed framing",
+			text:     "5 hello<14>bonjour\n4 holahey there\n",
+			expected: []string{"hello", "<14>bonjour", "hola", "hey there"},
+		},
+		{
+			name:     "Messages starting with digits",
+			text:     "2018-06-01 hello\n0 bonjour\n",
+			expected: []string{"2018-06-01 hello", "0 bonjour"},
+		},
+		{
+			name:     "Incomplete frame",
+			text:     "5 hello10 bonjour",
+			expected: []string{"hello"},
+			err:      true,
+		},
+		{
+			name: "Octet count exceeding the maximum message size",
+			text: "1000 hello",
+			err:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			buf := strings.NewReader(test.text)
+			scanner := bufio.NewScanner(buf)
+			scanner.Split(factoryOctetCounting(100, bufio.ScanLines))
+			var elements []string
+			for scanner.Scan() {
+				elements = append(elements, scanner.Text())
+			}
+			assert.EqualValues(t, test.expected, elements)
+			if test.err {
+				assert.Error(t, scanner.Err())
+			} else {
+				assert.NoError(t, scanner.Err())
+			}
+		})
+	}
+}
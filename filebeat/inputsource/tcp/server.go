@@ -3,12 +3,14 @@ package tcp
 import (
 	"bufio"
 	"bytes"
+	"crypto/tls"
 	"fmt"
 	"net"
 	"sync"
 
 	"github.com/elastic/beats/filebeat/inputsource"
 	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
 )
 
 // Server represent a TCP server
@@ -21,6 +23,7 @@ type Server struct {
 	wg        sync.WaitGroup
 	done      chan struct{}
 	splitFunc bufio.SplitFunc
+	tlsConfig *tls.Config
 	log       *logp.Logger
 }
 
@@ -34,15 +37,27 @@ func New(
 		return nil, fmt.Errorf("empty line delimiter")
 	}
 
+	tlsConfig, err := outputs.LoadTLSServerConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	sf := splitFunc([]byte(config.LineDelimiter))
-	return &Server{
+	if config.Framing == FramingOctetCounting {
+		sf = factoryOctetCounting(int(config.MaxMessageSize), sf)
+	}
+	s := &Server{
 		config:    config,
 		callback:  callback,
 		clients:   make(map[*client]struct{}, 0),
 		done:      make(chan struct{}),
 		splitFunc: sf,
 		log:       logp.NewLogger("tcp").With("address", config.Host),
-	}, nil
+	}
+	if tlsConfig != nil {
+		s.tlsConfig = tlsConfig.BuildServerConfig()
+	}
+	return s, nil
 }
 
 // Start listen to the TCP socket.
@@ -53,7 +68,12 @@ func (s *Server) Start() error {
 		return err
 	}
 
-	s.log.Info("Started listening for TCP connection")
+	if s.tlsConfig != nil {
+		s.Listener = tls.NewListener(s.Listener, s.tlsConfig)
+		s.log.Info("Started listening for TLS connection")
+	} else {
+		s.log.Info("Started listening for TCP connection")
+	}
 
 	s.wg.Add(1)
 	go func() {
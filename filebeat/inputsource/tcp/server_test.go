@@ -1,9 +1,13 @@
 package tcp
 
 import (
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"math/rand"
 	"net"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -13,10 +17,12 @@ import (
 
 	"github.com/elastic/beats/filebeat/inputsource"
 	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs/transport/transptest"
 )
 
 var defaultConfig = Config{
 	LineDelimiter:  "\n",
+	Framing:        FramingNonTransparent,
 	Timeout:        time.Minute * 5,
 	MaxMessageSize: 20 * humanize.MiByte,
 }
@@ -227,6 +233,113 @@ func TestReceiveNewEventsConcurrently(t *testing.T) {
 	}
 }
 
+func TestReceiveEventsWithTLS(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tcp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// the certificate is self-signed, so it is used as CA, server and client
+	// certificate
+	certName := filepath.Join(dir, "ca")
+	transptest.GenCertForTestingPurpose(t, "127.0.0.1", certName, "")
+	cert, err := tls.LoadX509KeyPair(certName+".pem", certName+".key")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name            string
+		clientAuth      string
+		clientCert      bool
+		expectedSubject string
+		rejected        bool
+	}{
+		{
+			name: "NoClientAuthentication",
+		},
+		{
+			name:            "RequiredWithCertificate",
+			clientAuth:      "required",
+			clientCert:      true,
+			expectedSubject: "OU=beats,O=elastic,C=US",
+		},
+		{
+			name:       "RequiredWithoutCertificate",
+			clientAuth: "required",
+			rejected:   true,
+		},
+		{
+			name:       "OptionalWithoutCertificate",
+			clientAuth: "optional",
+		},
+		{
+			name:            "OptionalWithCertificate",
+			clientAuth:      "optional",
+			clientCert:      true,
+			expectedSubject: "OU=beats,O=elastic,C=US",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ch := make(chan *info, 1)
+			to := func(message []byte, mt inputsource.NetworkMetadata) {
+				ch <- &info{message: string(message), mt: mt}
+			}
+
+			settings := map[string]interface{}{
+				"host":            "127.0.0.1:0",
+				"ssl.certificate": certName + ".pem",
+				"ssl.key":         certName + ".key",
+			}
+			if test.clientAuth != "" {
+				settings["ssl.certificate_authorities"] = []string{certName + ".pem"}
+				settings["ssl.client_authentication"] = test.clientAuth
+			}
+			cfg, _ := common.NewConfigFrom(settings)
+			config := defaultConfig
+			if err := cfg.Unpack(&config); err != nil {
+				t.Fatal(err)
+			}
+			server, err := New(&config, to)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := server.Start(); err != nil {
+				t.Fatal(err)
+			}
+			defer server.Stop()
+
+			clientConfig := &tls.Config{InsecureSkipVerify: true}
+			if test.clientCert {
+				clientConfig.Certificates = []tls.Certificate{cert}
+			}
+			conn, err := tls.Dial("tcp", server.Listener.Addr().String(), clientConfig)
+			if err == nil {
+				fmt.Fprintln(conn, "hello")
+				conn.Close()
+			}
+
+			select {
+			case event := <-ch:
+				if test.rejected {
+					t.Fatal("connection without client certificate has not been rejected")
+				}
+				assert.Equal(t, "hello", event.message)
+				if assert.NotNil(t, event.mt.TLS) {
+					assert.Equal(t, test.expectedSubject, event.mt.TLS.ClientSubject)
+				}
+			case <-time.After(time.Second):
+				if !test.rejected {
+					t.Fatal("timeout waiting for event")
+				}
+			}
+		})
+	}
+}
+
 func randomString(l int) string {
 	charsets := []byte("abcdefghijklmnopqrstuvwzyzABCDEFGHIJKLMNOPQRSTUVWZYZ0123456789")
 	message := make([]byte, l)
@@ -7,6 +7,14 @@ import (
 	"github.com/elastic/beats/filebeat/input/file"
 )
 
+// EventACKer can be set as private data of an event by inputs that need to be
+// notified once the event has been acknowledged by the outputs, e.g. to commit
+// the position of the event in the source. Events with a state must not use
+// EventACKer, as the state is used as private data.
+type EventACKer interface {
+	ACK()
+}
+
 type Data struct {
 	Event beat.Event
 	state file.State
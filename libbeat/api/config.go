@@ -1,9 +1,19 @@
 package api
 
+import "time"
+
 type Config struct {
 	Enabled bool
 	Host    string
 	Port    int
+	Health  HealthConfig
+}
+
+// HealthConfig configures the output health reported by the /health endpoint.
+type HealthConfig struct {
+	// FailureThreshold is the duration an output must report errors only,
+	// before it is considered failing.
+	FailureThreshold time.Duration `config:"failure_threshold" validate:"min=0"`
 }
 
 var (
@@ -11,5 +21,8 @@ var (
 		Enabled: false,
 		Host:    "localhost",
 		Port:    5066,
+		Health: HealthConfig{
+			FailureThreshold: 60 * time.Second,
+		},
 	}
 )
@@ -0,0 +1,73 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+// healthHandler reports the overall health and the health state of every
+// output. The status code is 503 if any output is failing.
+func healthHandler(config HealthConfig) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		state := outputs.Healthy
+		states := common.MapStr{}
+		for name, st := range outputs.HealthStatuses(config.FailureThreshold) {
+			states[name] = st.State.String()
+			if st.State > state {
+				state = st.State
+			}
+		}
+
+		if state == outputs.Failing {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+
+		data := common.MapStr{
+			"status":  state.String(),
+			"outputs": states,
+		}
+		print(w, data, r.URL)
+	}
+}
+
+// outputsHandler reports the detailed health status of every output.
+func outputsHandler(config HealthConfig) func(http.ResponseWriter, *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+
+		now := time.Now()
+		data := common.MapStr{}
+		for name, st := range outputs.HealthStatuses(config.FailureThreshold) {
+			data[name] = healthStatusFields(st, now)
+		}
+		print(w, data, r.URL)
+	}
+}
+
+func healthStatusFields(st outputs.HealthStatus, now time.Time) common.MapStr {
+	fields := common.MapStr{
+		"state": st.State.String(),
+	}
+
+	if !st.LastSuccess.IsZero() {
+		fields["last_success"] = common.MapStr{
+			"time":   common.Time(st.LastSuccess),
+			"ago_ms": int64(now.Sub(st.LastSuccess) / time.Millisecond),
+		}
+	}
+	if !st.FailingSince.IsZero() {
+		fields["failing_since"] = common.Time(st.FailingSince)
+	}
+	if st.LastError != nil {
+		fields["last_error"] = common.MapStr{
+			"message": st.LastError.Error(),
+			"time":    common.Time(st.LastErrorTime),
+		}
+	}
+	return fields
+}
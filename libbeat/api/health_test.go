@@ -0,0 +1,63 @@
+// +build !integration
+
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+func getJSON(t *testing.T, handler http.HandlerFunc, path string) (int, map[string]interface{}) {
+	w := httptest.NewRecorder()
+	handler(w, httptest.NewRequest("GET", path, nil))
+
+	var body map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatal(err)
+	}
+	return w.Code, body
+}
+
+func TestHealthHandler(t *testing.T) {
+	healthy := outputs.NewHealth()
+	healthy.Success()
+	failing := outputs.NewHealth()
+	failing.Failure(errors.New("connection refused"))
+
+	outputs.RegisterHealth("test-healthy", healthy)
+	outputs.RegisterHealth("test-failing", failing)
+
+	// failing output is degraded until the failure threshold is reached
+	code, body := getJSON(t, healthHandler(HealthConfig{FailureThreshold: time.Hour}), "/health")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "degraded", body["status"])
+	assert.Equal(t, map[string]interface{}{
+		"test-healthy": "healthy",
+		"test-failing": "degraded",
+	}, body["outputs"])
+
+	code, body = getJSON(t, healthHandler(HealthConfig{FailureThreshold: 0}), "/health")
+	assert.Equal(t, http.StatusServiceUnavailable, code)
+	assert.Equal(t, "failing", body["status"])
+
+	code, body = getJSON(t, outputsHandler(HealthConfig{FailureThreshold: 0}), "/outputs?pretty")
+	assert.Equal(t, http.StatusOK, code)
+
+	status := body["test-failing"].(map[string]interface{})
+	assert.Equal(t, "failing", status["state"])
+	assert.Equal(t, "connection refused", status["last_error"].(map[string]interface{})["message"])
+	assert.NotContains(t, status, "last_success")
+
+	status = body["test-healthy"].(map[string]interface{})
+	assert.Equal(t, "healthy", status["state"])
+	assert.Contains(t, status["last_success"], "ago_ms")
+	assert.NotContains(t, status, "last_error")
+}
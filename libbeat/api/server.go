@@ -26,6 +26,8 @@ func Start(cfg *common.Config, info beat.Info) {
 		// register handlers
 		mux.HandleFunc("/", rootHandler(info))
 		mux.HandleFunc("/stats", statsHandler)
+		mux.HandleFunc("/health", healthHandler(config.Health))
+		mux.HandleFunc("/outputs", outputsHandler(config.Health))
 
 		url := config.Host + ":" + strconv.Itoa(config.Port)
 		logp.Info("Metrics endpoint listening on: %s", url)
@@ -68,8 +70,8 @@ func statsHandler(w http.ResponseWriter, r *http.Request) {
 func print(w http.ResponseWriter, data common.MapStr, u *url.URL) {
 	query := u.Query()
 	if _, ok := query["pretty"]; ok {
-		fmt.Fprintf(w, data.StringToPrint())
+		fmt.Fprint(w, data.StringToPrint())
 	} else {
-		fmt.Fprintf(w, data.String())
+		fmt.Fprint(w, data.String())
 	}
 }
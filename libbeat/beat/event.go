@@ -2,6 +2,7 @@ package beat
 
 import (
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/elastic/beats/libbeat/common"
@@ -19,7 +20,8 @@ type Event struct {
 }
 
 var (
-	errNoTimestamp = errors.New("value is no timestamp")
+	errNoTimestamp     = errors.New("value is no timestamp")
+	errInvalidMetadata = errors.New("value must be written to a key in @metadata")
 )
 
 // SetID overwrites the "id" field in the events metadata.
@@ -31,10 +33,20 @@ func (e *Event) SetID(id string) {
 	e.Meta["id"] = id
 }
 
+// MetadataKey is the prefix used to access the Meta fields of an event with
+// GetValue, PutValue and Delete (e.g. '@metadata.id').
+const MetadataKey = "@metadata"
+
 func (e *Event) GetValue(key string) (interface{}, error) {
 	if key == "@timestamp" {
 		return e.Timestamp, nil
 	}
+	if subKey, ok := metadataKey(key); ok {
+		if subKey == "" {
+			return e.Meta, nil
+		}
+		return e.Meta.GetValue(subKey)
+	}
 	return e.Fields.GetValue(key)
 }
 
@@ -50,10 +62,43 @@ func (e *Event) PutValue(key string, v interface{}) (interface{}, error) {
 		}
 	}
 
-	// TODO: add support to write into '@metadata'?
+	if subKey, ok := metadataKey(key); ok {
+		if subKey == "" {
+			return nil, errInvalidMetadata
+		}
+		if e.Meta == nil {
+			e.Meta = common.MapStr{}
+		}
+		return e.Meta.Put(subKey, v)
+	}
+
 	return e.Fields.Put(key, v)
 }
 
 func (e *Event) Delete(key string) error {
+	if subKey, ok := metadataKey(key); ok {
+		if subKey == "" {
+			e.Meta = nil
+			return nil
+		}
+		return e.Meta.Delete(subKey)
+	}
 	return e.Fields.Delete(key)
 }
+
+// metadataKey checks if key addresses the Meta fields and returns the key
+// relative to Meta.
+func metadataKey(key string) (string, bool) {
+	if !strings.HasPrefix(key, MetadataKey) {
+		return "", false
+	}
+
+	subKey := key[len(MetadataKey):]
+	if subKey == "" {
+		return "", true
+	}
+	if subKey[0] == '.' {
+		return subKey[1:], true
+	}
+	return "", false
+}
@@ -0,0 +1,44 @@
+package beat
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestEventMetadata(t *testing.T) {
+	event := &Event{Fields: common.MapStr{}}
+
+	_, err := event.PutValue("@metadata._id", "abc")
+	assert.NoError(t, err)
+	assert.Equal(t, common.MapStr{"_id": "abc"}, event.Meta)
+	assert.Empty(t, event.Fields)
+
+	v, err := event.GetValue("@metadata._id")
+	assert.NoError(t, err)
+	assert.Equal(t, "abc", v)
+
+	v, err = event.GetValue("@metadata")
+	assert.NoError(t, err)
+	assert.Equal(t, event.Meta, v)
+
+	_, err = event.PutValue("@metadata", "abc")
+	assert.Error(t, err)
+
+	assert.NoError(t, event.Delete("@metadata._id"))
+	assert.Empty(t, event.Meta)
+
+	_, err = event.GetValue("@metadata._id")
+	assert.Equal(t, common.ErrKeyNotFound, err)
+}
+
+func TestEventMetadataPrefix(t *testing.T) {
+	event := &Event{Fields: common.MapStr{}}
+
+	_, err := event.PutValue("@metadatax", "abc")
+	assert.NoError(t, err)
+	assert.Nil(t, event.Meta)
+	assert.Equal(t, common.MapStr{"@metadatax": "abc"}, event.Fields)
+}
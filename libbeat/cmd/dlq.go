@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/cmd/instance"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cli"
+	"github.com/elastic/beats/libbeat/outputs/dlq"
+	"github.com/elastic/beats/libbeat/publisher/pipeline"
+)
+
+// genDLQCmd initializes the dlq command to manage the dead letter queue of
+// the output. The replay subcommand publishes all events in the dead letter
+// queue again.
+func genDLQCmd(name, version string) *cobra.Command {
+	dlqCmd := &cobra.Command{
+		Use:   "dlq",
+		Short: "Manage the dead letter queue of events rejected by the output",
+	}
+
+	dlqCmd.AddCommand(genDLQReplayCmd(name, version))
+	return dlqCmd
+}
+
+func genDLQReplayCmd(name, version string) *cobra.Command {
+	var flagKeep bool
+	var flagOutput string
+	command := &cobra.Command{
+		Use:   "replay [FILE...]",
+		Short: "Publish the events in the dead letter queue using the configured output",
+		Long: `Publish the events in the dead letter queue using the configured output.
+If no file is given, all files in the configured dead letter queue are
+replayed, oldest file first. Files are removed once all events have been
+acknowledged by the output. The beat must be stopped while replaying.`,
+		Run: cli.RunWith(func(cmd *cobra.Command, args []string) error {
+			b, err := instance.NewBeat(name, "", version)
+			if err != nil {
+				return fmt.Errorf("error initializing beat: %s", err)
+			}
+			if err = b.Init(); err != nil {
+				return fmt.Errorf("error initializing beat: %s", err)
+			}
+
+			out, err := dlqOutput(b, flagOutput)
+			if err != nil {
+				return err
+			}
+			return replayDLQ(b, out, args, flagKeep)
+		}),
+	}
+	command.Flags().BoolVar(&flagKeep, "keep", false, "Keep the files after all events have been replayed")
+	command.Flags().StringVar(&flagOutput, "output", "", "Name of the output to replay the events with, if multiple outputs are configured")
+	return command
+}
+
+// dlqOutput returns the output configuration to replay the events with. A
+// named output must be selected if the outputs are configured in the
+// 'outputs' list.
+func dlqOutput(b *instance.Beat, name string) (common.ConfigNamespace, error) {
+	named := b.Config.Pipeline.Outputs
+	if len(named) == 0 {
+		if name != "" {
+			return common.ConfigNamespace{}, fmt.Errorf("no named outputs are configured")
+		}
+		if !b.Config.Output.IsSet() {
+			return common.ConfigNamespace{}, fmt.Errorf("no output is configured")
+		}
+		return b.Config.Output, nil
+	}
+
+	if name == "" {
+		return common.ConfigNamespace{}, fmt.Errorf("outputs are configured in the 'outputs' list, select the output with --output")
+	}
+	for _, out := range named {
+		if out.Name == name {
+			return out.Output, nil
+		}
+	}
+	return common.ConfigNamespace{}, fmt.Errorf("output '%v' is not configured", name)
+}
+
+func replayDLQ(b *instance.Beat, out common.ConfigNamespace, files []string, keep bool) error {
+	path, err := dlqFilePath(b.Info.Beat, out)
+	if err != nil && len(files) == 0 {
+		return err
+	}
+
+	// Events are written to the dead letter queue while the beat is running,
+	// files must not be moved or removed until the beat is stopped.
+	if err == nil {
+		if err := dlq.CheckNotLocked(path); err != nil {
+			return fmt.Errorf("dead letter queue %v can not be replayed while %v is running: %v",
+				path, b.Info.Beat, err)
+		}
+	}
+
+	if len(files) == 0 {
+		files, err = prepareDLQReplay(path)
+		if err != nil {
+			return err
+		}
+		if len(files) == 0 {
+			fmt.Printf("No events in dead letter queue %v\n", path)
+			return nil
+		}
+	}
+
+	// Replayed events have been processed already, so the processors and
+	// fields configured for the pipeline are not applied again. The memory
+	// queue is used, so the spool file of the beat is not modified.
+	p, err := pipeline.Load(b.Info, nil, pipeline.Config{}, out)
+	if err != nil {
+		return fmt.Errorf("error initializing output: %s", err)
+	}
+	defer p.Close()
+
+	var pending sync.WaitGroup
+	client, err := p.ConnectWith(beat.ClientConfig{
+		PublishMode: beat.GuaranteedSend,
+		ACKCount: func(n int) {
+			pending.Add(-n)
+		},
+	})
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	for _, file := range files {
+		count := 0
+		skipped, err := dlq.ReadFile(file, func(r dlq.Record) error {
+			count++
+			pending.Add(1)
+			client.Publish(r.BeatEvent())
+			return nil
+		})
+
+		// wait for all events published so far to be ACKed, so the file is
+		// only removed if no event has been lost.
+		pending.Wait()
+		if err != nil {
+			return fmt.Errorf("replayed %v events from %v: %s", count, file, err)
+		}
+
+		if skipped > 0 {
+			fmt.Printf("Replayed %v events from %v, skipped %v incomplete records\n", count, file, skipped)
+		} else {
+			fmt.Printf("Replayed %v events from %v\n", count, file)
+		}
+		if !keep {
+			if err := os.Remove(file); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dlqFilePath returns the dead letter queue file configured for the output.
+func dlqFilePath(beatName string, out common.ConfigNamespace) (string, error) {
+	settings := struct {
+		DeadLetterQueue dlq.Config `config:"dead_letter_queue"`
+	}{dlq.DefaultConfig()}
+	if err := out.Config().Unpack(&settings); err != nil {
+		return "", err
+	}
+
+	if !settings.DeadLetterQueue.Enabled {
+		return "", fmt.Errorf("the dead letter queue is not enabled for the %v output", out.Name())
+	}
+	return settings.DeadLetterQueue.FilePath(beatName), nil
+}
+
+// prepareDLQReplay moves all dead letter queue files out of the way, so events
+// rejected again while replaying are written to a new file. Files left over
+// from a replay that did not finish are included in the returned list.
+func prepareDLQReplay(path string) ([]string, error) {
+	files, err := dlq.Files(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := time.Now().Unix()
+	for i, file := range files {
+		replay := fmt.Sprintf("%v.replay-%v-%04d", path, ts, i)
+		if err := os.Rename(file, replay); err != nil {
+			return nil, err
+		}
+	}
+
+	// lexical order is the replay order, as long as the number of digits in the
+	// timestamp does not change
+	replays, err := filepath.Glob(path + ".replay-*")
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(replays)
+	return replays, nil
+}
@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/cmd/instance"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/publisher"
+)
+
+var replayedEvents int32
+
+type replayTestClient struct{}
+
+func (replayTestClient) Close() error { return nil }
+
+func (replayTestClient) Publish(batch publisher.Batch) error {
+	atomic.AddInt32(&replayedEvents, int32(len(batch.Events())))
+	batch.ACK()
+	return nil
+}
+
+func init() {
+	outputs.RegisterType("dlq_replay_test", func(
+		_ beat.Info,
+		_ outputs.Observer,
+		_ *common.Config,
+	) (outputs.Group, error) {
+		return outputs.Success(0, 0, replayTestClient{})
+	})
+}
+
+func TestReplayDLQIncompleteRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.dlq.replay-1-0000")
+	content := `{"output":"elasticsearch","event":{"fields":{"a":1}}}` + "\n" +
+		`{"output":"elasticsearch","event":{"fields":{"a":2}}}` + "\n" +
+		`{"output":"elast`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var out common.ConfigNamespace
+	cfg, err := common.NewConfigFrom(map[string]interface{}{"dlq_replay_test": map[string]interface{}{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cfg.Unpack(&out); err != nil {
+		t.Fatal(err)
+	}
+
+	b := &instance.Beat{Beat: beat.Beat{Info: beat.Info{Beat: "testbeat"}}}
+	err = replayDLQ(b, out, []string{path}, false)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	assert.Equal(t, int32(2), atomic.LoadInt32(&replayedEvents))
+	_, err = os.Stat(path)
+	assert.True(t, os.IsNotExist(err), "replayed file must be removed")
+}
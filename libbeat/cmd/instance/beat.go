@@ -52,6 +52,12 @@ import (
 	_ "github.com/elastic/beats/libbeat/processors/add_host_metadata"
 	_ "github.com/elastic/beats/libbeat/processors/add_kubernetes_metadata"
 	_ "github.com/elastic/beats/libbeat/processors/add_locale"
+	_ "github.com/elastic/beats/libbeat/processors/dissect"
+	_ "github.com/elastic/beats/libbeat/processors/fingerprint"
+	_ "github.com/elastic/beats/libbeat/processors/ratelimit"
+	_ "github.com/elastic/beats/libbeat/processors/redact"
+	_ "github.com/elastic/beats/libbeat/processors/script"
+	_ "github.com/elastic/beats/libbeat/processors/timestamp"
 
 	// Register autodiscover providers
 	_ "github.com/elastic/beats/libbeat/autodiscover/providers/docker"
@@ -358,14 +364,17 @@ func (b *Beat) Setup(bt beat.Creator, template, dashboards, machineLearning, pip
 		}
 
 		if template {
-			outCfg := b.Config.Output
-
-			if outCfg.Name() != "elasticsearch" {
+			esConfig := b.elasticsearchOutput()
+			if esConfig == nil {
 				return fmt.Errorf("Template loading requested but the Elasticsearch output is not configured/enabled")
 			}
 
-			esConfig := outCfg.Config()
-			if tmplCfg := b.Config.Template; tmplCfg == nil || tmplCfg.Enabled() {
+			ilmCfg, err := b.ilmConfig()
+			if err != nil {
+				return err
+			}
+
+			if tmplCfg := b.Config.Template; tmplCfg == nil || tmplCfg.Enabled() || ilmCfg.Enabled {
 				loadCallback, err := b.templateLoadingCallback()
 				if err != nil {
 					return err
@@ -405,7 +414,10 @@ func (b *Beat) Setup(bt beat.Creator, template, dashboards, machineLearning, pip
 		}
 
 		if pipelines && b.OverwritePipelinesCallback != nil {
-			esConfig := b.Config.Output.Config()
+			esConfig := b.elasticsearchOutput()
+			if esConfig == nil {
+				return fmt.Errorf("Pipeline loading requested but the Elasticsearch output is not configured/enabled")
+			}
 			err = b.OverwritePipelinesCallback(esConfig)
 			if err != nil {
 				return err
@@ -594,11 +606,7 @@ func (b *Beat) loadDashboards(ctx context.Context, force bool) error {
 	}
 
 	if b.Config.Dashboards.Enabled() {
-		var esConfig *common.Config
-
-		if b.Config.Output.Name() == "elasticsearch" {
-			esConfig = b.Config.Output.Config()
-		}
+		esConfig := b.elasticsearchOutput()
 		err := dashboards.ImportDashboards(ctx, b.Info.Beat, b.Info.Hostname, paths.Resolve(paths.Home, ""),
 			b.Config.Kibana, esConfig, b.Config.Dashboards, nil)
 		if err != nil {
@@ -626,13 +634,13 @@ func (b *Beat) registerTemplateLoading() error {
 	}
 
 	// Loads template by default if esOutput is enabled
-	if b.Config.Output.Name() == "elasticsearch" {
+	if esConfig := b.elasticsearchOutput(); esConfig != nil {
 
 		// Get ES Index name for comparison
 		esCfg := struct {
 			Index string `config:"index"`
 		}{}
-		err := b.Config.Output.Config().Unpack(&esCfg)
+		err := esConfig.Unpack(&esCfg)
 		if err != nil {
 			return err
 		}
@@ -641,10 +649,15 @@ func (b *Beat) registerTemplateLoading() error {
 			return fmt.Errorf("setup.template.name and setup.template.pattern have to be set if index name is modified.")
 		}
 
-		if b.Config.Template == nil || (b.Config.Template != nil && b.Config.Template.Enabled()) {
+		ilmCfg, err := b.ilmConfig()
+		if err != nil {
+			return err
+		}
+
+		if b.Config.Template == nil || b.Config.Template.Enabled() || ilmCfg.Enabled {
 
-			// load template through callback to make sure it is also loaded
-			// on reconnecting
+			// load template and lifecycle policy through callback to make sure
+			// they are also loaded on reconnecting
 			callback, err := b.templateLoadingCallback()
 			if err != nil {
 				return err
@@ -658,19 +671,54 @@ func (b *Beat) registerTemplateLoading() error {
 
 // Build and return a callback to load index template into ES
 func (b *Beat) templateLoadingCallback() (func(esClient *elasticsearch.Client) error, error) {
+	ilmCfg, err := b.ilmConfig()
+	if err != nil {
+		return nil, err
+	}
+
 	callback := func(esClient *elasticsearch.Client) error {
 		if b.Config.Template == nil {
 			b.Config.Template = common.NewConfig()
 		}
 
-		loader, err := template.NewLoader(b.Config.Template, esClient, b.Info)
-		if err != nil {
-			return fmt.Errorf("Error creating Elasticsearch template loader: %v", err)
+		// The lifecycle policy is installed before the template referencing
+		// it. The rollover alias is bootstrapped last, so the first index
+		// already matches the template.
+		var ilmLoader *template.ILMLoader
+		tmplCfg := b.Config.Template
+		if ilmCfg.Enabled {
+			ilmLoader = template.NewILMLoader(ilmCfg, esClient, b.Info)
+			if err := ilmLoader.CheckSupported(); err != nil {
+				return err
+			}
+
+			if err := ilmLoader.LoadPolicy(); err != nil {
+				return fmt.Errorf("Error loading Elasticsearch ILM policy: %v", err)
+			}
+
+			cfg, err := ilmLoader.TemplateConfig(tmplCfg)
+			if err != nil {
+				return fmt.Errorf("Error applying ILM settings to template: %v", err)
+			}
+			tmplCfg = cfg
 		}
 
-		err = loader.Load()
-		if err != nil {
-			return fmt.Errorf("Error loading Elasticsearch template: %v", err)
+		if tmplCfg.Enabled() {
+			loader, err := template.NewLoader(tmplCfg, esClient, b.Info)
+			if err != nil {
+				return fmt.Errorf("Error creating Elasticsearch template loader: %v", err)
+			}
+
+			err = loader.Load()
+			if err != nil {
+				return fmt.Errorf("Error loading Elasticsearch template: %v", err)
+			}
+		}
+
+		if ilmLoader != nil {
+			if err := ilmLoader.BootstrapAlias(); err != nil {
+				return fmt.Errorf("Error bootstrapping rollover alias: %v", err)
+			}
 		}
 
 		return nil
@@ -679,6 +727,58 @@ func (b *Beat) templateLoadingCallback() (func(esClient *elasticsearch.Client) e
 	return callback, nil
 }
 
+// elasticsearchOutput returns the configuration of the Elasticsearch output.
+// If named outputs are configured, the first Elasticsearch output is returned.
+// It returns nil if no Elasticsearch output is configured.
+func (b *Beat) elasticsearchOutput() *common.Config {
+	if esOutputs := b.elasticsearchOutputs(); len(esOutputs) > 0 {
+		return esOutputs[0]
+	}
+	return nil
+}
+
+// elasticsearchOutputs returns the configurations of all Elasticsearch
+// outputs.
+func (b *Beat) elasticsearchOutputs() []*common.Config {
+	if b.Config.Output.Name() == "elasticsearch" {
+		return []*common.Config{b.Config.Output.Config()}
+	}
+
+	var esOutputs []*common.Config
+	for _, out := range b.Config.Pipeline.Outputs {
+		if out.Output.Name() == "elasticsearch" {
+			esOutputs = append(esOutputs, out.Output.Config())
+		}
+	}
+	return esOutputs
+}
+
+// ilmConfig reads the index lifecycle management settings of the
+// Elasticsearch output. The template and lifecycle policy are loaded by a
+// connect callback shared by all Elasticsearch outputs, so index lifecycle
+// management can not be enabled if multiple Elasticsearch outputs are
+// configured.
+func (b *Beat) ilmConfig() (template.ILMConfig, error) {
+	esOutputs := b.elasticsearchOutputs()
+	if len(esOutputs) > 1 {
+		for _, out := range esOutputs {
+			cfg, err := template.ReadILMConfig(out)
+			if err != nil {
+				return cfg, fmt.Errorf("unpacking ilm config fails: %v", err)
+			}
+			if cfg.Enabled {
+				return cfg, fmt.Errorf("index lifecycle management is only supported with a single Elasticsearch output, %v are configured", len(esOutputs))
+			}
+		}
+	}
+
+	cfg, err := template.ReadILMConfig(b.elasticsearchOutput())
+	if err != nil {
+		return cfg, fmt.Errorf("unpacking ilm config fails: %v", err)
+	}
+	return cfg, nil
+}
+
 // handleError handles the given error by logging it and then returning the
 // error. If the err is nil or is a GracefulExit error then the method will
 // return nil without logging anything.
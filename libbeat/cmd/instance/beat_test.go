@@ -7,6 +7,9 @@ import (
 
 	"github.com/satori/go.uuid"
 	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/publisher/pipeline"
 )
 
 func TestNewInstance(t *testing.T) {
@@ -42,3 +45,42 @@ func TestNewInstanceUUID(t *testing.T) {
 	// Make sure the UUID's are different
 	assert.NotEqual(t, b.Info.UUID, uuid.NewV4())
 }
+
+func TestILMConfigMultipleOutputs(t *testing.T) {
+	namedOutput := func(name string, settings map[string]interface{}) pipeline.OutputConfig {
+		var out common.ConfigNamespace
+		cfg, err := common.NewConfigFrom(map[string]interface{}{"elasticsearch": settings})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := cfg.Unpack(&out); err != nil {
+			t.Fatal(err)
+		}
+		return pipeline.OutputConfig{Name: name, Output: out}
+	}
+
+	b, err := NewBeat("testbeat", "", "0.9")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b.Config.Pipeline.Outputs = []pipeline.OutputConfig{
+		namedOutput("a", map[string]interface{}{"hosts": []string{"a:9200"}}),
+		namedOutput("b", map[string]interface{}{"hosts": []string{"b:9200"}}),
+	}
+	cfg, err := b.ilmConfig()
+	assert.NoError(t, err)
+	assert.False(t, cfg.Enabled)
+
+	b.Config.Pipeline.Outputs[1] = namedOutput("b", map[string]interface{}{
+		"hosts":       []string{"b:9200"},
+		"ilm.enabled": true,
+	})
+	_, err = b.ilmConfig()
+	assert.Error(t, err)
+
+	b.Config.Pipeline.Outputs = b.Config.Pipeline.Outputs[1:]
+	cfg, err = b.ilmConfig()
+	assert.NoError(t, err)
+	assert.True(t, cfg.Enabled)
+}
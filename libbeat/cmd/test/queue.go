@@ -0,0 +1,89 @@
+package test
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/spf13/cobra"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/cmd/instance"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/publisher/queue/spool"
+)
+
+func GenTestQueueCmd(name, beatVersion string) *cobra.Command {
+	var events int
+
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Inspect the contents of the " + name + " spool file while the beat is stopped",
+		Run: func(cmd *cobra.Command, args []string) {
+			b, err := instance.NewBeat(name, "", beatVersion)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing beat: %s\n", err)
+				os.Exit(1)
+			}
+
+			err = b.Init()
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error initializing beat: %s\n", err)
+				os.Exit(1)
+			}
+
+			queue := b.Config.Pipeline.Queue
+			if queue.Name() != "spool" {
+				fmt.Fprintf(os.Stderr, "The spool queue is not configured (queue type: '%v')\n", queue.Name())
+				os.Exit(1)
+			}
+
+			path, err := spool.FilePath(queue.Config())
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "Error reading spool settings: %s\n", err)
+				os.Exit(1)
+			}
+
+			if err := inspectSpool(path, events); err != nil {
+				fmt.Fprintf(os.Stderr, "Error inspecting spool file: %s\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().IntVar(&events, "events", 0, "Number of events to print, starting with the oldest event. Use -1 to print all events")
+	return cmd
+}
+
+func inspectSpool(path string, limit int) error {
+	info, err := spool.Inspect(path, limit, func(event beat.Event, err error) error {
+		if err != nil {
+			fmt.Printf("invalid event: %v\n", err)
+			return nil
+		}
+
+		doc := common.MapStr{"@timestamp": common.Time(event.Timestamp)}
+		if len(event.Meta) > 0 {
+			doc["@metadata"] = event.Meta
+		}
+		doc.DeepUpdate(event.Fields)
+
+		out, err := json.Marshal(doc)
+		if err != nil {
+			fmt.Printf("invalid event: %v\n", err)
+			return nil
+		}
+		fmt.Println(string(out))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("spool file: %v\n", info.Path)
+	fmt.Printf("  page size: %v\n", humanize.IBytes(uint64(info.PageSize)))
+	fmt.Printf("  file size: %v\n", humanize.IBytes(uint64(info.FileSize)))
+	fmt.Printf("  events: %v\n", info.Events)
+	return nil
+}
@@ -1,10 +1,13 @@
 package file
 
 import (
+	"compress/gzip"
+	"io"
 	"os"
 	"path/filepath"
 	"strconv"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 )
@@ -14,17 +17,28 @@ import (
 const MaxBackupsLimit = 1024
 
 // Rotator is a io.WriteCloser that automatically rotates the file it is
-// writing to when it reaches a maximum size. It also purges the oldest rotated
-// files when the maximum number of backups is reached.
+// writing to when it reaches a maximum size or when the configured time
+// interval has passed. It also purges the oldest rotated files when the
+// maximum number of backups is reached. Rotated files can optionally be
+// compressed with gzip, in the background.
 type Rotator struct {
 	filename     string
 	maxSizeBytes uint
 	maxBackups   uint
 	permissions  os.FileMode
-
-	file  *os.File
-	size  uint
-	mutex sync.Mutex
+	interval     time.Duration
+	compress     bool
+	append       bool
+	clock        func() time.Time
+
+	file     *os.File
+	size     uint
+	openTime time.Time
+	timer    *time.Timer
+	mutex    sync.Mutex
+
+	compressing sync.WaitGroup
+	compressErr error // set by the background compression, guarded by compressing
 }
 
 // RotatorOption is a configuration option for Rotator.
@@ -55,6 +69,35 @@ func Permissions(m os.FileMode) RotatorOption {
 	}
 }
 
+// Interval configures a time interval after which the file is rotated. The
+// intervals are aligned to UTC, e.g. an interval of 24h rotates the file at
+// midnight UTC, even if no more data is written. The default is 0, which
+// disables time based rotation.
+func Interval(d time.Duration) RotatorOption {
+	return func(r *Rotator) {
+		r.interval = d
+	}
+}
+
+// Compress configures the Rotator to gzip rotated files. Compressed backup
+// files get the .gz suffix. The file is compressed in the background, writes
+// to the new file are not blocked. The default is false.
+func Compress(enabled bool) RotatorOption {
+	return func(r *Rotator) {
+		r.compress = enabled
+	}
+}
+
+// Append configures the Rotator to append to an existing file when the file is
+// opened, instead of rotating it. The file is still rotated if it exceeds the
+// maximum size or was last modified in an earlier rotation interval. The
+// default is false.
+func Append(enabled bool) RotatorOption {
+	return func(r *Rotator) {
+		r.append = enabled
+	}
+}
+
 // NewFileRotator returns a new Rotator.
 func NewFileRotator(filename string, options ...RotatorOption) (*Rotator, error) {
 	r := &Rotator{
@@ -62,6 +105,7 @@ func NewFileRotator(filename string, options ...RotatorOption) (*Rotator, error)
 		maxSizeBytes: 10 * 1024 * 1024, // 10 MiB
 		maxBackups:   7,
 		permissions:  0600,
+		clock:        time.Now,
 	}
 
 	for _, opt := range options {
@@ -77,6 +121,9 @@ func NewFileRotator(filename string, options ...RotatorOption) (*Rotator, error)
 	if r.permissions > os.ModePerm {
 		return nil, errors.Errorf("file rotator permissions mask of %o is invalid", r.permissions)
 	}
+	if r.interval < 0 {
+		return nil, errors.Errorf("file rotator interval %v must not be negative", r.interval)
+	}
 
 	return r, nil
 }
@@ -98,7 +145,8 @@ func (r *Rotator) Write(data []byte) (int, error) {
 		if err := r.openNew(); err != nil {
 			return 0, err
 		}
-	} else if r.size+dataLen > r.maxSizeBytes {
+	}
+	if r.size+dataLen > r.maxSizeBytes || r.intervalPassed() {
 		if err := r.rotate(); err != nil {
 			return 0, err
 		}
@@ -131,18 +179,37 @@ func (r *Rotator) Rotate() error {
 	return r.rotate()
 }
 
-// Close closes the currently open file.
+// Close closes the currently open file. Close waits for the background
+// compression of the last rotated file to finish.
 func (r *Rotator) Close() error {
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
-	return r.closeFile()
+
+	err := r.closeFile()
+	if cerr := r.waitCompress(); err == nil {
+		err = cerr
+	}
+	return err
 }
 
 func (r *Rotator) backupName(n uint) string {
 	if n == 0 {
 		return r.filename
 	}
-	return r.filename + "." + strconv.Itoa(int(n))
+	name := r.filename + "." + strconv.Itoa(int(n))
+	if r.compress {
+		name += ".gz"
+	}
+	return name
+}
+
+// intervalPassed checks if the current file was opened in an earlier
+// rotation interval.
+func (r *Rotator) intervalPassed() bool {
+	if r.interval <= 0 {
+		return false
+	}
+	return !r.clock().Truncate(r.interval).Equal(r.openTime.Truncate(r.interval))
 }
 
 func (r *Rotator) dir() string {
@@ -166,8 +233,11 @@ func (r *Rotator) openNew() error {
 		return errors.Wrap(err, "failed to make directories for new file")
 	}
 
-	_, err = os.Stat(r.filename)
+	info, err := os.Stat(r.filename)
 	if err == nil {
+		if r.append {
+			return r.openExisting(info)
+		}
 		if err = r.rotate(); err != nil {
 			return err
 		}
@@ -176,6 +246,21 @@ func (r *Rotator) openNew() error {
 	return r.openFile()
 }
 
+// openExisting opens the existing file for appending. The modification time
+// of the file is used as the open time, so the file is rotated on the next
+// write if it was last written in an earlier rotation interval.
+func (r *Rotator) openExisting(info os.FileInfo) error {
+	var err error
+	r.file, err = os.OpenFile(r.filename, os.O_WRONLY|os.O_APPEND, r.permissions)
+	if err != nil {
+		return errors.Wrap(err, "failed to open existing file")
+	}
+	r.size = uint(info.Size())
+	r.openTime = info.ModTime()
+	r.startTimer()
+	return nil
+}
+
 func (r *Rotator) openFile() error {
 	err := os.MkdirAll(r.dir(), r.dirMode())
 	if err != nil {
@@ -186,11 +271,41 @@ func (r *Rotator) openFile() error {
 	if err != nil {
 		return errors.Wrap(err, "failed to open new file")
 	}
-
+	r.openTime = r.clock()
+	r.startTimer()
 	return nil
 }
 
+// startTimer schedules the rotation at the end of the current rotation
+// interval.
+func (r *Rotator) startTimer() {
+	if r.interval > 0 {
+		next := r.openTime.Truncate(r.interval).Add(r.interval)
+		r.timer = time.AfterFunc(next.Sub(r.clock()), r.rotateOnInterval)
+	}
+}
+
+// rotateOnInterval rotates the file at the end of the rotation interval.
+// The new file is only created on the next write.
+func (r *Rotator) rotateOnInterval() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil || !r.intervalPassed() {
+		return
+	}
+
+	// Errors are reported by the next write, which retries the rotation as the
+	// file still exists.
+	r.rotate()
+}
+
 func (r *Rotator) closeFile() error {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+
 	if r.file == nil {
 		return nil
 	}
@@ -225,6 +340,18 @@ func (r *Rotator) rotate() error {
 		return errors.Wrap(err, "error file closing current file")
 	}
 
+	// The last rotated file must be compressed before the backups are shifted.
+	// A file left over by a failed compression or a crash is compressed now.
+	if r.compress {
+		r.waitCompress()
+		pending := r.filename + ".1"
+		if _, err := os.Stat(pending); err == nil {
+			if err := r.compressFile(pending, r.backupName(1)); err != nil {
+				return errors.Wrap(err, "failed to compress rotated file")
+			}
+		}
+	}
+
 	for i := r.maxBackups + 1; i > 0; i-- {
 		old := r.backupName(i - 1)
 		older := r.backupName(i)
@@ -238,6 +365,17 @@ func (r *Rotator) rotate() error {
 		if err := os.Remove(older); err != nil && !os.IsNotExist(err) {
 			return errors.Wrap(err, "failed to rotate backups")
 		}
+
+		// The active file is the only uncompressed one, all backups already
+		// carry the .gz suffix.
+		if i == 1 && r.compress {
+			pending := r.filename + ".1"
+			if err := os.Rename(old, pending); err != nil {
+				return errors.Wrap(err, "failed to rotate backups")
+			}
+			r.compressInBackground(pending, older)
+			continue
+		}
 		if err := os.Rename(old, older); err != nil {
 			return errors.Wrap(err, "failed to rotate backups")
 		}
@@ -245,3 +383,72 @@ func (r *Rotator) rotate() error {
 
 	return r.purgeOldBackups()
 }
+
+// compressInBackground compresses src to dst without blocking writes to the
+// new file. The error is returned by the next call to waitCompress.
+func (r *Rotator) compressInBackground(src, dst string) {
+	r.compressing.Add(1)
+	go func() {
+		defer r.compressing.Done()
+		if err := r.compressFile(src, dst); err != nil {
+			r.compressErr = errors.Wrap(err, "failed to compress rotated file")
+		}
+	}()
+}
+
+// waitCompress waits for the background compression to finish and returns
+// its error.
+func (r *Rotator) waitCompress() error {
+	r.compressing.Wait()
+	err := r.compressErr
+	r.compressErr = nil
+	return err
+}
+
+// compressFile writes a gzip compressed copy of src to dst and removes src.
+// The compressed data is written to a temporary file first, such that dst is
+// never left incomplete.
+func (r *Rotator) compressFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, r.permissions)
+	if err != nil {
+		return err
+	}
+
+	zw := gzip.NewWriter(out)
+	zw.Name = filepath.Base(r.filename)
+	zw.ModTime = info.ModTime()
+	_, err = io.Copy(zw, in)
+	if err == nil {
+		err = zw.Close()
+	}
+	if err == nil {
+		err = out.Sync()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := os.Rename(tmp, dst); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	in.Close()
+	return os.Remove(src)
+}
@@ -1,12 +1,14 @@
 package file
 
 import (
+	"compress/gzip"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"sort"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -74,6 +76,171 @@ func TestFileRotatorConcurrently(t *testing.T) {
 	wg.Wait()
 }
 
+func TestFileRotatorInterval(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_rotator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	now := time.Date(2018, 7, 1, 10, 30, 0, 0, time.UTC)
+	filename := filepath.Join(dir, "sample.log")
+	r, err := NewFileRotator(filename, MaxBackups(2), Interval(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.clock = func() time.Time { return now }
+	defer r.Close()
+
+	WriteMsg(t, r)
+	AssertDirContents(t, dir, "sample.log")
+
+	now = now.Add(20 * time.Minute)
+	WriteMsg(t, r)
+	AssertDirContents(t, dir, "sample.log")
+
+	now = now.Add(20 * time.Minute)
+	WriteMsg(t, r)
+	AssertDirContents(t, dir, "sample.log", "sample.log.1")
+
+	now = now.Add(3 * time.Hour)
+	WriteMsg(t, r)
+	AssertDirContents(t, dir, "sample.log", "sample.log.1", "sample.log.2")
+}
+
+func TestFileRotatorCompress(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_rotator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "sample.log")
+	r, err := NewFileRotator(filename, MaxBackups(2), Compress(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	WriteMsg(t, r)
+	Rotate(t, r)
+	if err := r.waitCompress(); err != nil {
+		t.Fatal(err)
+	}
+	AssertDirContents(t, dir, "sample.log.1.gz")
+
+	WriteMsg(t, r)
+	Rotate(t, r)
+	WriteMsg(t, r)
+	Rotate(t, r)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	AssertDirContents(t, dir, "sample.log.1.gz", "sample.log.2.gz")
+
+	f, err := os.Open(filepath.Join(dir, "sample.log.2.gz"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, logMessage, string(content))
+	assert.Equal(t, "sample.log", zr.Name)
+}
+
+func TestFileRotatorCompressLeftover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_rotator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	// uncompressed file left over by a crash during compression
+	filename := filepath.Join(dir, "sample.log")
+	if err := ioutil.WriteFile(filename+".1", []byte(logMessage), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := NewFileRotator(filename, MaxBackups(2), Compress(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	WriteMsg(t, r)
+	Rotate(t, r)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+	AssertDirContents(t, dir, "sample.log.1.gz", "sample.log.2.gz")
+}
+
+func TestFileRotatorIntervalTimer(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_rotator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "sample.log")
+	r, err := NewFileRotator(filename, MaxBackups(2), Interval(100*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	WriteMsg(t, r)
+	for start := time.Now(); time.Since(start) < 5*time.Second; {
+		if _, err := os.Stat(filename + ".1"); err == nil {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	AssertDirContents(t, dir, "sample.log.1")
+}
+
+func TestFileRotatorAppend(t *testing.T) {
+	dir, err := ioutil.TempDir("", "file_rotator")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "sample.log")
+	for i := 0; i < 3; i++ {
+		r, err := NewFileRotator(filename, MaxBackups(2), MaxSizeBytes(uint(len(logMessage)*3)), Append(true))
+		if err != nil {
+			t.Fatal(err)
+		}
+		WriteMsg(t, r)
+		r.Close()
+	}
+	AssertDirContents(t, dir, "sample.log")
+
+	content, err := ioutil.ReadFile(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, logMessage+logMessage+logMessage, string(content))
+
+	// the existing file is rotated once it reaches the maximum size
+	r, err := NewFileRotator(filename, MaxBackups(2), MaxSizeBytes(uint(len(logMessage)*3)), Append(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	WriteMsg(t, r)
+	AssertDirContents(t, dir, "sample.log", "sample.log.1")
+}
+
 func AssertDirContents(t *testing.T, dir string, files ...string) {
 	t.Helper()
 
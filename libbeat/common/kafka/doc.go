@@ -0,0 +1,3 @@
+// Package kafka contains the settings shared by the Kafka output and the
+// Kafka input.
+package kafka
@@ -0,0 +1,70 @@
+package kafka
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Shopify/sarama"
+)
+
+// SaslConfig selects the SASL mechanism used to authenticate with the
+// configured username and password.
+type SaslConfig struct {
+	Mechanism string `config:"mechanism"`
+}
+
+var saslMechanisms = map[string]sarama.SASLMechanism{
+	sarama.SASLTypePlaintext:   sarama.SASLTypePlaintext,
+	sarama.SASLTypeSCRAMSHA256: sarama.SASLTypeSCRAMSHA256,
+	sarama.SASLTypeSCRAMSHA512: sarama.SASLTypeSCRAMSHA512,
+}
+
+// ValidateAuth checks the username, password and SASL settings are complete
+// and the SASL mechanism is supported.
+func ValidateAuth(username, password string, sasl SaslConfig) error {
+	if username != "" && password == "" {
+		return fmt.Errorf("password must be set when username is configured")
+	}
+
+	if sasl.Mechanism != "" {
+		if _, ok := saslMechanisms[strings.ToUpper(sasl.Mechanism)]; !ok {
+			return fmt.Errorf("sasl mechanism '%v' unknown", sasl.Mechanism)
+		}
+		if username == "" {
+			return errors.New("username must be set when sasl.mechanism is configured")
+		}
+	}
+	return nil
+}
+
+// ConfigureAuth enables SASL authentication in k if a username is configured.
+// The PLAIN mechanism is used by default.
+func ConfigureAuth(k *sarama.Config, username, password string, sasl SaslConfig) {
+	if username == "" {
+		return
+	}
+
+	k.Net.SASL.Enable = true
+	k.Net.SASL.User = username
+	k.Net.SASL.Password = password
+
+	mechanism := sarama.SASLMechanism(sarama.SASLTypePlaintext)
+	if sasl.Mechanism != "" {
+		mechanism = saslMechanisms[strings.ToUpper(sasl.Mechanism)]
+	}
+	k.Net.SASL.Mechanism = mechanism
+
+	switch mechanism {
+	case sarama.SASLTypeSCRAMSHA256:
+		k.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{HashGeneratorFcn: sha256.New}
+		}
+	case sarama.SASLTypeSCRAMSHA512:
+		k.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{HashGeneratorFcn: sha512.New}
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigureAuth(t *testing.T) {
+	tests := map[string]struct {
+		username  string
+		mechanism string
+		enabled   bool
+		expected  sarama.SASLMechanism
+		scram     bool
+	}{
+		"no username":     {},
+		"plain default":   {username: "beats", enabled: true, expected: sarama.SASLTypePlaintext},
+		"scram-sha-256":   {username: "beats", mechanism: "scram-sha-256", enabled: true, expected: sarama.SASLTypeSCRAMSHA256, scram: true},
+		"SCRAM-SHA-512":   {username: "beats", mechanism: "SCRAM-SHA-512", enabled: true, expected: sarama.SASLTypeSCRAMSHA512, scram: true},
+		"plain mechanism": {username: "beats", mechanism: "plain", enabled: true, expected: sarama.SASLTypePlaintext},
+	}
+
+	for name, test := range tests {
+		k := sarama.NewConfig()
+		ConfigureAuth(k, test.username, "secret", SaslConfig{Mechanism: test.mechanism})
+
+		assert.Equal(t, test.enabled, k.Net.SASL.Enable, name)
+		if !test.enabled {
+			continue
+		}
+		assert.Equal(t, test.expected, k.Net.SASL.Mechanism, name)
+		assert.Equal(t, test.scram, k.Net.SASL.SCRAMClientGeneratorFunc != nil, name)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	v, ok := Version("")
+	assert.True(t, ok)
+	assert.Equal(t, sarama.V0_8_2_0, v)
+
+	v, ok = Version("2.1")
+	assert.True(t, ok)
+	assert.Equal(t, sarama.V2_1_0_0, v)
+
+	_, ok = Version("3.0.0")
+	assert.False(t, ok)
+}
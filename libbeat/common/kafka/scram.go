@@ -0,0 +1,29 @@
+package kafka
+
+import (
+	"github.com/xdg/scram"
+)
+
+// scramClient implements the sarama.SCRAMClient interface for the
+// SCRAM-SHA-256 and SCRAM-SHA-512 SASL mechanisms.
+type scramClient struct {
+	*scram.ClientConversation
+	scram.HashGeneratorFcn
+}
+
+func (c *scramClient) Begin(userName, password, authzID string) error {
+	client, err := c.HashGeneratorFcn.NewClient(userName, password, authzID)
+	if err != nil {
+		return err
+	}
+	c.ClientConversation = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.ClientConversation.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.ClientConversation.Done()
+}
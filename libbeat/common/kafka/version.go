@@ -0,0 +1,55 @@
+package kafka
+
+import "github.com/Shopify/sarama"
+
+var kafkaVersions = map[string]sarama.KafkaVersion{
+	"": sarama.V0_8_2_0,
+
+	"0.8.2.0": sarama.V0_8_2_0,
+	"0.8.2.1": sarama.V0_8_2_1,
+	"0.8.2.2": sarama.V0_8_2_2,
+	"0.8.2":   sarama.V0_8_2_2,
+	"0.8":     sarama.V0_8_2_2,
+
+	"0.9.0.0": sarama.V0_9_0_0,
+	"0.9.0.1": sarama.V0_9_0_1,
+	"0.9.0":   sarama.V0_9_0_1,
+	"0.9":     sarama.V0_9_0_1,
+
+	"0.10.0.0": sarama.V0_10_0_0,
+	"0.10.0.1": sarama.V0_10_0_1,
+	"0.10.0":   sarama.V0_10_0_1,
+	"0.10.1.0": sarama.V0_10_1_0,
+	"0.10.1":   sarama.V0_10_1_0,
+	"0.10.2.0": sarama.V0_10_2_0,
+	"0.10.2.1": sarama.V0_10_2_1,
+	"0.10.2":   sarama.V0_10_2_1,
+	"0.10":     sarama.V0_10_2_1,
+
+	"0.11.0.0": sarama.V0_11_0_0,
+	"0.11.0.1": sarama.V0_11_0_1,
+	"0.11.0.2": sarama.V0_11_0_2,
+	"0.11.0":   sarama.V0_11_0_2,
+	"0.11":     sarama.V0_11_0_2,
+
+	"1.0.0": sarama.V1_0_0_0,
+	"1.0":   sarama.V1_0_0_0,
+	"1.1.0": sarama.V1_1_0_0,
+	"1.1.1": sarama.V1_1_1_0,
+	"1.1":   sarama.V1_1_1_0,
+
+	"2.0.0": sarama.V2_0_0_0,
+	"2.0.1": sarama.V2_0_1_0,
+	"2.0":   sarama.V2_0_1_0,
+	"2.1.0": sarama.V2_1_0_0,
+	"2.1":   sarama.V2_1_0_0,
+	"2.2.0": sarama.V2_2_0_0,
+	"2.2":   sarama.V2_2_0_0,
+}
+
+// Version returns the sarama Kafka version for a configured version string.
+// The empty string selects the oldest supported version.
+func Version(version string) (sarama.KafkaVersion, bool) {
+	v, ok := kafkaVersions[version]
+	return v, ok
+}
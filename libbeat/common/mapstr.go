@@ -16,6 +16,7 @@ import (
 const (
 	FieldsKey = "fields"
 	TagsKey   = "tags"
+	LabelsKey = "labels"
 )
 
 var (
@@ -48,32 +49,48 @@ func (m MapStr) Update(d MapStr) {
 // If the key is present and a map as well, the sub-map will be updated recursively
 // via DeepUpdate.
 func (m MapStr) DeepUpdate(d MapStr) {
+	m.deepUpdateMap(d, true)
+}
+
+// DeepUpdateNoOverwrite recursively copies the key-value pairs from d to this
+// map. If a key is already present it will not be overwritten. Sub-maps
+// present in both maps are merged recursively.
+func (m MapStr) DeepUpdateNoOverwrite(d MapStr) {
+	m.deepUpdateMap(d, false)
+}
+
+func (m MapStr) deepUpdateMap(d MapStr, overwrite bool) {
 	for k, v := range d {
 		switch val := v.(type) {
 		case map[string]interface{}:
-			m[k] = deepUpdateValue(m[k], MapStr(val))
+			m[k] = deepUpdateValue(m[k], MapStr(val), overwrite)
 		case MapStr:
-			m[k] = deepUpdateValue(m[k], val)
+			m[k] = deepUpdateValue(m[k], val, overwrite)
 		default:
-			m[k] = v
+			if _, exists := m[k]; overwrite || !exists {
+				m[k] = v
+			}
 		}
 	}
 }
 
-func deepUpdateValue(old interface{}, val MapStr) interface{} {
+func deepUpdateValue(old interface{}, val MapStr, overwrite bool) interface{} {
 	if old == nil {
 		return val
 	}
 
 	switch sub := old.(type) {
 	case MapStr:
-		sub.DeepUpdate(val)
+		sub.deepUpdateMap(val, overwrite)
 		return sub
 	case map[string]interface{}:
 		tmp := MapStr(sub)
-		tmp.DeepUpdate(val)
+		tmp.deepUpdateMap(val, overwrite)
 		return tmp
 	default:
+		if !overwrite {
+			return old
+		}
 		return val
 	}
 }
@@ -288,27 +305,39 @@ func MergeFields(ms, fields MapStr, underRoot bool) error {
 // exist then it will be created. If the tags field exists and is not a []string
 // then an error will be returned. It does not deduplicate the list of tags.
 func AddTags(ms MapStr, tags []string) error {
+	return AddTagsWithKey(ms, TagsKey, tags)
+}
+
+// AddTagsWithKey appends a tag to the key field of ms. The key can be a
+// dotted path. If the field does not exist then it will be created. If the
+// field exists and is not a []string then an error will be returned. It does
+// not deduplicate the list of tags.
+func AddTagsWithKey(ms MapStr, key string, tags []string) error {
 	if ms == nil || len(tags) == 0 {
 		return nil
 	}
-	eventTags, exists := ms[TagsKey]
-	if !exists {
-		ms[TagsKey] = tags
-		return nil
+
+	eventTags, err := ms.GetValue(key)
+	if err != nil {
+		if err != ErrKeyNotFound {
+			return err
+		}
+		_, err = ms.Put(key, tags)
+		return err
 	}
 
 	switch arr := eventTags.(type) {
 	case []string:
-		ms[TagsKey] = append(arr, tags...)
+		_, err = ms.Put(key, append(arr, tags...))
 	case []interface{}:
 		for _, tag := range tags {
 			arr = append(arr, tag)
 		}
-		ms[TagsKey] = arr
+		_, err = ms.Put(key, arr)
 	default:
-		return errors.Errorf("expected string array by type is %T", eventTags)
+		err = errors.Errorf("expected string array by type is %T", eventTags)
 	}
-	return nil
+	return err
 }
 
 // toMapStr performs a type assertion on v and returns a MapStr. v can be either
@@ -83,6 +83,48 @@ func TestMapStrDeepUpdate(t *testing.T) {
 	}
 }
 
+func TestMapStrDeepUpdateNoOverwrite(t *testing.T) {
+	tests := []struct {
+		a, b, expected MapStr
+	}{
+		{
+			MapStr{"a": 1},
+			MapStr{"b": 2},
+			MapStr{"a": 1, "b": 2},
+		},
+		{
+			MapStr{"a": 1},
+			MapStr{"a": 2},
+			MapStr{"a": 1},
+		},
+		{
+			MapStr{"a": 1},
+			MapStr{"a": MapStr{"b": 1}},
+			MapStr{"a": 1},
+		},
+		{
+			MapStr{"a": MapStr{"b": 1}},
+			MapStr{"a": MapStr{"b": 2, "c": 2}},
+			MapStr{"a": MapStr{"b": 1, "c": 2}},
+		},
+		{
+			MapStr{"a": MapStr{"b": 1}},
+			MapStr{"a": 1},
+			MapStr{"a": MapStr{"b": 1}},
+		},
+	}
+
+	for i, test := range tests {
+		a, b, expected := test.a, test.b, test.expected
+		name := fmt.Sprintf("%v: %v + %v = %v", i, a, b, expected)
+
+		t.Run(name, func(t *testing.T) {
+			a.DeepUpdateNoOverwrite(b)
+			assert.Equal(t, expected, a)
+		})
+	}
+}
+
 func TestMapStrUnion(t *testing.T) {
 	assert := assert.New(t)
 
@@ -529,6 +571,60 @@ func TestAddTag(t *testing.T) {
 	}
 }
 
+func TestAddTagsWithKey(t *testing.T) {
+	type io struct {
+		Event  MapStr
+		Key    string
+		Tags   []string
+		Output MapStr
+		Err    string
+	}
+	tests := []io{
+		// No existing field, creates new tag array
+		{
+			Event: MapStr{},
+			Key:   "labels.tags",
+			Tags:  []string{"json"},
+			Output: MapStr{
+				"labels": MapStr{"tags": []string{"json"}},
+			},
+		},
+		// Existing field is a []string, appends
+		{
+			Event: MapStr{
+				"labels": MapStr{"tags": []string{"json"}},
+			},
+			Key:  "labels.tags",
+			Tags: []string{"docker"},
+			Output: MapStr{
+				"labels": MapStr{"tags": []string{"json", "docker"}},
+			},
+		},
+		// Existing field is not a []string or []interface{}
+		{
+			Event: MapStr{
+				"labels": MapStr{"tags": "not a slice"},
+			},
+			Key:  "labels.tags",
+			Tags: []string{"docker"},
+			Output: MapStr{
+				"labels": MapStr{"tags": "not a slice"},
+			},
+			Err: "expected string array",
+		},
+	}
+
+	for _, test := range tests {
+		err := AddTagsWithKey(test.Event, test.Key, test.Tags)
+		assert.Equal(t, test.Output, test.Event)
+		if test.Err != "" {
+			assert.Contains(t, err.Error(), test.Err)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
 func TestFlatten(t *testing.T) {
 	type data struct {
 		Event    MapStr
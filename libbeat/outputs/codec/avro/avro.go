@@ -0,0 +1,141 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+	"github.com/elastic/beats/libbeat/paths"
+)
+
+// Encoder for serializing a beat.Event to Avro. The schema is derived from
+// the field definitions in fields.yml.
+type Encoder struct {
+	enc      encoder
+	root     *node
+	schema   []byte
+	schemaID int32
+	hasID    bool
+	version  string
+}
+
+type config struct {
+	Fields   string         `config:"fields"`
+	Registry registryConfig `config:"registry"`
+}
+
+var defaultConfig = config{
+	Fields: "fields.yml",
+	Registry: registryConfig{
+		Timeout: 30 * time.Second,
+	},
+}
+
+func init() {
+	codec.RegisterType("avro", func(info beat.Info, cfg *common.Config) (codec.Codec, error) {
+		config := defaultConfig
+		if cfg != nil {
+			if err := cfg.Unpack(&config); err != nil {
+				return nil, err
+			}
+		}
+
+		path := paths.Resolve(paths.Config, config.Fields)
+		fields, err := common.LoadFieldsYaml(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fields from %v: %v", path, err)
+		}
+
+		enc, err := New(info, fields)
+		if err != nil {
+			return nil, err
+		}
+
+		if config.Registry.URL != "" {
+			subject := config.Registry.Subject
+			if subject == "" {
+				subject = info.Beat + "-value"
+			}
+
+			registry, err := newRegistry(config.Registry)
+			if err != nil {
+				return nil, err
+			}
+			id, err := registry.register(subject, enc.Schema())
+			if err != nil {
+				return nil, err
+			}
+
+			logp.Info("Registered avro schema for subject '%v' with id %v", subject, id)
+			enc.SetSchemaID(id)
+		}
+
+		return enc, nil
+	})
+}
+
+// New creates a new Avro Encoder for the given field definitions.
+func New(info beat.Info, fields common.Fields) (*Encoder, error) {
+	root := buildTree(fields)
+	schema, err := json.Marshal(makeSchema(info.Beat, root))
+	if err != nil {
+		return nil, err
+	}
+
+	logp.Debug("avro", "Avro schema: %s", schema)
+	return &Encoder{
+		root:    root,
+		schema:  schema,
+		version: info.Version,
+	}, nil
+}
+
+// Schema returns the JSON representation of the Avro schema.
+func (e *Encoder) Schema() []byte {
+	return e.schema
+}
+
+// SetSchemaID configures the encoder to prefix all events with the schema
+// registry wire format header: a zero magic byte followed by the 4 byte
+// schema ID.
+func (e *Encoder) SetSchemaID(id int32) {
+	e.schemaID = id
+	e.hasID = true
+}
+
+// Encode serializes a beat event to Avro. The @metadata fields and the beat
+// name and version are stored in the metadata map.
+func (e *Encoder) Encode(index string, event *beat.Event) ([]byte, error) {
+	enc := &e.enc
+	enc.reset()
+	enc.unmapped = nil
+
+	if e.hasID {
+		var header [5]byte
+		binary.BigEndian.PutUint32(header[1:], uint32(e.schemaID))
+		enc.buf = append(enc.buf, header[:]...)
+	}
+
+	ts := event.Timestamp
+	enc.long(ts.Unix()*1000 + int64(ts.Nanosecond())/int64(time.Millisecond))
+
+	meta := map[string]string{
+		"beat":    index,
+		"type":    "doc",
+		"version": e.version,
+	}
+	for k, v := range event.Meta {
+		meta[k] = toJSONString(v)
+	}
+	enc.stringMap(meta)
+
+	enc.record(e.root, event.Fields, "")
+	enc.stringMap(enc.unmapped)
+
+	return enc.buf, nil
+}
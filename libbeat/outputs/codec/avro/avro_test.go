@@ -0,0 +1,363 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+)
+
+const testFields = `
+- key: beat
+  title: Beat
+  fields:
+    - name: beat.name
+      type: keyword
+    - name: "@timestamp"
+      type: date
+    - name: tags
+      type: keyword
+- key: system
+  title: System
+  fields:
+    - name: system
+      type: group
+      fields:
+        - name: load
+          type: group
+          fields:
+            - name: "1"
+              type: scaled_float
+            - name: cores
+              type: long
+        - name: process.running
+          type: boolean
+        - name: labels
+          type: object
+        - name: location
+          type: geo_point
+`
+
+func TestSchema(t *testing.T) {
+	enc := newTestEncoder(t)
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(enc.Schema(), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "record", schema["type"])
+	assert.Equal(t, "testbeat", schema["name"])
+	assert.Equal(t, "co.elastic.beats", schema["namespace"])
+
+	var names []string
+	for _, f := range schema["fields"].([]interface{}) {
+		names = append(names, f.(map[string]interface{})["name"].(string))
+	}
+	assert.Equal(t, []string{"timestamp", "metadata", "beat", "tags", "system", "_unmapped"}, names)
+
+	system := findField(schema, "system")
+	assert.Equal(t, "testbeat.system", system["type"].([]interface{})[1].(map[string]interface{})["name"])
+
+	load := findField(system["type"].([]interface{})[1].(map[string]interface{}), "load")
+	loadRecord := load["type"].([]interface{})[1].(map[string]interface{})
+	assert.NotNil(t, findField(loadRecord, "_1"))
+	assert.NotNil(t, findField(loadRecord, "cores"))
+}
+
+func TestEncode(t *testing.T) {
+	enc := newTestEncoder(t)
+
+	ts := time.Date(2018, 7, 1, 10, 30, 0, 123000000, time.UTC)
+	out, err := enc.Encode("testbeat", &beat.Event{
+		Timestamp: ts,
+		Meta:      common.MapStr{"pipeline": "p1"},
+		Fields: common.MapStr{
+			"beat": common.MapStr{"name": "host1"},
+			"tags": []string{"a", "b"},
+			"system": common.MapStr{
+				"load": common.MapStr{
+					"1":     common.Float(1.5),
+					"cores": "not a number",
+				},
+				"process":  common.MapStr{"running": true},
+				"labels":   common.MapStr{"env": "prod", "tier": 2},
+				"location": common.MapStr{"lat": 1.5, "lon": 2},
+			},
+			"unknown": 42,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded := decodeWithSchema(t, enc.Schema(), out)
+
+	assert.Equal(t, map[string]interface{}{
+		"timestamp": ts.UnixNano() / int64(time.Millisecond),
+		"metadata": map[string]interface{}{
+			"beat":     "testbeat",
+			"type":     "doc",
+			"version":  "1.2.3",
+			"pipeline": "p1",
+		},
+		"beat": map[string]interface{}{"name": "host1"},
+		"tags": []interface{}{"a", "b"},
+		"system": map[string]interface{}{
+			"load": map[string]interface{}{
+				"_1":    1.5,
+				"cores": nil,
+			},
+			"process":  map[string]interface{}{"running": true},
+			"labels":   map[string]interface{}{"env": "prod", "tier": "2"},
+			"location": `{"lat":1.5,"lon":2}`,
+		},
+		"_unmapped": map[string]interface{}{
+			"system.load.cores": "not a number",
+			"unknown":           "42",
+		},
+	}, decoded)
+}
+
+func TestRegistry(t *testing.T) {
+	var subject, contentType string
+	var registered map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		subject = r.URL.Path
+		contentType = r.Header.Get("Content-Type")
+		if err := json.NewDecoder(r.Body).Decode(&registered); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", registryContentType)
+		w.Write([]byte(`{"id":42}`))
+	}))
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fieldsPath := filepath.Join(dir, "fields.yml")
+	if err := ioutil.WriteFile(fieldsPath, []byte(testFields), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := common.NewConfigFrom(map[string]interface{}{
+		"avro.fields":       fieldsPath,
+		"avro.registry.url": server.URL,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var config codec.Config
+	if err := cfg.Unpack(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	c, err := codec.CreateEncoder(beat.Info{Beat: "testbeat", Version: "1.2.3"}, config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "/subjects/testbeat-value/versions", subject)
+	assert.Equal(t, registryContentType, contentType)
+	assert.Equal(t, string(c.(*Encoder).Schema()), registered["schema"])
+
+	out, err := c.Encode("testbeat", &beat.Event{Timestamp: time.Now()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []byte{0, 0, 0, 0, 42}, out[:5])
+	decodeWithSchema(t, c.(*Encoder).Schema(), out[5:])
+}
+
+func TestRegistryError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, `{"error_code":409,"message":"incompatible schema"}`, http.StatusConflict)
+	}))
+	defer server.Close()
+
+	r, err := newRegistry(registryConfig{URL: server.URL, Timeout: time.Second})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = r.register("test", []byte(`"string"`))
+	assert.Error(t, err)
+}
+
+func TestAvroName(t *testing.T) {
+	assert.Equal(t, "_1", avroName("1"))
+	assert.Equal(t, "_5m", avroName("5m"))
+	assert.Equal(t, "_timestamp", avroName("@timestamp"))
+	assert.Equal(t, "a_b", avroName("a-b"))
+	assert.Equal(t, "_", avroName(""))
+}
+
+func newTestEncoder(t *testing.T) *Encoder {
+	dir, err := ioutil.TempDir("", "avro")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "fields.yml")
+	if err := ioutil.WriteFile(path, []byte(testFields), 0600); err != nil {
+		t.Fatal(err)
+	}
+	fields, err := common.LoadFieldsYaml(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	enc, err := New(beat.Info{Beat: "testbeat", Version: "1.2.3"}, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enc
+}
+
+func findField(record map[string]interface{}, name string) map[string]interface{} {
+	for _, f := range record["fields"].([]interface{}) {
+		if field := f.(map[string]interface{}); field["name"] == name {
+			return field
+		}
+	}
+	return nil
+}
+
+// decodeWithSchema decodes Avro binary data using the JSON schema. Only the
+// types used by the encoder are supported. Optional fields are decoded as
+// their value or nil.
+func decodeWithSchema(t *testing.T, schema, data []byte) interface{} {
+	var s interface{}
+	if err := json.Unmarshal(schema, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	d := &decoder{data: data}
+	v, err := d.decode(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d.data) != 0 {
+		t.Fatalf("%v bytes left after decoding", len(d.data))
+	}
+	return v
+}
+
+type decoder struct {
+	data []byte
+}
+
+func (d *decoder) long() (int64, error) {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		return 0, fmt.Errorf("invalid varint")
+	}
+	d.data = d.data[n:]
+	return v, nil
+}
+
+func (d *decoder) decode(schema interface{}) (interface{}, error) {
+	switch s := schema.(type) {
+	case string:
+		switch s {
+		case "null":
+			return nil, nil
+		case "boolean":
+			b := d.data[0]
+			d.data = d.data[1:]
+			return b == 1, nil
+		case "long":
+			return d.long()
+		case "double":
+			f := math.Float64frombits(binary.LittleEndian.Uint64(d.data))
+			d.data = d.data[8:]
+			return f, nil
+		case "string":
+			l, err := d.long()
+			if err != nil {
+				return nil, err
+			}
+			str := string(d.data[:l])
+			d.data = d.data[l:]
+			return str, nil
+		}
+		return nil, fmt.Errorf("unsupported type %v", s)
+
+	case []interface{}:
+		idx, err := d.long()
+		if err != nil {
+			return nil, err
+		}
+		return d.decode(s[idx])
+
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record":
+			out := map[string]interface{}{}
+			for _, f := range s["fields"].([]interface{}) {
+				field := f.(map[string]interface{})
+				v, err := d.decode(field["type"])
+				if err != nil {
+					return nil, err
+				}
+				out[field["name"].(string)] = v
+			}
+			return out, nil
+		case "array":
+			out := []interface{}{}
+			for {
+				n, err := d.long()
+				if err != nil || n == 0 {
+					return out, err
+				}
+				for i := int64(0); i < n; i++ {
+					v, err := d.decode(s["items"])
+					if err != nil {
+						return nil, err
+					}
+					out = append(out, v)
+				}
+			}
+		case "map":
+			out := map[string]interface{}{}
+			for {
+				n, err := d.long()
+				if err != nil || n == 0 {
+					return out, err
+				}
+				for i := int64(0); i < n; i++ {
+					k, err := d.decode("string")
+					if err != nil {
+						return nil, err
+					}
+					v, err := d.decode(s["values"])
+					if err != nil {
+						return nil, err
+					}
+					out[k.(string)] = v
+				}
+			}
+		default:
+			return d.decode(s["type"])
+		}
+	}
+	return nil, fmt.Errorf("unsupported schema %v", schema)
+}
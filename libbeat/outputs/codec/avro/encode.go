@@ -0,0 +1,288 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// writer implements the Avro binary encoding.
+type writer struct {
+	buf     []byte
+	scratch [binary.MaxVarintLen64]byte
+}
+
+func (w *writer) reset() {
+	w.buf = w.buf[:0]
+}
+
+func (w *writer) long(v int64) {
+	n := binary.PutVarint(w.scratch[:], v) // zig-zag encoding as required by Avro
+	w.buf = append(w.buf, w.scratch[:n]...)
+}
+
+func (w *writer) double(f float64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], math.Float64bits(f))
+	w.buf = append(w.buf, b[:]...)
+}
+
+func (w *writer) boolean(b bool) {
+	if b {
+		w.buf = append(w.buf, 1)
+	} else {
+		w.buf = append(w.buf, 0)
+	}
+}
+
+func (w *writer) str(s string) {
+	w.long(int64(len(s)))
+	w.buf = append(w.buf, s...)
+}
+
+// stringMap writes a map of strings as a single block. Keys are written in
+// sorted order, such that equal events result in equal output.
+func (w *writer) stringMap(m map[string]string) {
+	if len(m) > 0 {
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		w.long(int64(len(keys)))
+		for _, k := range keys {
+			w.str(k)
+			w.str(m[k])
+		}
+	}
+	w.long(0)
+}
+
+// encoder writes event fields according to the schema tree. Values not
+// matching the schema are collected in unmapped.
+type encoder struct {
+	writer
+	unmapped map[string]string
+}
+
+func (e *encoder) record(n *node, fields map[string]interface{}, prefix string) {
+	for _, child := range n.children {
+		v, exists := fields[child.key]
+		if !exists || v == nil {
+			e.long(branchNull)
+			continue
+		}
+
+		if !e.field(child, v, prefix+child.key) {
+			e.long(branchNull)
+			e.unmap(prefix+child.key, v)
+		}
+	}
+
+	for key, v := range fields {
+		if n.index[key] == nil && v != nil {
+			e.unmap(prefix+key, v)
+		}
+	}
+}
+
+// field writes a single field. If the value does not match the schema,
+// nothing is written and false is returned.
+func (e *encoder) field(n *node, v interface{}, path string) bool {
+	switch n.kind {
+	case kindRecord:
+		m, ok := toMap(v)
+		if !ok {
+			return false
+		}
+		e.long(branchValue)
+		e.record(n, m, path+".")
+		return true
+
+	case kindObject:
+		m, ok := toMap(v)
+		if !ok {
+			return false
+		}
+		values := make(map[string]string, len(m))
+		for k, v := range m {
+			values[k] = toJSONString(v)
+		}
+		e.long(branchValue)
+		e.stringMap(values)
+		return true
+
+	case kindJSON:
+		e.long(branchValue)
+		e.str(toJSONString(v))
+		return true
+	}
+
+	convert := converters[n.kind]
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		converted, ok := convert(v)
+		if !ok {
+			return false
+		}
+		e.long(branchValue)
+		e.value(n.kind, converted)
+		return true
+	}
+
+	values := make([]interface{}, rv.Len())
+	for i := range values {
+		converted, ok := convert(rv.Index(i).Interface())
+		if !ok {
+			return false
+		}
+		values[i] = converted
+	}
+
+	e.long(branchValues)
+	if len(values) > 0 {
+		e.long(int64(len(values)))
+		for _, converted := range values {
+			e.value(n.kind, converted)
+		}
+	}
+	e.long(0)
+	return true
+}
+
+func (e *encoder) value(k kind, v interface{}) {
+	switch k {
+	case kindString:
+		e.str(v.(string))
+	case kindLong:
+		e.long(v.(int64))
+	case kindDouble:
+		e.double(v.(float64))
+	case kindBoolean:
+		e.boolean(v.(bool))
+	}
+}
+
+func (e *encoder) unmap(path string, v interface{}) {
+	if e.unmapped == nil {
+		e.unmapped = map[string]string{}
+	}
+	e.unmapped[path] = toJSONString(v)
+}
+
+var converters = map[kind]func(interface{}) (interface{}, bool){
+	kindString:  toString,
+	kindLong:    toLong,
+	kindDouble:  toDouble,
+	kindBoolean: toBoolean,
+}
+
+const timestampFormat = "2006-01-02T15:04:05.000Z07:00"
+
+func toMap(v interface{}) (map[string]interface{}, bool) {
+	switch m := v.(type) {
+	case common.MapStr:
+		return m, true
+	case map[string]interface{}:
+		return m, true
+	}
+	return nil, false
+}
+
+func toString(v interface{}) (interface{}, bool) {
+	switch s := v.(type) {
+	case string:
+		return s, true
+	case time.Time:
+		return s.UTC().Format(timestampFormat), true
+	case common.Time:
+		return time.Time(s).UTC().Format(timestampFormat), true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.String:
+		return rv.String(), true
+	case reflect.Bool:
+		return strconv.FormatBool(rv.Bool()), true
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), true
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64), true
+	}
+	return nil, false
+}
+
+func toLong(v interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if u := rv.Uint(); u <= math.MaxInt64 {
+			return int64(u), true
+		}
+	case reflect.Float32, reflect.Float64:
+		if f := rv.Float(); f == math.Trunc(f) && f >= math.MinInt64 && f <= math.MaxInt64 {
+			return int64(f), true
+		}
+	case reflect.String:
+		if i, err := strconv.ParseInt(rv.String(), 10, 64); err == nil {
+			return i, true
+		}
+	}
+	return nil, false
+}
+
+func toDouble(v interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	case reflect.String:
+		if f, err := strconv.ParseFloat(rv.String(), 64); err == nil {
+			return f, true
+		}
+	}
+	return nil, false
+}
+
+func toBoolean(v interface{}) (interface{}, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Bool:
+		return rv.Bool(), true
+	case reflect.String:
+		if b, err := strconv.ParseBool(rv.String()); err == nil {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+// toJSONString returns strings as is and the JSON encoding for all other
+// values.
+func toJSONString(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	return string(b)
+}
@@ -0,0 +1,109 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+const registryContentType = "application/vnd.schemaregistry.v1+json"
+
+type registryConfig struct {
+	URL      string             `config:"url"`
+	Subject  string             `config:"subject"`
+	Username string             `config:"username"`
+	Password string             `config:"password"`
+	TLS      *outputs.TLSConfig `config:"ssl"`
+	Timeout  time.Duration      `config:"timeout" validate:"positive"`
+}
+
+// registry is a client for the Confluent Schema Registry REST API.
+type registry struct {
+	url      string
+	username string
+	password string
+	http     *http.Client
+}
+
+func newRegistry(config registryConfig) (*registry, error) {
+	u, err := url.Parse(config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema registry url: %v", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid schema registry url '%v': scheme must be http or https", config.URL)
+	}
+
+	tls, err := outputs.LoadTLSConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	httpTransport := &http.Transport{Proxy: http.ProxyFromEnvironment}
+	if tls != nil {
+		httpTransport.TLSClientConfig = tls.BuildModuleConfig(u.Hostname())
+	}
+
+	return &registry{
+		url:      strings.TrimRight(config.URL, "/"),
+		username: config.Username,
+		password: config.Password,
+		http: &http.Client{
+			Transport: httpTransport,
+			Timeout:   config.Timeout,
+		},
+	}, nil
+}
+
+// register registers the schema under the given subject and returns the
+// schema ID. Registering a schema that is already known to the registry
+// returns the existing ID.
+func (r *registry) register(subject string, schema []byte) (int32, error) {
+	body, err := json.Marshal(struct {
+		Schema string `json:"schema"`
+	}{string(schema)})
+	if err != nil {
+		return 0, err
+	}
+
+	path := fmt.Sprintf("%s/subjects/%s/versions", r.url, url.PathEscape(subject))
+	req, err := http.NewRequest("POST", path, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", registryContentType)
+	req.Header.Set("Accept", registryContentType)
+	if r.username != "" || r.password != "" {
+		req.SetBasicAuth(r.username, r.password)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to register schema: %v", err)
+	}
+	defer resp.Body.Close()
+
+	content, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read schema registry response: %v", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("failed to register schema for subject '%v': %v: %s",
+			subject, resp.Status, content)
+	}
+
+	var result struct {
+		ID *int32 `json:"id"`
+	}
+	if err := json.Unmarshal(content, &result); err != nil || result.ID == nil {
+		return 0, fmt.Errorf("invalid schema registry response: %s", content)
+	}
+	return *result.ID, nil
+}
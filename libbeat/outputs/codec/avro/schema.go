@@ -0,0 +1,277 @@
+package avro
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// kind is the encoding used for a node of the schema tree.
+type kind uint8
+
+const (
+	kindRecord kind = iota
+	kindString
+	kindLong
+	kindDouble
+	kindBoolean
+	kindObject // map of strings
+	kindJSON   // string holding the JSON encoded value
+)
+
+// node is an entry of the schema tree derived from fields.yml. Leaf nodes
+// map to a single Avro field, record nodes to a nested Avro record.
+type node struct {
+	key      string // field name in the event
+	name     string // Avro field name
+	kind     kind
+	children []*node
+	index    map[string]*node
+	names    map[string]bool
+}
+
+func newRecord(key, name string) *node {
+	return &node{
+		key:   key,
+		name:  name,
+		kind:  kindRecord,
+		index: map[string]*node{},
+		names: map[string]bool{},
+	}
+}
+
+// buildTree creates the schema tree for the given field definitions. Dotted
+// field names are expanded into nested records. If multiple definitions
+// exist for the same field, the first one wins.
+func buildTree(fields common.Fields) *node {
+	root := newRecord("", "")
+	for _, f := range fields {
+		addField(root, nil, f)
+	}
+	return root
+}
+
+func addField(root *node, path []string, f common.Field) {
+	if f.Name == "" {
+		return
+	}
+	path = append(path, strings.Split(f.Name, ".")...)
+
+	if f.Type == "group" || (f.Type == "" && len(f.Fields) > 0) {
+		for _, child := range f.Fields {
+			addField(root, path, child)
+		}
+		return
+	}
+
+	// @timestamp and @metadata have dedicated fields in the record.
+	if len(path) == 1 && strings.HasPrefix(path[0], "@") {
+		return
+	}
+
+	parent := root
+	for _, key := range path[:len(path)-1] {
+		child := parent.index[key]
+		if child == nil {
+			child = newRecord(key, parent.uniqueName(key))
+			parent.add(child)
+		}
+		if child.kind != kindRecord {
+			// conflicting definitions, keep the leaf
+			return
+		}
+		parent = child
+	}
+
+	key := path[len(path)-1]
+	if parent.index[key] != nil {
+		return
+	}
+	parent.add(&node{key: key, name: parent.uniqueName(key), kind: fieldKind(f.Type)})
+}
+
+func (n *node) add(child *node) {
+	n.children = append(n.children, child)
+	n.index[child.key] = child
+	n.names[child.name] = true
+}
+
+// uniqueName returns a valid Avro name for key, that is not yet used by
+// another field of the record.
+func (n *node) uniqueName(key string) string {
+	name := avroName(key)
+	if !n.names[name] {
+		return name
+	}
+	for i := 2; ; i++ {
+		if candidate := name + "_" + strconv.Itoa(i); !n.names[candidate] {
+			return candidate
+		}
+	}
+}
+
+// avroName replaces all characters not allowed in Avro names with '_'. Names
+// starting with a digit are prefixed with '_'.
+func avroName(s string) string {
+	name := []byte(s)
+	for i, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9', c == '_':
+		default:
+			name[i] = '_'
+		}
+	}
+	if len(name) == 0 {
+		return "_"
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		return "_" + string(name)
+	}
+	return string(name)
+}
+
+func fieldKind(typ string) kind {
+	switch typ {
+	case "long", "integer", "short", "byte":
+		return kindLong
+	case "float", "half_float", "scaled_float", "double":
+		return kindDouble
+	case "boolean":
+		return kindBoolean
+	case "object":
+		return kindObject
+	case "nested", "array", "geo_point":
+		return kindJSON
+	default:
+		// keyword, text, ip, date and unknown types
+		return kindString
+	}
+}
+
+// Avro schema representation, see https://avro.apache.org/docs/1.8.2/spec.html
+
+type recordSchema struct {
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	Doc       string        `json:"doc,omitempty"`
+	Fields    []fieldSchema `json:"fields"`
+}
+
+type fieldSchema struct {
+	Name    string      `json:"name"`
+	Type    interface{} `json:"type"`
+	Default interface{} `json:"default"`
+}
+
+type arraySchema struct {
+	Type  string      `json:"type"`
+	Items interface{} `json:"items"`
+}
+
+type mapSchema struct {
+	Type   string      `json:"type"`
+	Values interface{} `json:"values"`
+}
+
+type longSchema struct {
+	Type        string `json:"type"`
+	LogicalType string `json:"logicalType"`
+}
+
+const (
+	schemaNamespace  = "co.elastic.beats"
+	timestampField   = "timestamp"
+	metadataField    = "metadata"
+	unmappedField    = "_unmapped"
+	unmappedFieldDoc = "Fields not defined in fields.yml or not matching the defined type, as JSON"
+)
+
+var stringMap = mapSchema{Type: "map", Values: "string"}
+
+// makeSchema builds the Avro schema for the events of a Beat. All fields
+// defined in fields.yml are optional. The event timestamp and metadata are
+// stored in the timestamp and metadata fields, fields not covered by the
+// schema in the _unmapped field.
+func makeSchema(beatName string, root *node) recordSchema {
+	name := avroName(beatName)
+
+	fields := []fieldSchema{
+		{
+			Name:    timestampField,
+			Type:    longSchema{Type: "long", LogicalType: "timestamp-millis"},
+			Default: 0,
+		},
+		{
+			Name:    metadataField,
+			Type:    stringMap,
+			Default: map[string]string{},
+		},
+	}
+	fields = append(fields, recordFields(name, root)...)
+	fields = append(fields, fieldSchema{
+		Name:    unmappedField,
+		Type:    stringMap,
+		Default: map[string]string{},
+	})
+
+	return recordSchema{
+		Type:      "record",
+		Name:      name,
+		Namespace: schemaNamespace,
+		Doc:       "Event published by " + beatName,
+		Fields:    fields,
+	}
+}
+
+func recordFields(fullname string, n *node) []fieldSchema {
+	fields := make([]fieldSchema, 0, len(n.children))
+	for _, child := range n.children {
+		var typ []interface{}
+		switch child.kind {
+		case kindRecord:
+			name := fullname + "." + child.name
+			typ = optional(recordSchema{
+				Type:   "record",
+				Name:   name,
+				Fields: recordFields(name, child),
+			})
+		case kindString:
+			typ = optionalScalarOrArray("string")
+		case kindLong:
+			typ = optionalScalarOrArray("long")
+		case kindDouble:
+			typ = optionalScalarOrArray("double")
+		case kindBoolean:
+			typ = optionalScalarOrArray("boolean")
+		case kindObject:
+			typ = optional(stringMap)
+		case kindJSON:
+			typ = optional("string")
+		}
+
+		fields = append(fields, fieldSchema{
+			Name:    child.name,
+			Type:    typ,
+			Default: nil,
+		})
+	}
+	return fields
+}
+
+// Union branches of optional fields. Leaf fields accept a single value or an
+// array of values.
+const (
+	branchNull   = 0
+	branchValue  = 1
+	branchValues = 2
+)
+
+func optional(typ interface{}) []interface{} {
+	return []interface{}{"null", typ}
+}
+
+func optionalScalarOrArray(typ string) []interface{} {
+	return []interface{}{"null", typ, arraySchema{Type: "array", Items: typ}}
+}
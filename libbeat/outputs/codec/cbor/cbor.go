@@ -0,0 +1,74 @@
+package cbor
+
+import (
+	"bytes"
+
+	"github.com/elastic/go-structform"
+	"github.com/elastic/go-structform/cborl"
+	"github.com/elastic/go-structform/gotype"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+)
+
+// Encoder for serializing a beat.Event to CBOR (RFC 7049).
+type Encoder struct {
+	buf     bytes.Buffer
+	folder  *gotype.Iterator
+	version string
+}
+
+func init() {
+	codec.RegisterType("cbor", func(info beat.Info, cfg *common.Config) (codec.Codec, error) {
+		return New(info.Version), nil
+	})
+}
+
+// New creates a new CBOR Encoder.
+func New(version string) *Encoder {
+	e := &Encoder{version: version}
+	e.reset()
+	return e
+}
+
+func (e *Encoder) reset() {
+	visitor := indefiniteObjects{structform.EnsureExtVisitor(cborl.NewVisitor(&e.buf))}
+
+	var err error
+
+	// create new encoder with custom time.Time encoding
+	e.folder, err = gotype.NewIterator(visitor,
+		gotype.Folders(
+			codec.MakeTimestampEncoder(),
+			codec.MakeBCTimestampEncoder(),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Encode serializes a beat event to CBOR. It adds additional metadata in the
+// `@metadata` namespace.
+func (e *Encoder) Encode(index string, event *beat.Event) ([]byte, error) {
+	e.buf.Reset()
+	err := e.folder.Fold(codec.MakeEvent(index, e.version, event))
+	if err != nil {
+		e.reset()
+		return nil, err
+	}
+
+	return e.buf.Bytes(), nil
+}
+
+// indefiniteObjects encodes all objects as indefinite-length maps. The number
+// of fields reported for structs with inline fields does not match the number
+// of keys actually written, which would produce a corrupt definite-length map.
+type indefiniteObjects struct {
+	structform.ExtVisitor
+}
+
+func (v indefiniteObjects) OnObjectStart(len int, baseType structform.BaseType) error {
+	return v.ExtVisitor.OnObjectStart(-1, baseType)
+}
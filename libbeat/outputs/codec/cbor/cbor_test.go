@@ -0,0 +1,51 @@
+package cbor
+
+import (
+	"testing"
+	"time"
+
+	"github.com/elastic/go-structform/cborl"
+	"github.com/elastic/go-structform/gotype"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestCborCodec(t *testing.T) {
+	codec := New("1.2.3")
+	output, err := codec.Encode("test", &beat.Event{
+		Timestamp: time.Date(2018, 7, 1, 10, 30, 0, 0, time.UTC),
+		Meta:      common.MapStr{"pipeline": "p1"},
+		Fields: common.MapStr{
+			"msg":    "message",
+			"count":  3,
+			"nested": common.MapStr{"tags": []string{"a", "b"}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var decoded map[string]interface{}
+	unfolder, err := gotype.NewUnfolder(&decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cborl.NewParser(unfolder).Parse(output); err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "2018-07-01T10:30:00.000Z", decoded["@timestamp"])
+	assert.Equal(t, map[string]interface{}{
+		"beat":     "test",
+		"type":     "doc",
+		"version":  "1.2.3",
+		"pipeline": "p1",
+	}, decoded["@metadata"])
+	assert.Equal(t, "message", decoded["msg"])
+	assert.EqualValues(t, 3, decoded["count"])
+	assert.Equal(t, map[string]interface{}{
+		"tags": []interface{}{"a", "b"},
+	}, decoded["nested"])
+}
@@ -1,4 +1,4 @@
-package json
+package codec
 
 import (
 	"time"
@@ -8,25 +8,27 @@ import (
 )
 
 // Event describes the event structure for events
-// (in-)directly send to logstash
-type event struct {
+// (in-)directly send to logstash. The json, cbor and msgpack codecs encode
+// the same structure.
+type Event struct {
 	Timestamp time.Time     `struct:"@timestamp"`
-	Meta      meta          `struct:"@metadata"`
+	Meta      Meta          `struct:"@metadata"`
 	Fields    common.MapStr `struct:",inline"`
 }
 
 // Meta defines common event metadata to be stored in '@metadata'
-type meta struct {
+type Meta struct {
 	Beat    string                 `struct:"beat"`
 	Type    string                 `struct:"type"`
 	Version string                 `struct:"version"`
 	Fields  map[string]interface{} `struct:",inline"`
 }
 
-func makeEvent(index, version string, in *beat.Event) event {
-	return event{
+// MakeEvent creates the event structure to be encoded for a beat event.
+func MakeEvent(index, version string, in *beat.Event) Event {
+	return Event{
 		Timestamp: in.Timestamp,
-		Meta: meta{
+		Meta: Meta{
 			Beat:    index,
 			Version: version,
 			Type:    "doc",
@@ -69,7 +69,7 @@ func (e *Encoder) reset() {
 // `@metadata` namespace.
 func (e *Encoder) Encode(index string, event *beat.Event) ([]byte, error) {
 	e.buf.Reset()
-	err := e.folder.Fold(makeEvent(index, e.version, event))
+	err := e.folder.Fold(codec.MakeEvent(index, e.version, event))
 	if err != nil {
 		e.reset()
 		return nil, err
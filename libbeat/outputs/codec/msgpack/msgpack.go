@@ -0,0 +1,62 @@
+package msgpack
+
+import (
+	"bytes"
+
+	"github.com/elastic/go-structform/gotype"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+)
+
+// Encoder for serializing a beat.Event to MessagePack.
+type Encoder struct {
+	buf     bytes.Buffer
+	visitor *visitor
+	folder  *gotype.Iterator
+	version string
+}
+
+func init() {
+	codec.RegisterType("msgpack", func(info beat.Info, cfg *common.Config) (codec.Codec, error) {
+		return New(info.Version), nil
+	})
+}
+
+// New creates a new MessagePack Encoder.
+func New(version string) *Encoder {
+	e := &Encoder{version: version}
+	e.reset()
+	return e
+}
+
+func (e *Encoder) reset() {
+	e.visitor = newVisitor(&e.buf)
+
+	var err error
+
+	// create new encoder with custom time.Time encoding
+	e.folder, err = gotype.NewIterator(e.visitor,
+		gotype.Folders(
+			codec.MakeTimestampEncoder(),
+			codec.MakeBCTimestampEncoder(),
+		),
+	)
+	if err != nil {
+		panic(err)
+	}
+}
+
+// Encode serializes a beat event to MessagePack. It adds additional metadata
+// in the `@metadata` namespace.
+func (e *Encoder) Encode(index string, event *beat.Event) ([]byte, error) {
+	e.buf.Reset()
+	err := e.folder.Fold(codec.MakeEvent(index, e.version, event))
+	if err != nil {
+		e.reset()
+		return nil, err
+	}
+
+	return e.buf.Bytes(), nil
+}
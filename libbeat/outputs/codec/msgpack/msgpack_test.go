@@ -0,0 +1,168 @@
+package msgpack
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestMsgpackCodec(t *testing.T) {
+	codec := New("1.2.3")
+	output, err := codec.Encode("test", &beat.Event{
+		Timestamp: time.Date(2018, 7, 1, 10, 30, 0, 0, time.UTC),
+		Meta:      common.MapStr{"pipeline": "p1"},
+		Fields: common.MapStr{
+			"msg":    "message",
+			"nested": common.MapStr{"tags": []string{"a", "b"}},
+			"ints":   []interface{}{0, 127, 128, -1, -33, -200, 70000, int64(-5000000000), uint64(math.MaxUint64)},
+			"float":  1.5,
+			"ok":     true,
+			"none":   nil,
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, rest, err := decode(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Empty(t, rest)
+
+	assert.Equal(t, map[string]interface{}{
+		"@timestamp": "2018-07-01T10:30:00.000Z",
+		"@metadata": map[string]interface{}{
+			"beat":     "test",
+			"type":     "doc",
+			"version":  "1.2.3",
+			"pipeline": "p1",
+		},
+		"msg":    "message",
+		"nested": map[string]interface{}{"tags": []interface{}{"a", "b"}},
+		"ints": []interface{}{
+			int64(0), int64(127), int64(128), int64(-1), int64(-33), int64(-200),
+			int64(70000), int64(-5000000000), uint64(math.MaxUint64),
+		},
+		"float": 1.5,
+		"ok":    true,
+		"none":  nil,
+	}, decoded)
+}
+
+func TestMsgpackLongString(t *testing.T) {
+	long := string(make([]byte, 70000))
+
+	codec := New("1.2.3")
+	output, err := codec.Encode("test", &beat.Event{Fields: common.MapStr{"msg": long}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	decoded, _, err := decode(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, long, decoded.(map[string]interface{})["msg"])
+}
+
+// decode is a minimal MessagePack decoder supporting the types written by
+// the visitor.
+func decode(b []byte) (interface{}, []byte, error) {
+	if len(b) == 0 {
+		return nil, nil, fmt.Errorf("unexpected end of input")
+	}
+
+	c, b := b[0], b[1:]
+	switch {
+	case c <= 0x7f:
+		return int64(c), b, nil
+	case c >= 0xe0:
+		return int64(int8(c)), b, nil
+	case c&0xf0 == 0x80:
+		return decodeMap(int(c&0x0f), b)
+	case c&0xf0 == 0x90:
+		return decodeArray(int(c&0x0f), b)
+	case c&0xe0 == 0xa0:
+		l := int(c & 0x1f)
+		return string(b[:l]), b[l:], nil
+	}
+
+	switch c {
+	case 0xc0:
+		return nil, b, nil
+	case 0xc2:
+		return false, b, nil
+	case 0xc3:
+		return true, b, nil
+	case 0xcb:
+		return math.Float64frombits(binary.BigEndian.Uint64(b)), b[8:], nil
+	case 0xcc:
+		return int64(b[0]), b[1:], nil
+	case 0xcd:
+		return int64(binary.BigEndian.Uint16(b)), b[2:], nil
+	case 0xce:
+		return int64(binary.BigEndian.Uint32(b)), b[4:], nil
+	case 0xcf:
+		return binary.BigEndian.Uint64(b), b[8:], nil
+	case 0xd0:
+		return int64(int8(b[0])), b[1:], nil
+	case 0xd1:
+		return int64(int16(binary.BigEndian.Uint16(b))), b[2:], nil
+	case 0xd2:
+		return int64(int32(binary.BigEndian.Uint32(b))), b[4:], nil
+	case 0xd3:
+		return int64(binary.BigEndian.Uint64(b)), b[8:], nil
+	case 0xd9:
+		l := int(b[0])
+		return string(b[1 : 1+l]), b[1+l:], nil
+	case 0xda:
+		l := int(binary.BigEndian.Uint16(b))
+		return string(b[2 : 2+l]), b[2+l:], nil
+	case 0xdb:
+		l := int(binary.BigEndian.Uint32(b))
+		return string(b[4 : 4+l]), b[4+l:], nil
+	case 0xdc:
+		return decodeArray(int(binary.BigEndian.Uint16(b)), b[2:])
+	case 0xde:
+		return decodeMap(int(binary.BigEndian.Uint16(b)), b[2:])
+	}
+	return nil, nil, fmt.Errorf("unsupported code 0x%x", c)
+}
+
+func decodeMap(n int, b []byte) (interface{}, []byte, error) {
+	m := map[string]interface{}{}
+	for i := 0; i < n; i++ {
+		k, rest, err := decode(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		v, rest, err := decode(rest)
+		if err != nil {
+			return nil, nil, err
+		}
+		m[k.(string)] = v
+		b = rest
+	}
+	return m, b, nil
+}
+
+func decodeArray(n int, b []byte) (interface{}, []byte, error) {
+	a := []interface{}{}
+	for i := 0; i < n; i++ {
+		v, rest, err := decode(b)
+		if err != nil {
+			return nil, nil, err
+		}
+		a = append(a, v)
+		b = rest
+	}
+	return a, b, nil
+}
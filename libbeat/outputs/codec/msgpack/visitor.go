@@ -0,0 +1,267 @@
+package msgpack
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+
+	"github.com/elastic/go-structform"
+)
+
+// visitor is a structform.Visitor writing MessagePack.
+//
+// MessagePack requires the number of entries in maps and arrays to be known
+// upfront. As the length reported by the folder is not reliable for structs
+// with inline fields, the contents of every map and array are buffered until
+// the structure is finished.
+type visitor struct {
+	out     *bytes.Buffer
+	stack   []*frame
+	free    []*frame
+	scratch [9]byte
+}
+
+type frame struct {
+	array bool
+	count int
+	buf   bytes.Buffer
+}
+
+var errStructure = errors.New("msgpack: unbalanced structure")
+
+func newVisitor(out *bytes.Buffer) *visitor {
+	return &visitor{out: out}
+}
+
+// w returns the buffer of the structure currently being written.
+func (v *visitor) w() *bytes.Buffer {
+	if len(v.stack) == 0 {
+		return v.out
+	}
+	return &v.stack[len(v.stack)-1].buf
+}
+
+// value counts a new value written to the current array.
+func (v *visitor) value() {
+	if len(v.stack) > 0 {
+		if f := v.stack[len(v.stack)-1]; f.array {
+			f.count++
+		}
+	}
+}
+
+func (v *visitor) push(array bool) {
+	v.value()
+
+	var f *frame
+	if n := len(v.free); n > 0 {
+		f = v.free[n-1]
+		v.free = v.free[:n-1]
+	} else {
+		f = &frame{}
+	}
+	f.array = array
+	v.stack = append(v.stack, f)
+}
+
+func (v *visitor) pop(array bool) error {
+	n := len(v.stack)
+	if n == 0 || v.stack[n-1].array != array {
+		return errStructure
+	}
+
+	f := v.stack[n-1]
+	v.stack = v.stack[:n-1]
+	defer v.release(f)
+
+	if array {
+		v.writeLen(0x90, 0xdc, f.count)
+	} else {
+		v.writeLen(0x80, 0xde, f.count)
+	}
+	_, err := v.w().Write(f.buf.Bytes())
+	return err
+}
+
+func (v *visitor) release(f *frame) {
+	f.count = 0
+	f.buf.Reset()
+	v.free = append(v.free, f)
+}
+
+// writeLen writes a map or array header. fix is the code of the fixed size
+// variant, code16 the code of the 16bit variant. The 32bit variant always
+// follows the 16bit one.
+func (v *visitor) writeLen(fix, code16 byte, l int) {
+	w := v.w()
+	switch {
+	case l < 16:
+		w.WriteByte(fix | byte(l))
+	case l <= math.MaxUint16:
+		v.scratch[0] = code16
+		binary.BigEndian.PutUint16(v.scratch[1:], uint16(l))
+		w.Write(v.scratch[:3])
+	default:
+		v.scratch[0] = code16 + 1
+		binary.BigEndian.PutUint32(v.scratch[1:], uint32(l))
+		w.Write(v.scratch[:5])
+	}
+}
+
+func (v *visitor) OnObjectStart(len int, baseType structform.BaseType) error {
+	v.push(false)
+	return nil
+}
+
+func (v *visitor) OnObjectFinished() error {
+	return v.pop(false)
+}
+
+func (v *visitor) OnKey(s string) error {
+	n := len(v.stack)
+	if n == 0 || v.stack[n-1].array {
+		return errStructure
+	}
+	v.stack[n-1].count++
+	v.writeString(s)
+	return nil
+}
+
+func (v *visitor) OnArrayStart(len int, baseType structform.BaseType) error {
+	v.push(true)
+	return nil
+}
+
+func (v *visitor) OnArrayFinished() error {
+	return v.pop(true)
+}
+
+func (v *visitor) OnNil() error {
+	v.value()
+	return v.w().WriteByte(0xc0)
+}
+
+func (v *visitor) OnBool(b bool) error {
+	v.value()
+	if b {
+		return v.w().WriteByte(0xc3)
+	}
+	return v.w().WriteByte(0xc2)
+}
+
+func (v *visitor) OnString(s string) error {
+	v.value()
+	v.writeString(s)
+	return nil
+}
+
+func (v *visitor) writeString(s string) {
+	w := v.w()
+	l := len(s)
+	switch {
+	case l < 32:
+		w.WriteByte(0xa0 | byte(l))
+	case l <= math.MaxUint8:
+		w.WriteByte(0xd9)
+		w.WriteByte(byte(l))
+	case l <= math.MaxUint16:
+		v.scratch[0] = 0xda
+		binary.BigEndian.PutUint16(v.scratch[1:], uint16(l))
+		w.Write(v.scratch[:3])
+	default:
+		v.scratch[0] = 0xdb
+		binary.BigEndian.PutUint32(v.scratch[1:], uint32(l))
+		w.Write(v.scratch[:5])
+	}
+	w.WriteString(s)
+}
+
+func (v *visitor) OnInt8(i int8) error   { return v.OnInt64(int64(i)) }
+func (v *visitor) OnInt16(i int16) error { return v.OnInt64(int64(i)) }
+func (v *visitor) OnInt32(i int32) error { return v.OnInt64(int64(i)) }
+func (v *visitor) OnInt(i int) error     { return v.OnInt64(int64(i)) }
+
+func (v *visitor) OnInt64(i int64) error {
+	if i >= 0 {
+		return v.OnUint64(uint64(i))
+	}
+
+	v.value()
+	w := v.w()
+	switch {
+	case i >= -32:
+		return w.WriteByte(byte(i))
+	case i >= math.MinInt8:
+		v.scratch[0] = 0xd0
+		v.scratch[1] = byte(i)
+		_, err := w.Write(v.scratch[:2])
+		return err
+	case i >= math.MinInt16:
+		v.scratch[0] = 0xd1
+		binary.BigEndian.PutUint16(v.scratch[1:], uint16(i))
+		_, err := w.Write(v.scratch[:3])
+		return err
+	case i >= math.MinInt32:
+		v.scratch[0] = 0xd2
+		binary.BigEndian.PutUint32(v.scratch[1:], uint32(i))
+		_, err := w.Write(v.scratch[:5])
+		return err
+	default:
+		v.scratch[0] = 0xd3
+		binary.BigEndian.PutUint64(v.scratch[1:], uint64(i))
+		_, err := w.Write(v.scratch[:9])
+		return err
+	}
+}
+
+func (v *visitor) OnByte(b byte) error     { return v.OnUint64(uint64(b)) }
+func (v *visitor) OnUint8(u uint8) error   { return v.OnUint64(uint64(u)) }
+func (v *visitor) OnUint16(u uint16) error { return v.OnUint64(uint64(u)) }
+func (v *visitor) OnUint32(u uint32) error { return v.OnUint64(uint64(u)) }
+func (v *visitor) OnUint(u uint) error     { return v.OnUint64(uint64(u)) }
+
+func (v *visitor) OnUint64(u uint64) error {
+	v.value()
+	w := v.w()
+	switch {
+	case u <= math.MaxInt8:
+		return w.WriteByte(byte(u))
+	case u <= math.MaxUint8:
+		v.scratch[0] = 0xcc
+		v.scratch[1] = byte(u)
+		_, err := w.Write(v.scratch[:2])
+		return err
+	case u <= math.MaxUint16:
+		v.scratch[0] = 0xcd
+		binary.BigEndian.PutUint16(v.scratch[1:], uint16(u))
+		_, err := w.Write(v.scratch[:3])
+		return err
+	case u <= math.MaxUint32:
+		v.scratch[0] = 0xce
+		binary.BigEndian.PutUint32(v.scratch[1:], uint32(u))
+		_, err := w.Write(v.scratch[:5])
+		return err
+	default:
+		v.scratch[0] = 0xcf
+		binary.BigEndian.PutUint64(v.scratch[1:], u)
+		_, err := w.Write(v.scratch[:9])
+		return err
+	}
+}
+
+func (v *visitor) OnFloat32(f float32) error {
+	v.value()
+	v.scratch[0] = 0xca
+	binary.BigEndian.PutUint32(v.scratch[1:], math.Float32bits(f))
+	_, err := v.w().Write(v.scratch[:5])
+	return err
+}
+
+func (v *visitor) OnFloat64(f float64) error {
+	v.value()
+	v.scratch[0] = 0xcb
+	binary.BigEndian.PutUint64(v.scratch[1:], math.Float64bits(f))
+	_, err := v.w().Write(v.scratch[:9])
+	return err
+}
@@ -0,0 +1,53 @@
+package dlq
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/elastic/beats/libbeat/common/file"
+	"github.com/elastic/beats/libbeat/paths"
+)
+
+// Config configures the dead letter queue of an output.
+type Config struct {
+	Enabled       bool   `config:"enabled"`
+	Path          string `config:"path"`
+	Filename      string `config:"filename"`
+	RotateEveryKb uint   `config:"rotate_every_kb" validate:"min=1"`
+	NumberOfFiles uint   `config:"number_of_files"`
+	Permissions   uint32 `config:"permissions"`
+}
+
+// DefaultConfig returns the default dead letter queue settings. The dead
+// letter queue is disabled by default.
+func DefaultConfig() Config {
+	return Config{
+		Enabled:       false,
+		RotateEveryKb: 10 * 1024,
+		NumberOfFiles: 7,
+		Permissions:   0600,
+	}
+}
+
+func (c *Config) Validate() error {
+	if c.NumberOfFiles < 2 || c.NumberOfFiles > file.MaxBackupsLimit {
+		return fmt.Errorf("the number_of_files to keep should be between 2 and %v",
+			file.MaxBackupsLimit)
+	}
+	return nil
+}
+
+// FilePath returns the path of the active dead letter queue file. Rotated
+// files use the same path with a numeric suffix.
+func (c *Config) FilePath(beatName string) string {
+	dir := c.Path
+	if dir == "" {
+		dir = paths.Resolve(paths.Data, "dlq")
+	}
+
+	name := c.Filename
+	if name == "" {
+		name = beatName + ".dlq"
+	}
+	return filepath.Join(dir, name)
+}
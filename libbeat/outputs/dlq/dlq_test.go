@@ -0,0 +1,181 @@
+package dlq
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestWriteRead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := DefaultConfig()
+	config.Path = dir
+	w, err := NewWriter("testbeat", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, filepath.Join(dir, "testbeat.dlq"), w.Path())
+
+	ts := time.Date(2018, 6, 1, 12, 0, 0, 0, time.UTC)
+	event := beat.Event{
+		Timestamp: ts,
+		Meta:      common.MapStr{"pipeline": "test"},
+		Fields: common.MapStr{
+			"count":   42,
+			"ratio":   0.5,
+			"message": "hello",
+			"nested":  common.MapStr{"value": 1},
+		},
+	}
+	for i := 0; i < 3; i++ {
+		err := w.Write(NewRecord("elasticsearch", &event, 400, []byte(`{"reason":"mapping error"}`)))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var records []Record
+	_, err = ReadFile(w.Path(), func(r Record) error {
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !assert.Len(t, records, 3) {
+		return
+	}
+	r := records[0]
+	assert.Equal(t, "elasticsearch", r.Output)
+	assert.Equal(t, 400, r.Status)
+	assert.Equal(t, "mapping error", r.Reason)
+
+	replay := r.BeatEvent()
+	assert.True(t, ts.Equal(replay.Timestamp))
+	assert.Equal(t, common.MapStr{"pipeline": "test"}, replay.Meta)
+	assert.Equal(t, int64(42), replay.Fields["count"])
+	assert.Equal(t, 0.5, replay.Fields["ratio"])
+	assert.Equal(t, "hello", replay.Fields["message"])
+	v, err := replay.Fields.GetValue("nested.value")
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), v)
+}
+
+func TestNewRecordReason(t *testing.T) {
+	event := &beat.Event{Fields: common.MapStr{}}
+
+	tests := []struct {
+		msg    string
+		reason string
+		err    string
+	}{
+		{``, ``, ``},
+		{`{"type":"x","reason":"bad field"}`, `bad field`, `{"type":"x","reason":"bad field"}`},
+		{`"plain error"`, `plain error`, `"plain error"`},
+		{`not json`, `not json`, `"not json"`},
+	}
+
+	for _, test := range tests {
+		r := NewRecord("elasticsearch", event, 400, []byte(test.msg))
+		assert.Equal(t, test.reason, r.Reason, test.msg)
+		assert.Equal(t, test.err, string(r.Error), test.msg)
+	}
+}
+
+func TestReadIncompleteRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.dlq")
+	content := `{"output":"elasticsearch","event":{"fields":{"a":1}}}` + "\n" + `{"output":"elast`
+	if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	count := 0
+	skipped, err := ReadFile(path, func(r Record) error {
+		count++
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+	assert.Equal(t, 1, skipped)
+}
+
+func TestFiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "test.dlq")
+	for _, name := range []string{"test.dlq", "test.dlq.1", "test.dlq.2", "test.dlq.10", "test.dlq.replay", "other.dlq"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), nil, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := Files(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{path + ".10", path + ".2", path + ".1", path}, files)
+}
+
+func TestWriterLock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := DefaultConfig()
+	config.Path = dir
+	event := &beat.Event{Fields: common.MapStr{"message": "hello"}}
+	record := NewRecord("elasticsearch", event, 400, nil)
+
+	w, err := NewWriter("testbeat", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, CheckNotLocked(w.Path()), "the lock must only be taken on the first write")
+	if err := w.Write(record); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, ErrInUse, CheckNotLocked(w.Path()))
+
+	other, err := NewWriter("testbeat", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Error(t, other.Write(record), "the dead letter queue must not be shared by writers")
+	assert.NoError(t, other.Close())
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	assert.NoError(t, CheckNotLocked(w.Path()))
+
+	assert.NoError(t, other.Write(record))
+	other.Close()
+}
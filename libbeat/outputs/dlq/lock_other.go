@@ -0,0 +1,16 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package dlq
+
+import "os"
+
+// lockFile creates the lock file at path. Locking is not supported on this
+// platform.
+func lockFile(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+}
+
+// checkNotLocked is not supported on this platform.
+func checkNotLocked(path string) error {
+	return nil
+}
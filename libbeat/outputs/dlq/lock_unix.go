@@ -0,0 +1,40 @@
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package dlq
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// lockFile creates the lock file at path and takes an exclusive lock on it.
+// ErrInUse is returned if another writer holds the lock.
+func lockFile(path string) (*os.File, error) {
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+	if err != nil {
+		f.Close()
+		if err == unix.EWOULDBLOCK {
+			return nil, ErrInUse
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// checkNotLocked returns ErrInUse if a writer holds the lock file at path.
+func checkNotLocked(path string) error {
+	f, err := lockFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,117 @@
+package dlq
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// Reader reads records from a dead letter queue file.
+type Reader struct {
+	dec     *json.Decoder
+	line    int
+	skipped int
+	log     *logp.Logger
+}
+
+// NewReader creates a reader for records in r.
+func NewReader(r io.Reader) *Reader {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	dec.UseNumber()
+	return &Reader{dec: dec, log: logp.NewLogger("dlq")}
+}
+
+// Next returns the next record. io.EOF is returned once all records have
+// been read.
+//
+// A crash while writing can leave an incomplete last record. The incomplete
+// record is skipped and io.EOF is returned.
+func (r *Reader) Next() (Record, error) {
+	r.line++
+	record, err := decodeRecord(r.dec)
+	if err == io.EOF {
+		return record, err
+	}
+	if err == io.ErrUnexpectedEOF {
+		r.log.Warnf("Skipping incomplete record %v at the end of the dead letter queue file", r.line)
+		r.skipped++
+		return record, io.EOF
+	}
+	if err != nil {
+		return record, errors.Wrapf(err, "failed to decode record %v", r.line)
+	}
+	return record, nil
+}
+
+// Skipped returns the number of incomplete records skipped by the reader.
+func (r *Reader) Skipped() int {
+	return r.skipped
+}
+
+// ReadFile calls fn for every record in the dead letter queue file at path.
+// The number of skipped incomplete records is returned.
+func ReadFile(path string, fn func(Record) error) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	reader := NewReader(f)
+	for {
+		record, err := reader.Next()
+		if err == io.EOF {
+			return reader.Skipped(), nil
+		}
+		if err != nil {
+			return reader.Skipped(), errors.Wrapf(err, "invalid dead letter queue file %v", path)
+		}
+
+		if err := fn(record); err != nil {
+			return reader.Skipped(), err
+		}
+	}
+}
+
+// Files returns the dead letter queue file at path and all rotated files,
+// ordered from the oldest to the newest file.
+func Files(path string) ([]string, error) {
+	matches, err := filepath.Glob(path + ".*")
+	if err != nil {
+		return nil, err
+	}
+
+	type rotated struct {
+		path string
+		n    int
+	}
+	var files []rotated
+	for _, m := range matches {
+		n, err := strconv.Atoi(strings.TrimPrefix(m, path+"."))
+		if err != nil || n <= 0 {
+			continue
+		}
+		files = append(files, rotated{m, n})
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].n > files[j].n })
+
+	paths := make([]string, 0, len(files)+1)
+	for _, f := range files {
+		paths = append(paths, f.path)
+	}
+	if _, err := os.Stat(path); err == nil {
+		paths = append(paths, path)
+	} else if !os.IsNotExist(err) {
+		return nil, err
+	}
+	return paths, nil
+}
@@ -0,0 +1,95 @@
+package dlq
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/jsontransform"
+)
+
+// Record is a single entry in the dead letter queue. It stores the rejected
+// event and the reason the output rejected the event.
+type Record struct {
+	Timestamp time.Time       `json:"@timestamp"`
+	Output    string          `json:"output"`
+	Status    int             `json:"status,omitempty"`
+	Reason    string          `json:"reason,omitempty"`
+	Error     json.RawMessage `json:"error,omitempty"`
+	Event     Event           `json:"event"`
+}
+
+// Event is the serialized form of a rejected beat.Event.
+type Event struct {
+	Timestamp time.Time     `json:"@timestamp"`
+	Meta      common.MapStr `json:"@metadata,omitempty"`
+	Fields    common.MapStr `json:"fields"`
+}
+
+// NewRecord creates a record for an event rejected by output. The error
+// message is the raw error returned by the output, which is stored as is if
+// it is valid JSON. The reason is taken from the error object's 'reason'
+// field, if present.
+func NewRecord(output string, event *beat.Event, status int, msg []byte) Record {
+	r := Record{
+		Timestamp: time.Now().UTC(),
+		Output:    output,
+		Status:    status,
+		Event: Event{
+			Timestamp: event.Timestamp,
+			Meta:      event.Meta,
+			Fields:    event.Fields,
+		},
+	}
+
+	if len(msg) == 0 {
+		return r
+	}
+
+	if !json.Valid(msg) {
+		r.Reason = string(msg)
+		r.Error, _ = json.Marshal(r.Reason)
+		return r
+	}
+
+	r.Error = json.RawMessage(msg)
+	var details struct {
+		Reason string `json:"reason"`
+	}
+	if err := json.Unmarshal(msg, &details); err == nil && details.Reason != "" {
+		r.Reason = details.Reason
+	} else {
+		var str string
+		if err := json.Unmarshal(msg, &str); err == nil {
+			r.Reason = str
+		}
+	}
+	return r
+}
+
+// BeatEvent returns the rejected event.
+func (r *Record) BeatEvent() beat.Event {
+	return beat.Event{
+		Timestamp: r.Event.Timestamp,
+		Meta:      r.Event.Meta,
+		Fields:    r.Event.Fields,
+	}
+}
+
+func decodeRecord(dec *json.Decoder) (Record, error) {
+	var r Record
+	if err := dec.Decode(&r); err != nil {
+		return r, err
+	}
+
+	// restore numeric types lost in the JSON encoding
+	if r.Event.Meta != nil {
+		jsontransform.TransformNumbers(r.Event.Meta)
+	}
+	if r.Event.Fields == nil {
+		r.Event.Fields = common.MapStr{}
+	}
+	jsontransform.TransformNumbers(r.Event.Fields)
+	return r, nil
+}
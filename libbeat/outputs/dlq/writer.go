@@ -0,0 +1,136 @@
+package dlq
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/elastic/beats/libbeat/common/file"
+	"github.com/elastic/beats/libbeat/logp"
+)
+
+// ErrInUse is returned if the dead letter queue is used by another writer.
+var ErrInUse = errors.New("dead letter queue is in use by another process or output")
+
+// Writer appends records to the dead letter queue file. The file is rotated
+// once it reaches the configured size. Writer is safe for concurrent use.
+type Writer struct {
+	path    string
+	config  Config
+	mu      sync.Mutex
+	rotator *file.Rotator
+	lock    *os.File
+	log     *logp.Logger
+}
+
+// NewWriter creates a dead letter queue writer for the beat. The dead letter
+// queue is only opened once the first record is written, so loading the output
+// does not fail while another process uses the dead letter queue. The writer
+// holds a lock on the dead letter queue from the first write until it is
+// closed, so the queue can not be shared by multiple outputs or replayed while
+// the beat is running.
+func NewWriter(beatName string, config Config) (*Writer, error) {
+	log := logp.NewLogger("dlq")
+	log.Infof("Initialized dead letter queue. path=%v max_size_bytes=%v max_backups=%v permissions=%v",
+		config.FilePath(beatName), config.RotateEveryKb*1024, config.NumberOfFiles, os.FileMode(config.Permissions))
+
+	return &Writer{path: config.FilePath(beatName), config: config, log: log}, nil
+}
+
+// open locks the dead letter queue and creates the file rotator.
+func (w *Writer) open() error {
+	if err := os.MkdirAll(filepath.Dir(w.path), dirMode(w.config.Permissions)); err != nil {
+		return err
+	}
+	lock, err := lockFile(lockPath(w.path))
+	if err != nil {
+		return fmt.Errorf("failed to lock dead letter queue %v: %v", w.path, err)
+	}
+
+	rotator, err := file.NewFileRotator(
+		w.path,
+		file.MaxSizeBytes(w.config.RotateEveryKb*1024),
+		file.MaxBackups(w.config.NumberOfFiles),
+		file.Permissions(os.FileMode(w.config.Permissions)),
+	)
+	if err != nil {
+		lock.Close()
+		return err
+	}
+
+	w.rotator = rotator
+	w.lock = lock
+	return nil
+}
+
+// CheckNotLocked returns ErrInUse if a writer holds the lock of the dead letter
+// queue at path.
+func CheckNotLocked(path string) error {
+	return checkNotLocked(lockPath(path))
+}
+
+func lockPath(path string) string {
+	return path + ".lock"
+}
+
+// dirMode returns the mode of the dead letter queue directory. Group and
+// others can list the directory if they can read the files.
+func dirMode(permissions uint32) os.FileMode {
+	mode := os.FileMode(0700)
+	if permissions&0070 > 0 {
+		mode |= 0050
+	}
+	if permissions&0007 > 0 {
+		mode |= 0005
+	}
+	return mode
+}
+
+// Path returns the path of the active dead letter queue file.
+func (w *Writer) Path() string {
+	return w.path
+}
+
+// Write appends a record to the dead letter queue. The record is written
+// using a single write call, so a partially written file contains complete
+// records only, except for the last line.
+func (w *Writer) Write(r Record) error {
+	line, err := json.Marshal(r)
+	if err != nil {
+		return err
+	}
+	line = append(line, '\n')
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.rotator == nil {
+		if err := w.open(); err != nil {
+			return err
+		}
+	}
+	_, err = w.rotator.Write(line)
+	return err
+}
+
+// Close closes the active dead letter queue file and releases the lock.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.rotator == nil {
+		return nil
+	}
+
+	err := w.rotator.Close()
+	w.rotator = nil
+	if w.lock != nil {
+		if lerr := w.lock.Close(); err == nil {
+			err = lerr
+		}
+		w.lock = nil
+	}
+	return err
+}
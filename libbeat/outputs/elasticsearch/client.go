@@ -14,6 +14,7 @@ import (
 	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/logp"
 	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/dlq"
 	"github.com/elastic/beats/libbeat/outputs/outil"
 	"github.com/elastic/beats/libbeat/outputs/transport"
 	"github.com/elastic/beats/libbeat/publisher"
@@ -40,6 +41,12 @@ type Client struct {
 	compressionLevel int
 	proxyURL         *url.URL
 
+	// dead letter queue for events rejected by Elasticsearch. Optional.
+	deadLetter *dlq.Writer
+
+	// adaptive bulk window shared by all clients of the output. Optional.
+	window *adaptiveWindow
+
 	observer outputs.Observer
 }
 
@@ -56,6 +63,7 @@ type ClientSettings struct {
 	Timeout            time.Duration
 	CompressionLevel   int
 	Observer           outputs.Observer
+	DeadLetterQueue    *dlq.Writer
 }
 
 type connectCallback func(client *Client) error
@@ -93,6 +101,7 @@ type bulkResultStats struct {
 	acked        int // number of events ACKed by Elasticsearch
 	duplicates   int // number of events failed with `create` due to ID already being indexed
 	fails        int // number of failed events (can be retried)
+	tooMany      int // number of events rejected due to back pressure (status 429)
 	nonIndexable int // number of failed events (not indexable -> must be dropped)
 }
 
@@ -201,6 +210,7 @@ func NewClient(
 
 		compressionLevel: compression,
 		proxyURL:         s.Proxy,
+		deadLetter:       s.DeadLetterQueue,
 		observer:         s.Observer,
 	}
 
@@ -250,7 +260,15 @@ func (client *Client) Clone() *Client {
 
 func (client *Client) Publish(batch publisher.Batch) error {
 	events := batch.Events()
-	rest, err := client.publishEvents(events)
+
+	var rest []publisher.Event
+	var err error
+	if client.window == nil {
+		rest, err = client.publishEvents(events)
+	} else {
+		rest, err = client.publishWindowed(events)
+	}
+
 	if len(rest) == 0 {
 		batch.ACK()
 	} else {
@@ -259,12 +277,46 @@ func (client *Client) Publish(batch publisher.Batch) error {
 	return err
 }
 
+// publishWindowed splits the events into bulk requests of the current
+// adaptive window size. On error all events not published are returned.
+func (client *Client) publishWindowed(
+	data []publisher.Event,
+) ([]publisher.Event, error) {
+	for len(data) > 0 {
+		n := client.window.get()
+		if n > len(data) {
+			n = len(data)
+		}
+
+		client.window.acquire()
+		begin := time.Now()
+		failed, rejected, err := client.publishBulk(data[:n])
+		client.window.release(n, time.Since(begin), rejected, err)
+
+		if err != nil {
+			return append(failed, data[n:]...), err
+		}
+		data = data[n:]
+	}
+	return nil, nil
+}
+
 // PublishEvents sends all events to elasticsearch. On error a slice with all
 // events not published or confirmed to be processed by elasticsearch will be
 // returned. The input slice backing memory will be reused by return the value.
 func (client *Client) publishEvents(
 	data []publisher.Event,
 ) ([]publisher.Event, error) {
+	failed, _, err := client.publishBulk(data)
+	return failed, err
+}
+
+// publishBulk sends the events in a single bulk request, like publishEvents.
+// In addition it reports if Elasticsearch rejected the request or some of the
+// events due to back pressure (status 429).
+func (client *Client) publishBulk(
+	data []publisher.Event,
+) ([]publisher.Event, bool, error) {
 	begin := time.Now()
 	st := client.observer
 
@@ -273,7 +325,7 @@ func (client *Client) publishEvents(
 	}
 
 	if len(data) == 0 {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	body := client.encoder
@@ -289,7 +341,7 @@ func (client *Client) publishEvents(
 		st.Dropped(origCount - newCount)
 	}
 	if newCount == 0 {
-		return nil, nil
+		return nil, false, nil
 	}
 
 	requ := client.bulkRequ
@@ -297,7 +349,7 @@ func (client *Client) publishEvents(
 	status, result, sendErr := client.sendBulkRequest(requ)
 	if sendErr != nil {
 		logp.Err("Failed to perform any bulk index operations: %s", sendErr)
-		return data, sendErr
+		return data, status == 429, sendErr
 	}
 
 	debugf("PublishEvents: %d events have been  published to elasticsearch in %v.",
@@ -312,7 +364,7 @@ func (client *Client) publishEvents(
 		stats.fails = len(failedEvents)
 	} else {
 		client.json.init(result.raw)
-		failedEvents, stats = bulkCollectPublishFails(&client.json, data)
+		failedEvents, stats = bulkCollectPublishFails(&client.json, data, client.deadLetter)
 	}
 
 	failed := len(failedEvents)
@@ -327,13 +379,14 @@ func (client *Client) publishEvents(
 		st.Duplicate(duplicates)
 	}
 
+	rejected := status == 429 || stats.tooMany > 0
 	if failed > 0 {
 		if sendErr == nil {
 			sendErr = errTempBulkFailure
 		}
-		return failedEvents, sendErr
+		return failedEvents, rejected, sendErr
 	}
-	return nil, nil
+	return nil, rejected, nil
 }
 
 // fillBulkRequest encodes all bulk requests and returns slice of events
@@ -378,17 +431,7 @@ func createEventBulkMeta(
 		return nil, err
 	}
 
-	var id string
-	if m := event.Meta; m != nil {
-		if tmp := m["id"]; tmp != nil {
-			if s, ok := tmp.(string); ok {
-				id = s
-			} else {
-				logp.Err("Event ID '%v' is no string value", id)
-			}
-		}
-	}
-
+	id := getID(event)
 	meta := bulkEventMeta{
 		Index:    index,
 		DocType:  eventType,
@@ -402,6 +445,28 @@ func createEventBulkMeta(
 	return bulkIndexAction{meta}, nil
 }
 
+// getID returns the document ID set in the events metadata. The ID can be
+// set in '_id' (e.g. by the fingerprint processor) or in 'id'.
+func getID(event *beat.Event) string {
+	m := event.Meta
+	if m == nil {
+		return ""
+	}
+
+	for _, key := range []string{"_id", "id"} {
+		tmp := m[key]
+		if tmp == nil {
+			continue
+		}
+
+		if s, ok := tmp.(string); ok {
+			return s
+		}
+		logp.Err("Event ID '%v' is no string value", tmp)
+	}
+	return ""
+}
+
 func getPipeline(event *beat.Event, pipelineSel *outil.Selector) (string, error) {
 	if event.Meta != nil {
 		if pipeline, exists := event.Meta["pipeline"]; exists {
@@ -439,10 +504,12 @@ func getIndex(event *beat.Event, index outil.Selector) (string, error) {
 // bulkCollectPublishFails checks per item errors returning all events
 // to be tried again due to error code returned for that items. If indexing an
 // event failed due to some error in the event itself (e.g. does not respect mapping),
-// the event will be dropped.
+// the event will be dropped. Dropped events are written to the dead letter
+// queue, if deadLetter is not nil.
 func bulkCollectPublishFails(
 	reader *jsonReader,
 	data []publisher.Event,
+	deadLetter *dlq.Writer,
 ) ([]publisher.Event, bulkResultStats) {
 	if err := reader.expectDict(); err != nil {
 		logp.Err("Failed to parse bulk respose: expected JSON object")
@@ -499,13 +566,28 @@ func bulkCollectPublishFails(
 
 		if status < 500 && status != 429 {
 			// hard failure, don't collect
-			logp.Warn("Cannot index event %#v (status=%v): %s", data[i], status, msg)
 			stats.nonIndexable++
+			if deadLetter == nil {
+				logp.Warn("Cannot index event %#v (status=%v): %s", data[i], status, msg)
+				continue
+			}
+
+			record := dlq.NewRecord("elasticsearch", &data[i].Content, status, msg)
+			if err := deadLetter.Write(record); err != nil {
+				logp.Err("Failed to write event to the dead letter queue: %v", err)
+				logp.Warn("Cannot index event %#v (status=%v): %s", data[i], status, msg)
+				continue
+			}
+			logp.Warn("Cannot index event (status=%v): %s. Event written to the dead letter queue %v",
+				status, msg, deadLetter.Path())
 			continue
 		}
 
 		debugf("Bulk item insert failed (i=%v, status=%v): %s", i, status, msg)
 		stats.fails++
+		if status == 429 {
+			stats.tooMany++
+		}
 		failed = append(failed, data[i])
 	}
 
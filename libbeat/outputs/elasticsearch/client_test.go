@@ -4,8 +4,10 @@ package elasticsearch
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
 	"testing"
 	"time"
@@ -16,6 +18,8 @@ import (
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/common/fmtstr"
 	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/monitoring"
+	"github.com/elastic/beats/libbeat/outputs/dlq"
 	"github.com/elastic/beats/libbeat/outputs/outest"
 	"github.com/elastic/beats/libbeat/outputs/outil"
 	"github.com/elastic/beats/libbeat/publisher"
@@ -84,7 +88,7 @@ func TestCollectPublishFailsNone(t *testing.T) {
 	}
 
 	reader := newJSONReader(response)
-	res, _ := bulkCollectPublishFails(reader, events)
+	res, _ := bulkCollectPublishFails(reader, events, nil)
 	assert.Equal(t, 0, len(res))
 }
 
@@ -102,7 +106,7 @@ func TestCollectPublishFailMiddle(t *testing.T) {
 	events := []publisher.Event{event, eventFail, event}
 
 	reader := newJSONReader(response)
-	res, _ := bulkCollectPublishFails(reader, events)
+	res, _ := bulkCollectPublishFails(reader, events, nil)
 	assert.Equal(t, 1, len(res))
 	if len(res) == 1 {
 		assert.Equal(t, eventFail, res[0])
@@ -122,11 +126,63 @@ func TestCollectPublishFailAll(t *testing.T) {
 	events := []publisher.Event{event, event, event}
 
 	reader := newJSONReader(response)
-	res, _ := bulkCollectPublishFails(reader, events)
+	res, _ := bulkCollectPublishFails(reader, events, nil)
 	assert.Equal(t, 3, len(res))
 	assert.Equal(t, events, res)
 }
 
+func TestCollectPublishFailDeadLetterQueue(t *testing.T) {
+	dir, err := ioutil.TempDir("", "es-dlq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	config := dlq.DefaultConfig()
+	config.Enabled = true
+	config.Path = dir
+	deadLetter, err := dlq.NewWriter("testbeat", config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer deadLetter.Close()
+
+	response := []byte(`
+    { "items": [
+      {"create": {"status": 200}},
+      {"create": {"status": 400, "error": {"type": "mapper_parsing_exception", "reason": "failed to parse [field]"}}},
+      {"create": {"status": 429, "error": "ups"}}
+    ]}
+  `)
+
+	event := publisher.Event{Content: beat.Event{Fields: common.MapStr{"field": 1}}}
+	eventReject := publisher.Event{Content: beat.Event{Fields: common.MapStr{"field": "2"}}}
+	eventRetry := publisher.Event{Content: beat.Event{Fields: common.MapStr{"field": 3}}}
+	events := []publisher.Event{event, eventReject, eventRetry}
+
+	reader := newJSONReader(response)
+	res, stats := bulkCollectPublishFails(reader, events, deadLetter)
+	assert.Equal(t, []publisher.Event{eventRetry}, res)
+	assert.Equal(t, 1, stats.nonIndexable)
+
+	var records []dlq.Record
+	_, err = dlq.ReadFile(deadLetter.Path(), func(r dlq.Record) error {
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if assert.Len(t, records, 1) {
+		r := records[0]
+		assert.Equal(t, "elasticsearch", r.Output)
+		assert.Equal(t, 400, r.Status)
+		assert.Equal(t, "failed to parse [field]", r.Reason)
+		assert.Equal(t, common.MapStr{"field": "2"}, r.Event.Fields)
+	}
+}
+
 func TestCollectPipelinePublishFail(t *testing.T) {
 	logp.TestingSetup(logp.WithSelectors("elasticsearch"))
 
@@ -163,7 +219,7 @@ func TestCollectPipelinePublishFail(t *testing.T) {
 	events := []publisher.Event{event}
 
 	reader := newJSONReader(response)
-	res, _ := bulkCollectPublishFails(reader, events)
+	res, _ := bulkCollectPublishFails(reader, events, nil)
 	assert.Equal(t, 1, len(res))
 	assert.Equal(t, events, res)
 }
@@ -209,6 +265,49 @@ func TestGetIndexOverwrite(t *testing.T) {
 	assert.Equal(t, expected, index)
 }
 
+func TestGetID(t *testing.T) {
+	tests := []struct {
+		name     string
+		meta     common.MapStr
+		expected string
+	}{
+		{"no meta", nil, ""},
+		{"no id", common.MapStr{"index": "test"}, ""},
+		{"id", common.MapStr{"id": "abc"}, "abc"},
+		{"_id", common.MapStr{"_id": "abc"}, "abc"},
+		{"_id before id", common.MapStr{"_id": "abc", "id": "def"}, "abc"},
+		{"invalid _id", common.MapStr{"_id": 1, "id": "def"}, "def"},
+		{"invalid id", common.MapStr{"id": 1}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			event := &beat.Event{Meta: test.meta, Fields: common.MapStr{}}
+			assert.Equal(t, test.expected, getID(event))
+		})
+	}
+}
+
+func TestCreateEventBulkMetaWithID(t *testing.T) {
+	indexSel := outil.MakeSelector(outil.ConstSelectorExpr("test"))
+	event := &beat.Event{
+		Meta:   common.MapStr{"_id": "abc"},
+		Fields: common.MapStr{},
+	}
+
+	action, err := createEventBulkMeta(indexSel, nil, event)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	create, ok := action.(bulkCreateAction)
+	if !ok {
+		t.Fatalf("expected create action, got %T", action)
+	}
+	assert.Equal(t, "abc", create.Create.ID)
+	assert.Equal(t, "test", create.Create.Index)
+}
+
 func BenchmarkCollectPublishFailsNone(b *testing.B) {
 	response := []byte(`
     { "items": [
@@ -224,7 +323,7 @@ func BenchmarkCollectPublishFailsNone(b *testing.B) {
 	reader := newJSONReader(nil)
 	for i := 0; i < b.N; i++ {
 		reader.init(response)
-		res, _ := bulkCollectPublishFails(reader, events)
+		res, _ := bulkCollectPublishFails(reader, events, nil)
 		if len(res) != 0 {
 			b.Fail()
 		}
@@ -247,7 +346,7 @@ func BenchmarkCollectPublishFailMiddle(b *testing.B) {
 	reader := newJSONReader(nil)
 	for i := 0; i < b.N; i++ {
 		reader.init(response)
-		res, _ := bulkCollectPublishFails(reader, events)
+		res, _ := bulkCollectPublishFails(reader, events, nil)
 		if len(res) != 1 {
 			b.Fail()
 		}
@@ -269,7 +368,7 @@ func BenchmarkCollectPublishFailAll(b *testing.B) {
 	reader := newJSONReader(nil)
 	for i := 0; i < b.N; i++ {
 		reader.init(response)
-		res, _ := bulkCollectPublishFails(reader, events)
+		res, _ := bulkCollectPublishFails(reader, events, nil)
 		if len(res) != 3 {
 			b.Fail()
 		}
@@ -317,6 +416,63 @@ func TestClientWithHeaders(t *testing.T) {
 	assert.Equal(t, 2, requestCount)
 }
 
+func TestClientPublishWindowed(t *testing.T) {
+	var bulkSizes []int
+	reject := true
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		n := strings.Count(string(body), "\n") / 2
+		bulkSizes = append(bulkSizes, n)
+
+		status := 201
+		if reject {
+			status = 429
+			reject = false
+		}
+		items := make([]string, n)
+		for i := range items {
+			items[i] = fmt.Sprintf(`{"index":{"status":%v}}`, status)
+		}
+		fmt.Fprintf(w, `{"items":[%v]}`, strings.Join(items, ","))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(ClientSettings{
+		URL:   ts.URL,
+		Index: outil.MakeSelector(outil.ConstSelectorExpr("test")),
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	client.window = newAdaptiveWindow(defaultConfig.Adaptive, 100, 1, monitoring.NewRegistry())
+
+	events := make([]beat.Event, 200)
+	for i := range events {
+		events[i] = beat.Event{Timestamp: time.Now(), Fields: common.MapStr{"message": i}}
+	}
+
+	// first bulk request is rejected, shrinking the window
+	batch := outest.NewBatch(events...)
+	err = client.Publish(batch)
+	assert.Error(t, err)
+	assert.Equal(t, []int{50}, bulkSizes)
+	assert.Equal(t, 25, client.window.get())
+	if assert.Len(t, batch.Signals, 1) {
+		assert.Equal(t, outest.BatchRetryEvents, batch.Signals[0].Tag)
+		assert.Len(t, batch.Signals[0].Events, 200)
+	}
+
+	// window grows with every successful bulk request
+	bulkSizes = nil
+	batch = outest.NewBatch(events...)
+	err = client.Publish(batch)
+	assert.NoError(t, err)
+	assert.Equal(t, []int{25, 38, 57, 80}, bulkSizes)
+	if assert.Len(t, batch.Signals, 1) {
+		assert.Equal(t, outest.BatchACK, batch.Signals[0].Tag)
+	}
+}
+
 func TestAddToURL(t *testing.T) {
 	type Test struct {
 		url      string
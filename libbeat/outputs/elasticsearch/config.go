@@ -4,6 +4,8 @@ import (
 	"time"
 
 	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/dlq"
+	"github.com/elastic/beats/libbeat/template"
 )
 
 type elasticsearchConfig struct {
@@ -21,6 +23,16 @@ type elasticsearchConfig struct {
 	MaxRetries       int                `config:"max_retries"`
 	Timeout          time.Duration      `config:"timeout"`
 	Backoff          Backoff            `config:"backoff"`
+	DeadLetterQueue  dlq.Config         `config:"dead_letter_queue"`
+	ILM              template.ILMConfig `config:"ilm"`
+	Adaptive         adaptiveConfig     `config:"adaptive"`
+}
+
+type adaptiveConfig struct {
+	Enabled       bool          `config:"enabled"`
+	MinBulkSize   int           `config:"min_bulk_size"  validate:"min=1"`
+	MaxInFlight   int           `config:"max_in_flight"  validate:"min=0"`
+	TargetLatency time.Duration `config:"target_latency" validate:"min=1"`
 }
 
 type Backoff struct {
@@ -30,6 +42,9 @@ type Backoff struct {
 
 const (
 	defaultBulkSize = 50
+
+	// default maximum bulk size if adaptive bulk sizing is enabled
+	defaultAdaptiveBulkSize = 2048
 )
 
 var (
@@ -49,6 +64,14 @@ var (
 			Init: 1 * time.Second,
 			Max:  60 * time.Second,
 		},
+		DeadLetterQueue: dlq.DefaultConfig(),
+		ILM:             template.DefaultILMConfig,
+		Adaptive: adaptiveConfig{
+			Enabled:       false,
+			MinBulkSize:   10,
+			MaxInFlight:   0, // number of output clients
+			TargetLatency: 2 * time.Second,
+		},
 	}
 )
 
@@ -9,7 +9,10 @@ import (
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/logp"
 	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/dlq"
 	"github.com/elastic/beats/libbeat/outputs/outil"
+	"github.com/elastic/beats/libbeat/template"
+	"github.com/elastic/beats/libbeat/testing"
 )
 
 func init() {
@@ -53,11 +56,24 @@ func makeES(
 	cfg *common.Config,
 ) (outputs.Group, error) {
 	if !cfg.HasField("bulk_max_size") {
-		cfg.SetInt("bulk_max_size", -1, defaultBulkSize)
+		bulkSize := defaultBulkSize
+		if enabled, _ := cfg.Bool("adaptive.enabled", -1); enabled {
+			bulkSize = defaultAdaptiveBulkSize
+		}
+		cfg.SetInt("bulk_max_size", -1, int64(bulkSize))
 	}
 
 	if !cfg.HasField("index") {
+		ilmConfig, err := template.ReadILMConfig(cfg)
+		if err != nil {
+			return outputs.Fail(err)
+		}
+
+		// write to the rollover alias if index lifecycle management is enabled
 		pattern := fmt.Sprintf("%v-%v-%%{+yyyy.MM.dd}", beat.IndexPrefix, beat.Version)
+		if ilmConfig.Enabled {
+			pattern = ilmConfig.Alias(beat)
+		}
 		cfg.SetString("index", -1, pattern)
 	}
 
@@ -114,16 +130,33 @@ func makeES(
 		params = nil
 	}
 
+	var deadLetter *dlq.Writer
+	if config.DeadLetterQueue.Enabled {
+		deadLetter, err = dlq.NewWriter(beat.Beat, config.DeadLetterQueue)
+		if err != nil {
+			return outputs.Fail(err)
+		}
+	}
+
+	// the adaptive window is shared by all clients of the output. The number
+	// of concurrent requests is limited by the number of clients (hosts *
+	// workers) by default.
+	var window *adaptiveWindow
+	if config.Adaptive.Enabled {
+		window = newAdaptiveWindow(config.Adaptive, config.BulkMaxSize,
+			len(hosts), newWindowMetricsRegistry(observer))
+	}
+
 	clients := make([]outputs.NetworkClient, len(hosts))
 	for i, host := range hosts {
 		esURL, err := common.MakeURL(config.Protocol, config.Path, host, 9200)
 		if err != nil {
 			logp.Err("Invalid host param set: %s, Error: %v", host, err)
+			closeDeadLetter(deadLetter)
 			return outputs.Fail(err)
 		}
 
-		var client outputs.NetworkClient
-		client, err = NewClient(ClientSettings{
+		esClient, err := NewClient(ClientSettings{
 			URL:              esURL,
 			Index:            index,
 			Pipeline:         pipeline,
@@ -136,16 +169,76 @@ func makeES(
 			Timeout:          config.Timeout,
 			CompressionLevel: config.CompressionLevel,
 			Observer:         observer,
+			DeadLetterQueue:  deadLetter,
 		}, &connectCallbackRegistry)
 		if err != nil {
+			closeDeadLetter(deadLetter)
 			return outputs.Fail(err)
 		}
+		esClient.window = window
 
-		client = outputs.WithBackoff(client, config.Backoff.Init, config.Backoff.Max)
-		clients[i] = client
+		clients[i] = outputs.WithBackoff(esClient, config.Backoff.Init, config.Backoff.Max)
 	}
 
-	return outputs.SuccessNet(config.LoadBalance, config.BulkMaxSize, config.MaxRetries, clients)
+	group, err := outputs.SuccessNet(config.LoadBalance, config.BulkMaxSize, config.MaxRetries, clients)
+	if err != nil || deadLetter == nil {
+		return group, err
+	}
+	return withDeadLetterClose(group, deadLetter), nil
+}
+
+// deadLetterClient closes the dead letter queue writer shared by all clients
+// of the output, once the last client has been closed. The writer can not be
+// closed by the Elasticsearch client, as the client is closed and reconnected
+// on errors.
+type deadLetterClient struct {
+	outputs.NetworkClient
+	once    sync.Once
+	release func()
+}
+
+func withDeadLetterClose(group outputs.Group, deadLetter *dlq.Writer) outputs.Group {
+	var mu sync.Mutex
+	active := len(group.Clients)
+	release := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		if active--; active == 0 {
+			closeDeadLetter(deadLetter)
+		}
+	}
+
+	for i, client := range group.Clients {
+		group.Clients[i] = &deadLetterClient{
+			NetworkClient: client.(outputs.NetworkClient),
+			release:       release,
+		}
+	}
+	return group
+}
+
+func (c *deadLetterClient) Close() error {
+	err := c.NetworkClient.Close()
+	c.once.Do(c.release)
+	return err
+}
+
+func (c *deadLetterClient) Test(d testing.Driver) {
+	t, ok := c.NetworkClient.(testing.Testable)
+	if !ok {
+		d.Fatal("output", errors.New("client doesn't support testing"))
+	}
+
+	t.Test(d)
+}
+
+func closeDeadLetter(deadLetter *dlq.Writer) {
+	if deadLetter == nil {
+		return
+	}
+	if err := deadLetter.Close(); err != nil {
+		logp.Err("Failed to close dead letter queue %v: %v", deadLetter.Path(), err)
+	}
 }
 
 // NewConnectedClient creates a new Elasticsearch client based on the given config.
@@ -0,0 +1,49 @@
+package elasticsearch
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/dlq"
+	libtesting "github.com/elastic/beats/libbeat/testing"
+)
+
+func TestDeadLetterQueueOutput(t *testing.T) {
+	dir, err := ioutil.TempDir("", "dlq")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg, err := common.NewConfigFrom(map[string]interface{}{
+		"hosts":                     []string{"localhost:9200", "localhost:9201"},
+		"dead_letter_queue.enabled": true,
+		"dead_letter_queue.path":    dir,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info := beat.Info{Beat: "testbeat", IndexPrefix: "testbeat", Version: "6.3.1"}
+	group, err := makeES(info, outputs.NewNilObserver(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the dead letter queue is only locked once the first event is written,
+	// so the output can be loaded by 'test output' while the beat is running
+	assert.NoError(t, dlq.CheckNotLocked(filepath.Join(dir, "testbeat.dlq")))
+
+	for _, client := range group.Clients {
+		_, ok := client.(libtesting.Testable)
+		assert.True(t, ok, "the output must support testing")
+		assert.NoError(t, client.Close())
+	}
+}
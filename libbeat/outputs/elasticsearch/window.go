@@ -0,0 +1,189 @@
+package elasticsearch
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/libbeat/monitoring"
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+// adaptiveWindow controls the bulk size and the number of concurrent bulk
+// requests of all clients of an Elasticsearch output.
+//
+// Similar to the slow start window of the logstash output, the bulk size
+// grows by factor 1.5 as long as bulk requests succeed within the target
+// latency. Once the maximum bulk size is reached, the number of concurrent
+// bulk requests is increased by one. Slow responses shrink the bulk size,
+// while rejections due to back pressure (status 429) halve both the bulk
+// size and the number of concurrent requests.
+type adaptiveWindow struct {
+	mutex sync.Mutex
+	cond  *sync.Cond
+
+	bulkSize    int
+	minBulkSize int
+	maxBulkSize int
+
+	active      int // number of bulk requests being sent
+	inFlight    int // current limit of concurrent bulk requests
+	maxInFlight int
+
+	targetLatency time.Duration
+
+	metrics windowMetrics
+}
+
+type windowMetrics struct {
+	bulkSize *monitoring.Int
+	inFlight *monitoring.Int
+	active   *monitoring.Int
+	latency  *monitoring.Int
+	rejected *monitoring.Uint
+}
+
+func newAdaptiveWindow(
+	config adaptiveConfig,
+	maxBulkSize, maxInFlight int,
+	reg *monitoring.Registry,
+) *adaptiveWindow {
+	if maxBulkSize <= 0 {
+		maxBulkSize = defaultAdaptiveBulkSize
+	}
+
+	minBulkSize := config.MinBulkSize
+	if minBulkSize > maxBulkSize {
+		minBulkSize = maxBulkSize
+	}
+
+	start := defaultBulkSize
+	if start < minBulkSize {
+		start = minBulkSize
+	}
+	if start > maxBulkSize {
+		start = maxBulkSize
+	}
+
+	if config.MaxInFlight > 0 {
+		maxInFlight = config.MaxInFlight
+	}
+	if maxInFlight < 1 {
+		maxInFlight = 1
+	}
+
+	w := &adaptiveWindow{
+		bulkSize:      start,
+		minBulkSize:   minBulkSize,
+		maxBulkSize:   maxBulkSize,
+		inFlight:      1,
+		maxInFlight:   maxInFlight,
+		targetLatency: config.TargetLatency,
+		metrics: windowMetrics{
+			bulkSize: monitoring.NewInt(reg, "bulk_size"),
+			inFlight: monitoring.NewInt(reg, "in_flight.limit"),
+			active:   monitoring.NewInt(reg, "in_flight.active"),
+			latency:  monitoring.NewInt(reg, "latency_ms"),
+			rejected: monitoring.NewUint(reg, "rejected"),
+		},
+	}
+	w.cond = sync.NewCond(&w.mutex)
+	w.updateMetrics()
+	return w
+}
+
+// get returns the current bulk size.
+func (w *adaptiveWindow) get() int {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	return w.bulkSize
+}
+
+// acquire blocks until another bulk request can be sent.
+func (w *adaptiveWindow) acquire() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	for w.active >= w.inFlight {
+		w.cond.Wait()
+	}
+	w.active++
+	w.metrics.active.Set(int64(w.active))
+}
+
+// release marks a bulk request of size events as finished and adapts the
+// window based on the outcome of the request. Rejected must be true if
+// Elasticsearch returned 429 for the request or any of the bulk items.
+func (w *adaptiveWindow) release(
+	size int,
+	latency time.Duration,
+	rejected bool,
+	err error,
+) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	w.active--
+	w.metrics.latency.Set(int64(latency / time.Millisecond))
+
+	switch {
+	case rejected:
+		w.metrics.rejected.Inc()
+		w.shrinkBulkSize()
+		w.inFlight = w.inFlight / 2
+		if w.inFlight < 1 {
+			w.inFlight = 1
+		}
+
+	case err != nil:
+		w.shrinkBulkSize()
+
+	case latency > w.targetLatency:
+		w.bulkSize = int(float64(w.bulkSize) / 1.5)
+		if w.bulkSize < w.minBulkSize {
+			w.bulkSize = w.minBulkSize
+		}
+
+	case size >= w.bulkSize:
+		// only grow the window if the bulk request did fill the window
+		if w.bulkSize < w.maxBulkSize {
+			w.bulkSize = int(math.Ceil(1.5 * float64(w.bulkSize)))
+			if w.bulkSize > w.maxBulkSize {
+				w.bulkSize = w.maxBulkSize
+			}
+		} else if w.inFlight < w.maxInFlight {
+			w.inFlight++
+		}
+	}
+
+	w.updateMetrics()
+	w.cond.Broadcast()
+}
+
+func (w *adaptiveWindow) shrinkBulkSize() {
+	w.bulkSize = w.bulkSize / 2
+	if w.bulkSize < w.minBulkSize {
+		w.bulkSize = w.minBulkSize
+	}
+}
+
+func (w *adaptiveWindow) updateMetrics() {
+	w.metrics.bulkSize.Set(int64(w.bulkSize))
+	w.metrics.inFlight.Set(int64(w.inFlight))
+	w.metrics.active.Set(int64(w.active))
+}
+
+// newWindowMetricsRegistry creates the registry for reporting the adaptive
+// window in the stats registry of the output, e.g. 'libbeat.output.adaptive',
+// or 'libbeat.outputs.<name>.adaptive' for named outputs. Metrics of a previous
+// output instance are removed.
+func newWindowMetricsRegistry(observer outputs.Observer) *monitoring.Registry {
+	stats, ok := observer.(*outputs.Stats)
+	if !ok || stats.Registry() == nil {
+		return monitoring.NewRegistry()
+	}
+
+	reg := stats.Registry()
+	reg.Remove("adaptive")
+	return reg.NewRegistry("adaptive")
+}
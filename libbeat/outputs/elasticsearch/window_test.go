@@ -0,0 +1,130 @@
+// +build !integration
+
+package elasticsearch
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/monitoring"
+	"github.com/elastic/beats/libbeat/outputs"
+)
+
+func newTestWindow(maxBulkSize, maxInFlight int) (*adaptiveWindow, *monitoring.Registry) {
+	reg := monitoring.NewRegistry()
+	config := defaultConfig.Adaptive
+	config.Enabled = true
+	return newAdaptiveWindow(config, maxBulkSize, maxInFlight, reg), reg
+}
+
+func publishWindow(w *adaptiveWindow, size int, latency time.Duration, rejected bool, err error) {
+	w.acquire()
+	w.release(size, latency, rejected, err)
+}
+
+func TestAdaptiveWindowGrowUpToMax(t *testing.T) {
+	w, reg := newTestWindow(1000, 2)
+	assert.Equal(t, defaultBulkSize, w.get())
+
+	for i := 0; i < 100; i++ {
+		publishWindow(w, w.get(), time.Millisecond, false, nil)
+	}
+
+	assert.Equal(t, 1000, w.get())
+	assert.Equal(t, 2, w.inFlight)
+	assert.Equal(t, int64(1000), reg.Get("bulk_size").(*monitoring.Int).Get())
+	assert.Equal(t, int64(2), reg.Get("in_flight.limit").(*monitoring.Int).Get())
+}
+
+func TestAdaptiveWindowNoGrowOnSmallBatches(t *testing.T) {
+	w, _ := newTestWindow(1000, 2)
+
+	for i := 0; i < 10; i++ {
+		publishWindow(w, 10, time.Millisecond, false, nil)
+	}
+	assert.Equal(t, defaultBulkSize, w.get())
+}
+
+func TestAdaptiveWindowShrinkOnLatency(t *testing.T) {
+	w, _ := newTestWindow(1000, 2)
+	w.bulkSize = 900
+
+	publishWindow(w, 900, 3*time.Second, false, nil)
+	assert.Equal(t, 600, w.get())
+
+	for i := 0; i < 100; i++ {
+		publishWindow(w, w.get(), 3*time.Second, false, nil)
+	}
+	assert.Equal(t, 10, w.get())
+}
+
+func TestAdaptiveWindowShrinkOnRejection(t *testing.T) {
+	w, reg := newTestWindow(1000, 8)
+	w.bulkSize = 1000
+	w.inFlight = 8
+
+	publishWindow(w, 1000, time.Millisecond, true, errTempBulkFailure)
+	assert.Equal(t, 500, w.get())
+	assert.Equal(t, 4, w.inFlight)
+	assert.Equal(t, uint64(1), reg.Get("rejected").(*monitoring.Uint).Get())
+
+	for i := 0; i < 10; i++ {
+		publishWindow(w, w.get(), time.Millisecond, true, nil)
+	}
+	assert.Equal(t, 10, w.get())
+	assert.Equal(t, 1, w.inFlight)
+}
+
+func TestAdaptiveWindowShrinkOnError(t *testing.T) {
+	w, _ := newTestWindow(1000, 1)
+	w.bulkSize = 400
+
+	publishWindow(w, 400, time.Millisecond, false, errors.New("connection reset"))
+	assert.Equal(t, 200, w.get())
+	assert.Equal(t, 1, w.inFlight)
+}
+
+func TestAdaptiveWindowLimitInFlight(t *testing.T) {
+	w, _ := newTestWindow(100, 2)
+	w.inFlight = 2
+
+	w.acquire()
+	w.acquire()
+
+	acquired := make(chan struct{})
+	go func() {
+		w.acquire()
+		close(acquired)
+	}()
+
+	select {
+	case <-acquired:
+		t.Fatal("more requests in flight than allowed")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	w.release(10, time.Millisecond, false, nil)
+	select {
+	case <-acquired:
+	case <-time.After(time.Second):
+		t.Fatal("request not released")
+	}
+}
+
+func TestWindowMetricsRegistryPerOutput(t *testing.T) {
+	outReg := monitoring.NewRegistry()
+	a := outputs.NewStats(outReg.NewRegistry("a"))
+	b := outputs.NewStats(outReg.NewRegistry("b"))
+
+	config := defaultConfig.Adaptive
+	config.Enabled = true
+	newAdaptiveWindow(config, 100, 1, newWindowMetricsRegistry(a))
+	newAdaptiveWindow(config, 200, 1, newWindowMetricsRegistry(b))
+
+	assert.NotNil(t, outReg.Get("a.adaptive.bulk_size"))
+	assert.NotNil(t, outReg.Get("b.adaptive.bulk_size"))
+	assert.NotNil(t, newWindowMetricsRegistry(outputs.NewNilObserver()))
+}
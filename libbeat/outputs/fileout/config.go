@@ -2,18 +2,23 @@ package fileout
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/elastic/beats/libbeat/common/file"
+	"github.com/elastic/beats/libbeat/common/fmtstr"
 	"github.com/elastic/beats/libbeat/outputs/codec"
 )
 
 type config struct {
-	Path          string       `config:"path"`
-	Filename      string       `config:"filename"`
-	RotateEveryKb uint         `config:"rotate_every_kb" validate:"min=1"`
-	NumberOfFiles uint         `config:"number_of_files"`
-	Codec         codec.Config `config:"codec"`
-	Permissions   uint32       `config:"permissions"`
+	Path           string                    `config:"path"`
+	Filename       *fmtstr.EventFormatString `config:"filename"`
+	RotateEveryKb  uint                      `config:"rotate_every_kb" validate:"min=1"`
+	RotateInterval time.Duration             `config:"rotate_interval"`
+	NumberOfFiles  uint                      `config:"number_of_files"`
+	Compress       bool                      `config:"compress"`
+	CloseInactive  time.Duration             `config:"close_inactive"`
+	Codec          codec.Config              `config:"codec"`
+	Permissions    uint32                    `config:"permissions"`
 }
 
 var (
@@ -21,6 +26,7 @@ var (
 		NumberOfFiles: 7,
 		RotateEveryKb: 10 * 1024,
 		Permissions:   0600,
+		CloseInactive: 5 * time.Minute,
 	}
 )
 
@@ -30,5 +36,15 @@ func (c *config) Validate() error {
 			file.MaxBackupsLimit)
 	}
 
+	if c.RotateInterval != 0 && c.RotateInterval < time.Second {
+		return fmt.Errorf("The rotate_interval must be at least 1s, got %v",
+			c.RotateInterval)
+	}
+
+	if c.CloseInactive <= 0 {
+		return fmt.Errorf("The close_inactive timeout must be greater than 0, got %v",
+			c.CloseInactive)
+	}
+
 	return nil
 }
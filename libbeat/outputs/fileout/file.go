@@ -1,12 +1,18 @@
 package fileout
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/joeshaw/multierror"
 
 	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/common/file"
+	"github.com/elastic/beats/libbeat/common/fmtstr"
 	"github.com/elastic/beats/libbeat/logp"
 	"github.com/elastic/beats/libbeat/outputs"
 	"github.com/elastic/beats/libbeat/outputs/codec"
@@ -20,10 +26,20 @@ func init() {
 type fileOutput struct {
 	beat     beat.Info
 	observer outputs.Observer
-	rotator  *file.Rotator
+	config   config
+	filename *fmtstr.EventFormatString
+	files    map[string]*outputFile
+	inactive map[string]struct{} // files closed by close_inactive
 	codec    codec.Codec
 }
 
+// outputFile is an open output file. Files with a dynamic file name are closed
+// once no event has been written for close_inactive.
+type outputFile struct {
+	rotator  *file.Rotator
+	lastUsed time.Time
+}
+
 // makeFileout instantiates a new file output instance.
 func makeFileout(
 	beat beat.Info,
@@ -49,40 +65,125 @@ func makeFileout(
 	return outputs.Success(-1, 0, fo)
 }
 
-func (out *fileOutput) init(beat beat.Info, c config) error {
-	var path string
-	if c.Filename != "" {
-		path = filepath.Join(c.Path, c.Filename)
-	} else {
-		path = filepath.Join(c.Path, out.beat.Beat)
+func (out *fileOutput) init(info beat.Info, c config) error {
+	out.config = c
+	out.filename = c.Filename
+	out.files = map[string]*outputFile{}
+	out.inactive = map[string]struct{}{}
+
+	// With a constant file name the rotator is created right away, such that
+	// configuration errors are reported on startup.
+	if out.filename == nil || out.filename.IsConst() {
+		if _, err := out.rotator(&beat.Event{}); err != nil {
+			return err
+		}
 	}
 
 	var err error
-	out.rotator, err = file.NewFileRotator(
+	out.codec, err = codec.CreateEncoder(info, c.Codec)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// rotator returns the file rotator for the file name the event resolves to.
+// A new rotator is created for every file name seen for the first time.
+func (out *fileOutput) rotator(event *beat.Event) (*file.Rotator, error) {
+	name := out.beat.Beat
+	if out.filename != nil {
+		var err error
+		if name, err = out.filename.Run(event); err != nil {
+			return nil, err
+		}
+	}
+
+	name, err := sanitizeFilename(name)
+	if err != nil {
+		return nil, err
+	}
+	if f := out.files[name]; f != nil {
+		f.lastUsed = time.Now()
+		return f.rotator, nil
+	}
+
+	// A file closed by close_inactive is appended to when it is reopened, all
+	// other existing files are rotated.
+	_, reopen := out.inactive[name]
+
+	c := out.config
+	path := filepath.Join(c.Path, name)
+	r, err := file.NewFileRotator(
 		path,
 		file.MaxSizeBytes(c.RotateEveryKb*1024),
 		file.MaxBackups(c.NumberOfFiles),
 		file.Permissions(os.FileMode(c.Permissions)),
+		file.Interval(c.RotateInterval),
+		file.Compress(c.Compress),
+		file.Append(reopen),
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
+	delete(out.inactive, name)
+	out.files[name] = &outputFile{rotator: r, lastUsed: time.Now()}
 
-	out.codec, err = codec.CreateEncoder(beat, c.Codec)
-	if err != nil {
-		return err
+	logp.Info("Initialized file output. "+
+		"path=%v max_size_bytes=%v max_backups=%v permissions=%v "+
+		"rotate_interval=%v compress=%v",
+		path, c.RotateEveryKb*1024, c.NumberOfFiles, os.FileMode(c.Permissions),
+		c.RotateInterval, c.Compress)
+
+	return r, nil
+}
+
+// sanitizeFilename makes sure a file name derived from event contents can not
+// escape the configured output directory.
+func sanitizeFilename(name string) (string, error) {
+	name = strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator || r == 0 {
+			return '_'
+		}
+		return r
+	}, name)
+
+	if name == "" || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid file name '%v'", name)
 	}
+	return name, nil
+}
 
-	logp.Info("Initialized file output. "+
-		"path=%v max_size_bytes=%v max_backups=%v permissions=%v",
-		path, c.RotateEveryKb*1024, c.NumberOfFiles, os.FileMode(c.Permissions))
+// closeInactive closes the files with a dynamic file name that have not been
+// written to for close_inactive. A file is reopened, and appended to, once an
+// event with the file name is published again.
+func (out *fileOutput) closeInactive(now time.Time) {
+	if out.filename == nil || out.filename.IsConst() {
+		return
+	}
 
-	return nil
+	for name, f := range out.files {
+		if now.Sub(f.lastUsed) < out.config.CloseInactive {
+			continue
+		}
+
+		delete(out.files, name)
+		out.inactive[name] = struct{}{}
+		if err := f.rotator.Close(); err != nil {
+			logp.Err("Failed to close inactive output file %v: %v", name, err)
+		}
+	}
 }
 
 // Implement Outputer
 func (out *fileOutput) Close() error {
-	return out.rotator.Close()
+	var errs multierror.Errors
+	for _, f := range out.files {
+		if err := f.rotator.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs.Err()
 }
 
 func (out *fileOutput) Publish(
@@ -98,6 +199,18 @@ func (out *fileOutput) Publish(
 	for i := range events {
 		event := &events[i]
 
+		rotator, err := out.rotator(&event.Content)
+		if err != nil {
+			if event.Guaranteed() {
+				logp.Critical("Failed to select output file for the event: %v", err)
+			} else {
+				logp.Warn("Failed to select output file for the event: %v", err)
+			}
+
+			dropped++
+			continue
+		}
+
 		serializedEvent, err := out.codec.Encode(out.beat.Beat, &event.Content)
 		if err != nil {
 			if event.Guaranteed() {
@@ -110,7 +223,7 @@ func (out *fileOutput) Publish(
 			continue
 		}
 
-		if _, err = out.rotator.Write(append(serializedEvent, '\n')); err != nil {
+		if _, err = rotator.Write(append(serializedEvent, '\n')); err != nil {
 			st.WriteError(err)
 
 			if event.Guaranteed() {
@@ -129,5 +242,7 @@ func (out *fileOutput) Publish(
 	st.Dropped(dropped)
 	st.Acked(len(events) - dropped)
 
+	out.closeInactive(time.Now())
+
 	return nil
 }
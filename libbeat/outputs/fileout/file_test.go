@@ -1,3 +1,168 @@
 // +build !integration
 
 package fileout
+
+import (
+	"bufio"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs"
+	_ "github.com/elastic/beats/libbeat/outputs/codec/json"
+	"github.com/elastic/beats/libbeat/outputs/outest"
+)
+
+func TestDynamicFilename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := makeTestOutput(t, map[string]interface{}{
+		"path":     dir,
+		"filename": "%{[metricset.module]}.ndjson",
+	})
+	defer out.Close()
+
+	ts := time.Date(2018, 7, 1, 10, 30, 0, 0, time.UTC)
+	batch := outest.NewBatch(
+		beat.Event{Timestamp: ts, Fields: common.MapStr{"metricset": common.MapStr{"module": "system"}}},
+		beat.Event{Timestamp: ts, Fields: common.MapStr{"metricset": common.MapStr{"module": "mysql"}}},
+		beat.Event{Timestamp: ts, Fields: common.MapStr{"metricset": common.MapStr{"module": "system"}}},
+		beat.Event{Timestamp: ts, Fields: common.MapStr{"metricset": common.MapStr{"module": "../etc"}}},
+		beat.Event{Timestamp: ts, Fields: common.MapStr{"message": "no module"}},
+	)
+	if err := out.Publish(batch); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, outest.BatchACK, batch.Signals[0].Tag)
+
+	names := dirContents(t, dir)
+	assert.Equal(t, []string{".._etc.ndjson", "mysql.ndjson", "system.ndjson"}, names)
+	assert.Equal(t, 2, countLines(t, filepath.Join(dir, "system.ndjson")))
+	assert.Equal(t, 1, countLines(t, filepath.Join(dir, "mysql.ndjson")))
+}
+
+func TestConstFilename(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := makeTestOutput(t, map[string]interface{}{
+		"path":     dir,
+		"compress": true,
+	})
+	defer out.Close()
+
+	batch := outest.NewBatch(beat.Event{Timestamp: time.Now(), Fields: common.MapStr{"message": "test"}})
+	if err := out.Publish(batch); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"test"}, dirContents(t, dir))
+}
+
+func TestCloseInactive(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fileout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	out := makeTestOutput(t, map[string]interface{}{
+		"path":           dir,
+		"filename":       "%{[name]}",
+		"close_inactive": "50ms",
+	})
+	defer out.Close()
+
+	publish := func(name string) {
+		batch := outest.NewBatch(beat.Event{Timestamp: time.Now(), Fields: common.MapStr{"name": name}})
+		if err := out.Publish(batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publish("a")
+	publish("b")
+	time.Sleep(100 * time.Millisecond)
+	publish("b")
+
+	files := out.(*fileOutput).files
+	assert.Len(t, files, 1)
+	assert.NotNil(t, files["b"])
+
+	// the file is appended to when reopened, no backup is created
+	publish("a")
+	assert.Len(t, files, 2)
+	assert.Equal(t, []string{"a", "b"}, dirContents(t, dir))
+
+	content, err := ioutil.ReadFile(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, 2, strings.Count(string(content), "\n"))
+}
+
+func TestConfigValidate(t *testing.T) {
+	cfg, err := common.NewConfigFrom(map[string]interface{}{
+		"rotate_interval": "10ms",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = makeFileout(beat.Info{Beat: "test"}, outputs.NewNilObserver(), cfg)
+	assert.Error(t, err)
+}
+
+func makeTestOutput(t *testing.T, settings map[string]interface{}) outputs.Client {
+	cfg, err := common.NewConfigFrom(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grp, err := makeFileout(beat.Info{Beat: "test"}, outputs.NewNilObserver(), cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return grp.Clients[0]
+}
+
+func dirContents(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var names []string
+	for _, info := range infos {
+		names = append(names, info.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func countLines(t *testing.T, path string) int {
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	n := 0
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		n++
+	}
+	return n
+}
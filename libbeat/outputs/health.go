@@ -0,0 +1,190 @@
+package outputs
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/libbeat/publisher"
+)
+
+// HealthState describes the health of an output.
+type HealthState uint8
+
+const (
+	// Healthy outputs did not report any errors since the last events have
+	// been ACKed.
+	Healthy HealthState = iota
+
+	// Degraded outputs reported errors since the last events have been ACKed,
+	// but have not been failing for longer than the failure threshold.
+	Degraded
+
+	// Failing outputs reported errors only for longer than the failure
+	// threshold.
+	Failing
+)
+
+var healthStateNames = map[HealthState]string{
+	Healthy:  "healthy",
+	Degraded: "degraded",
+	Failing:  "failing",
+}
+
+func (s HealthState) String() string {
+	if name, ok := healthStateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// Health tracks the last success and errors of an output. Successes are
+// events being ACKed, errors are failed events, I/O errors and connection
+// failures.
+type Health struct {
+	mutex sync.Mutex
+	clock func() time.Time
+
+	lastSuccess   time.Time
+	lastError     error
+	lastErrorTime time.Time
+	failingSince  time.Time // first error after the last success
+}
+
+// HealthStatus is a snapshot of the output health.
+type HealthStatus struct {
+	State         HealthState
+	LastSuccess   time.Time
+	LastError     error
+	LastErrorTime time.Time
+	FailingSince  time.Time
+}
+
+var errEventsFailed = errors.New("failed to publish events")
+
+// NewHealth creates a new Health instance. The output is healthy until the
+// first error is reported.
+func NewHealth() *Health {
+	return &Health{clock: time.Now}
+}
+
+// Success records events being ACKed by the output.
+func (h *Health) Success() {
+	if h == nil {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	h.lastSuccess = h.clock()
+	h.failingSince = time.Time{}
+}
+
+// Failure records an error reported by the output.
+func (h *Health) Failure(err error) {
+	if h == nil {
+		return
+	}
+	if err == nil {
+		err = errEventsFailed
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	now := h.clock()
+	h.lastError = err
+	h.lastErrorTime = now
+	if h.failingSince.IsZero() {
+		h.failingSince = now
+	}
+}
+
+// Status returns the current health status. The output is failing, if only
+// errors have been reported for at least failureThreshold.
+func (h *Health) Status(failureThreshold time.Duration) HealthStatus {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	st := HealthStatus{
+		State:         Healthy,
+		LastSuccess:   h.lastSuccess,
+		LastError:     h.lastError,
+		LastErrorTime: h.lastErrorTime,
+		FailingSince:  h.failingSince,
+	}
+
+	if !h.failingSince.IsZero() {
+		st.State = Degraded
+		if h.clock().Sub(h.failingSince) >= failureThreshold {
+			st.State = Failing
+		}
+	}
+	return st
+}
+
+// healthClient reports connection and publish failures of a network client to
+// the output health.
+type healthClient struct {
+	NetworkClient
+	health *Health
+}
+
+// WithHealth wraps the network clients of an output group, reporting
+// connection and publish failures to h.
+func WithHealth(group Group, h *Health) Group {
+	if h == nil {
+		return group
+	}
+
+	clients := make([]Client, len(group.Clients))
+	for i, client := range group.Clients {
+		if nc, ok := client.(NetworkClient); ok {
+			client = &healthClient{NetworkClient: nc, health: h}
+		}
+		clients[i] = client
+	}
+	group.Clients = clients
+	return group
+}
+
+func (c *healthClient) Connect() error {
+	err := c.NetworkClient.Connect()
+	if err != nil {
+		c.health.Failure(err)
+	}
+	return err
+}
+
+func (c *healthClient) Publish(batch publisher.Batch) error {
+	err := c.NetworkClient.Publish(batch)
+	if err != nil {
+		c.health.Failure(err)
+	}
+	return err
+}
+
+var healthRegistry = struct {
+	mutex   sync.Mutex
+	outputs map[string]*Health
+}{outputs: map[string]*Health{}}
+
+// RegisterHealth registers the health of an output by name, for reporting the
+// health via the HTTP endpoint. The health of a previously registered output
+// with the same name is replaced.
+func RegisterHealth(name string, h *Health) {
+	healthRegistry.mutex.Lock()
+	defer healthRegistry.mutex.Unlock()
+	healthRegistry.outputs[name] = h
+}
+
+// HealthStatuses returns the health status of all registered outputs.
+func HealthStatuses(failureThreshold time.Duration) map[string]HealthStatus {
+	healthRegistry.mutex.Lock()
+	defer healthRegistry.mutex.Unlock()
+
+	statuses := make(map[string]HealthStatus, len(healthRegistry.outputs))
+	for name, h := range healthRegistry.outputs {
+		statuses[name] = h.Status(failureThreshold)
+	}
+	return statuses
+}
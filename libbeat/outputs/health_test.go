@@ -0,0 +1,109 @@
+// +build !integration
+
+package outputs
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/monitoring"
+	"github.com/elastic/beats/libbeat/publisher"
+)
+
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) get() time.Time          { return c.now }
+func (c *testClock) advance(d time.Duration) { c.now = c.now.Add(d) }
+
+func newTestHealth() (*Health, *testClock) {
+	clock := &testClock{now: time.Date(2018, 10, 1, 12, 0, 0, 0, time.UTC)}
+	h := NewHealth()
+	h.clock = clock.get
+	return h, clock
+}
+
+func TestHealthStates(t *testing.T) {
+	h, clock := newTestHealth()
+	threshold := time.Minute
+
+	st := h.Status(threshold)
+	assert.Equal(t, Healthy, st.State)
+	assert.True(t, st.LastSuccess.IsZero())
+
+	h.Success()
+	assert.Equal(t, Healthy, h.Status(threshold).State)
+
+	clock.advance(time.Second)
+	h.Failure(errors.New("connection refused"))
+	st = h.Status(threshold)
+	assert.Equal(t, Degraded, st.State)
+	assert.Equal(t, "connection refused", st.LastError.Error())
+	assert.Equal(t, clock.now, st.FailingSince)
+
+	// further errors do not reset the time the output is failing since
+	clock.advance(30 * time.Second)
+	h.Failure(nil)
+	assert.Equal(t, Degraded, h.Status(threshold).State)
+
+	clock.advance(30 * time.Second)
+	st = h.Status(threshold)
+	assert.Equal(t, Failing, st.State)
+	assert.Equal(t, errEventsFailed, st.LastError)
+
+	// a success recovers the output, keeping the last error
+	h.Success()
+	st = h.Status(threshold)
+	assert.Equal(t, Healthy, st.State)
+	assert.Equal(t, clock.now, st.LastSuccess)
+	assert.Equal(t, errEventsFailed, st.LastError)
+}
+
+func TestStatsReportHealth(t *testing.T) {
+	stats := NewStats(monitoring.NewRegistry())
+	h := stats.Health()
+
+	stats.WriteError(errors.New("broken pipe"))
+	assert.Equal(t, Degraded, h.Status(time.Minute).State)
+
+	stats.Acked(0)
+	assert.Equal(t, Degraded, h.Status(time.Minute).State)
+
+	stats.Acked(10)
+	assert.Equal(t, Healthy, h.Status(time.Minute).State)
+
+	stats.Failed(1)
+	assert.Equal(t, Degraded, h.Status(time.Minute).State)
+
+	var nilStats *Stats
+	assert.Nil(t, nilStats.Health())
+}
+
+type failingClient struct {
+	err error
+}
+
+func (c *failingClient) Connect() error                { return c.err }
+func (c *failingClient) Close() error                  { return nil }
+func (c *failingClient) Publish(publisher.Batch) error { return c.err }
+
+func TestWithHealthReportsConnectionFailures(t *testing.T) {
+	h, _ := newTestHealth()
+	group := WithHealth(Group{
+		Clients: []Client{&failingClient{err: errors.New("connection refused")}},
+	}, h)
+
+	client, ok := group.Clients[0].(NetworkClient)
+	if !ok {
+		t.Fatal("wrapped client is no network client")
+	}
+
+	assert.Error(t, client.Connect())
+	st := h.Status(time.Hour)
+	assert.Equal(t, Degraded, st.State)
+	assert.Equal(t, "connection refused", st.LastError.Error())
+}
@@ -0,0 +1,345 @@
+package httpout
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+	"github.com/elastic/beats/libbeat/outputs/transport"
+	"github.com/elastic/beats/libbeat/publisher"
+)
+
+type client struct {
+	url         string
+	method      string
+	headers     map[string]string
+	username    string
+	password    string
+	bearerToken string
+	format      batchFormat
+
+	index    string
+	codec    codec.Codec
+	observer outputs.Observer
+
+	compressionLevel int
+	buf              bytes.Buffer
+	gzip             *gzip.Writer
+
+	http *http.Client
+}
+
+type clientSettings struct {
+	URL                string
+	Index              string
+	Method             string
+	Headers            map[string]string
+	Username, Password string
+	BearerToken        string
+	Format             batchFormat
+	CompressionLevel   int
+	TLS                *transport.TLSConfig
+	Proxy              *transport.ProxyConfig
+	Timeout            time.Duration
+	Codec              codec.Codec
+	Observer           outputs.Observer
+}
+
+// sendResult classifies the response of a request.
+type sendResult uint8
+
+const (
+	resultOK    sendResult = iota // events have been accepted
+	resultRetry                   // request failed with temporary error, events must be retried
+	resultDrop                    // events have been rejected, retrying would fail again
+)
+
+var errTempFailure = errors.New("temporary http send failure")
+
+func newClient(s clientSettings) (*client, error) {
+	dialer := transport.NetDialer(s.Timeout)
+	dialer, err := transport.ProxyDialer(s.Proxy, dialer)
+	if err != nil {
+		return nil, err
+	}
+
+	tlsDialer, err := transport.TLSDialer(dialer, s.TLS, s.Timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if st := s.Observer; st != nil {
+		dialer = transport.StatsDialer(dialer, st)
+		tlsDialer = transport.StatsDialer(tlsDialer, st)
+	}
+
+	// use HTTP proxy from environment, unless a SOCKS5 proxy is configured
+	proxy := http.ProxyFromEnvironment
+	if s.Proxy != nil && s.Proxy.URL != "" {
+		proxy = nil
+	}
+
+	observer := s.Observer
+	if observer == nil {
+		observer = outputs.NewNilObserver()
+	}
+
+	c := &client{
+		url:              s.URL,
+		method:           strings.ToUpper(s.Method),
+		headers:          s.Headers,
+		username:         s.Username,
+		password:         s.Password,
+		bearerToken:      s.BearerToken,
+		format:           s.Format,
+		index:            s.Index,
+		codec:            s.Codec,
+		observer:         observer,
+		compressionLevel: s.CompressionLevel,
+		http: &http.Client{
+			Transport: &http.Transport{
+				Dial:    dialer.Dial,
+				DialTLS: tlsDialer.Dial,
+				Proxy:   proxy,
+			},
+			Timeout: s.Timeout,
+		},
+	}
+
+	if c.compressionLevel > 0 {
+		c.gzip, err = gzip.NewWriterLevel(&c.buf, c.compressionLevel)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return c, nil
+}
+
+func (c *client) Connect() error {
+	return nil
+}
+
+func (c *client) Close() error {
+	if t, ok := c.http.Transport.(*http.Transport); ok {
+		t.CloseIdleConnections()
+	}
+	return nil
+}
+
+func (c *client) String() string {
+	return "http(" + c.url + ")"
+}
+
+func (c *client) Publish(batch publisher.Batch) error {
+	events := batch.Events()
+	rest, err := c.publishEvents(events)
+	if len(rest) == 0 {
+		batch.ACK()
+	} else {
+		batch.RetryEvents(rest)
+	}
+	return err
+}
+
+// publishEvents sends all events to the configured URL. Events not
+// accepted due to a temporary error are returned for retrying.
+func (c *client) publishEvents(data []publisher.Event) ([]publisher.Event, error) {
+	st := c.observer
+	st.NewBatch(len(data))
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	if c.format == formatSingle {
+		return c.publishSingle(data)
+	}
+
+	origCount := len(data)
+	data, err := c.encodeBatch(data)
+	if err != nil {
+		st.Failed(len(data))
+		return data, err
+	}
+	if dropped := origCount - len(data); dropped > 0 {
+		st.Dropped(dropped)
+	}
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	result, err := c.send()
+	switch result {
+	case resultRetry:
+		st.Failed(len(data))
+		return data, err
+	case resultDrop:
+		st.Dropped(len(data))
+		logp.Err("Dropping %v events, rejected by %v: %v", len(data), c.url, err)
+	default:
+		st.Acked(len(data))
+	}
+	return nil, nil
+}
+
+// publishSingle sends one request per event. The remaining events are
+// returned for retrying once a request fails with a temporary error.
+func (c *client) publishSingle(data []publisher.Event) ([]publisher.Event, error) {
+	st := c.observer
+
+	for i := range data {
+		serialized, ok := c.encodeEvent(&data[i])
+		if !ok {
+			st.Dropped(1)
+			continue
+		}
+
+		c.resetBuffer()
+		c.writer().Write(serialized)
+		if err := c.closeBuffer(); err != nil {
+			return data[i:], err
+		}
+
+		result, err := c.send()
+		switch result {
+		case resultRetry:
+			st.Failed(len(data) - i)
+			return data[i:], err
+		case resultDrop:
+			st.Dropped(1)
+			logp.Err("Dropping event, rejected by %v: %v", c.url, err)
+		default:
+			st.Acked(1)
+		}
+	}
+	return nil, nil
+}
+
+// encodeBatch encodes all events into the request buffer. Events failing to
+// be encoded are dropped.
+func (c *client) encodeBatch(data []publisher.Event) ([]publisher.Event, error) {
+	c.resetBuffer()
+
+	w := c.writer()
+	okEvents := data[:0]
+	if c.format == formatArray {
+		w.Write([]byte{'['})
+	}
+	for i := range data {
+		serialized, ok := c.encodeEvent(&data[i])
+		if !ok {
+			continue
+		}
+
+		if c.format == formatArray && len(okEvents) > 0 {
+			w.Write([]byte{','})
+		}
+		w.Write(serialized)
+		if c.format == formatNDJSON {
+			w.Write([]byte{'\n'})
+		}
+		okEvents = append(okEvents, data[i])
+	}
+	if c.format == formatArray {
+		w.Write([]byte{']'})
+	}
+
+	err := c.closeBuffer()
+	return okEvents, err
+}
+
+// encodeEvent serializes an event using the configured codec. The returned
+// buffer is only valid until the next event is encoded.
+func (c *client) encodeEvent(event *publisher.Event) ([]byte, bool) {
+	serialized, err := c.codec.Encode(c.index, &event.Content)
+	if err != nil {
+		if event.Guaranteed() {
+			logp.Critical("Failed to serialize the event: %v", err)
+		} else {
+			logp.Warn("Failed to serialize the event: %v", err)
+		}
+		return nil, false
+	}
+	return serialized, true
+}
+
+func (c *client) writer() io.Writer {
+	if c.gzip != nil {
+		return c.gzip
+	}
+	return &c.buf
+}
+
+func (c *client) resetBuffer() {
+	c.buf.Reset()
+	if c.gzip != nil {
+		c.gzip.Reset(&c.buf)
+	}
+}
+
+func (c *client) closeBuffer() error {
+	if c.gzip != nil {
+		return c.gzip.Close()
+	}
+	return nil
+}
+
+// send sends the request buffer and classifies the response. 429 and 5xx
+// responses are retried, other non 2xx responses are not.
+func (c *client) send() (sendResult, error) {
+	req, err := http.NewRequest(c.method, c.url, bytes.NewReader(c.buf.Bytes()))
+	if err != nil {
+		return resultDrop, err
+	}
+
+	req.Header.Set("Content-Type", c.format.contentType())
+	if c.gzip != nil {
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+	if c.username != "" || c.password != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+	if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+	for name, value := range c.headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		c.observer.WriteError(err)
+		logp.Err("Failed to send events to %v: %v", c.url, err)
+		return resultRetry, err
+	}
+	defer resp.Body.Close()
+	c.observer.WriteBytes(c.buf.Len())
+
+	// read response, so the connection can be reused
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+	if err != nil {
+		c.observer.ReadError(err)
+	}
+	c.observer.ReadBytes(len(body))
+
+	status := resp.StatusCode
+	debugf("Request to %v returned status %v", c.url, status)
+	switch {
+	case status >= 200 && status < 300:
+		return resultOK, nil
+	case status == http.StatusTooManyRequests || status >= 500:
+		logp.Warn("Failed to send events to %v (status=%v): %s", c.url, status, body)
+		return resultRetry, errTempFailure
+	default:
+		return resultDrop, fmt.Errorf("status=%v: %s", status, body)
+	}
+}
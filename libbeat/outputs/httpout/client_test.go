@@ -0,0 +1,309 @@
+package httpout
+
+import (
+	"compress/gzip"
+	stdjson "encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs/codec/json"
+	"github.com/elastic/beats/libbeat/outputs/outest"
+)
+
+type testRequest struct {
+	header http.Header
+	body   string
+}
+
+type testServer struct {
+	*httptest.Server
+
+	mu       sync.Mutex
+	requests []testRequest
+	statuses []int // status codes returned, in order. Defaults to 200
+}
+
+func newTestServer(t *testing.T, statuses ...int) *testServer {
+	s := &testServer{statuses: statuses}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		var err error
+		if r.Header.Get("Content-Encoding") == "gzip" {
+			var reader *gzip.Reader
+			reader, err = gzip.NewReader(r.Body)
+			if err == nil {
+				body, err = ioutil.ReadAll(reader)
+			}
+		} else {
+			body, err = ioutil.ReadAll(r.Body)
+		}
+		if err != nil {
+			t.Error(err)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		s.requests = append(s.requests, testRequest{header: r.Header, body: string(body)})
+
+		status := http.StatusOK
+		if len(s.statuses) > 0 {
+			status, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	return s
+}
+
+func newTestClient(t *testing.T, url string, modify func(*clientSettings)) *client {
+	settings := clientSettings{
+		URL:     url,
+		Index:   "testbeat",
+		Method:  http.MethodPost,
+		Timeout: 5 * time.Second,
+		Codec:   json.New(false, "1.2.3"),
+	}
+	if modify != nil {
+		modify(&settings)
+	}
+
+	c, err := newClient(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func testEvents(n int) []beat.Event {
+	events := make([]beat.Event, n)
+	for i := range events {
+		events[i] = beat.Event{
+			Timestamp: time.Date(2018, 6, 1, 0, 0, 0, 0, time.UTC),
+			Fields:    common.MapStr{"message": "test", "i": i},
+		}
+	}
+	return events
+}
+
+func decodeMessages(t *testing.T, lines []string) []map[string]interface{} {
+	var docs []map[string]interface{}
+	for _, line := range lines {
+		var doc map[string]interface{}
+		if err := stdjson.Unmarshal([]byte(line), &doc); err != nil {
+			t.Fatalf("invalid JSON document '%v': %v", line, err)
+		}
+		docs = append(docs, doc)
+	}
+	return docs
+}
+
+func TestPublishArray(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, nil)
+	batch := outest.NewBatch(testEvents(3)...)
+	assert.NoError(t, client.Publish(batch))
+
+	assert.Equal(t, []outest.BatchSignal{{Tag: outest.BatchACK}}, batch.Signals)
+	if assert.Len(t, server.requests, 1) {
+		req := server.requests[0]
+		assert.Equal(t, "application/json; charset=UTF-8", req.header.Get("Content-Type"))
+
+		var docs []map[string]interface{}
+		if err := stdjson.Unmarshal([]byte(req.body), &docs); err != nil {
+			t.Fatal(err)
+		}
+		assert.Len(t, docs, 3)
+		assert.Equal(t, float64(2), docs[2]["i"])
+	}
+}
+
+func TestPublishNDJSON(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, func(s *clientSettings) {
+		s.Format = formatNDJSON
+	})
+	batch := outest.NewBatch(testEvents(3)...)
+	assert.NoError(t, client.Publish(batch))
+
+	assert.Equal(t, []outest.BatchSignal{{Tag: outest.BatchACK}}, batch.Signals)
+	if assert.Len(t, server.requests, 1) {
+		req := server.requests[0]
+		assert.Equal(t, "application/x-ndjson", req.header.Get("Content-Type"))
+
+		lines := strings.Split(strings.TrimSuffix(req.body, "\n"), "\n")
+		docs := decodeMessages(t, lines)
+		assert.Len(t, docs, 3)
+	}
+}
+
+func TestPublishSingle(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, func(s *clientSettings) {
+		s.Format = formatSingle
+	})
+	batch := outest.NewBatch(testEvents(3)...)
+	assert.NoError(t, client.Publish(batch))
+
+	assert.Equal(t, []outest.BatchSignal{{Tag: outest.BatchACK}}, batch.Signals)
+	if assert.Len(t, server.requests, 3) {
+		for i, req := range server.requests {
+			docs := decodeMessages(t, []string{req.body})
+			assert.Equal(t, float64(i), docs[0]["i"])
+		}
+	}
+}
+
+func TestPublishGzipAndHeaders(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, func(s *clientSettings) {
+		s.CompressionLevel = 5
+		s.BearerToken = "secret"
+		s.Headers = map[string]string{"X-Test": "value"}
+	})
+	batch := outest.NewBatch(testEvents(2)...)
+	assert.NoError(t, client.Publish(batch))
+
+	if assert.Len(t, server.requests, 1) {
+		req := server.requests[0]
+		assert.Equal(t, "gzip", req.header.Get("Content-Encoding"))
+		assert.Equal(t, "Bearer secret", req.header.Get("Authorization"))
+		assert.Equal(t, "value", req.header.Get("X-Test"))
+
+		var docs []map[string]interface{}
+		assert.NoError(t, stdjson.Unmarshal([]byte(req.body), &docs))
+		assert.Len(t, docs, 2)
+	}
+}
+
+func TestPublishBasicAuth(t *testing.T) {
+	server := newTestServer(t)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, func(s *clientSettings) {
+		s.Username = "user"
+		s.Password = "pass"
+	})
+	assert.NoError(t, client.Publish(outest.NewBatch(testEvents(1)...)))
+
+	if assert.Len(t, server.requests, 1) {
+		req := &http.Request{Header: server.requests[0].header}
+		user, pass, ok := req.BasicAuth()
+		assert.True(t, ok)
+		assert.Equal(t, "user", user)
+		assert.Equal(t, "pass", pass)
+	}
+}
+
+func TestPublishRetryableStatus(t *testing.T) {
+	for _, status := range []int{http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusServiceUnavailable} {
+		server := newTestServer(t, status)
+
+		client := newTestClient(t, server.URL, nil)
+		batch := outest.NewBatch(testEvents(3)...)
+		assert.Error(t, client.Publish(batch), "status %v", status)
+
+		if assert.Len(t, batch.Signals, 1) {
+			assert.Equal(t, outest.BatchRetryEvents, batch.Signals[0].Tag)
+			assert.Len(t, batch.Signals[0].Events, 3)
+		}
+		server.Close()
+	}
+}
+
+func TestPublishRejected(t *testing.T) {
+	server := newTestServer(t, http.StatusBadRequest)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, nil)
+	batch := outest.NewBatch(testEvents(3)...)
+	assert.NoError(t, client.Publish(batch))
+
+	// rejected events are dropped
+	assert.Equal(t, []outest.BatchSignal{{Tag: outest.BatchACK}}, batch.Signals)
+}
+
+func TestPublishSingleRetryRemaining(t *testing.T) {
+	server := newTestServer(t, http.StatusOK, http.StatusBadRequest, http.StatusServiceUnavailable)
+	defer server.Close()
+
+	client := newTestClient(t, server.URL, func(s *clientSettings) {
+		s.Format = formatSingle
+	})
+	batch := outest.NewBatch(testEvents(4)...)
+	assert.Error(t, client.Publish(batch))
+
+	if assert.Len(t, batch.Signals, 1) {
+		sig := batch.Signals[0]
+		assert.Equal(t, outest.BatchRetryEvents, sig.Tag)
+		if assert.Len(t, sig.Events, 2) {
+			assert.Equal(t, 2, sig.Events[0].Content.Fields["i"])
+		}
+	}
+}
+
+func TestPublishConnectionError(t *testing.T) {
+	server := newTestServer(t)
+	url := server.URL
+	server.Close()
+
+	client := newTestClient(t, url, nil)
+	batch := outest.NewBatch(testEvents(2)...)
+	assert.Error(t, client.Publish(batch))
+
+	if assert.Len(t, batch.Signals, 1) {
+		assert.Equal(t, outest.BatchRetryEvents, batch.Signals[0].Tag)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		config map[string]interface{}
+		ok     bool
+	}{
+		"defaults":        {map[string]interface{}{}, true},
+		"ndjson":          {map[string]interface{}{"batch_format": "ndjson"}, true},
+		"invalid format":  {map[string]interface{}{"batch_format": "xml"}, false},
+		"put":             {map[string]interface{}{"method": "put"}, true},
+		"invalid method":  {map[string]interface{}{"method": "GET"}, false},
+		"basic and token": {map[string]interface{}{"username": "u", "bearer_token": "t"}, false},
+		"compression":     {map[string]interface{}{"compression_level": 10}, false},
+	}
+
+	for name, test := range tests {
+		cfg, err := common.NewConfigFrom(test.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		config := defaultConfig
+		err = cfg.Unpack(&config)
+		if test.ok {
+			assert.NoError(t, err, name)
+		} else {
+			assert.Error(t, err, name)
+		}
+	}
+}
+
+func TestDefaultPort(t *testing.T) {
+	assert.Equal(t, 80, defaultPort("", "localhost"))
+	assert.Equal(t, 443, defaultPort("https", "localhost"))
+	assert.Equal(t, 443, defaultPort("", "https://localhost"))
+	assert.Equal(t, 80, defaultPort("https", "http://localhost"))
+}
@@ -0,0 +1,108 @@
+package httpout
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+	"github.com/elastic/beats/libbeat/outputs/transport"
+)
+
+type httpConfig struct {
+	Protocol         string                `config:"protocol"`
+	Path             string                `config:"path"`
+	Method           string                `config:"method"`
+	Headers          map[string]string     `config:"headers"`
+	Username         string                `config:"username"`
+	Password         string                `config:"password"`
+	BearerToken      string                `config:"bearer_token"`
+	BatchFormat      batchFormat           `config:"batch_format"`
+	CompressionLevel int                   `config:"compression_level" validate:"min=0, max=9"`
+	LoadBalance      bool                  `config:"loadbalance"`
+	TLS              *outputs.TLSConfig    `config:"ssl"`
+	Proxy            transport.ProxyConfig `config:",inline"`
+	Codec            codec.Config          `config:"codec"`
+	BulkMaxSize      int                   `config:"bulk_max_size"`
+	MaxRetries       int                   `config:"max_retries"`
+	Timeout          time.Duration         `config:"timeout"`
+	Backoff          backoff               `config:"backoff"`
+}
+
+type backoff struct {
+	Init time.Duration
+	Max  time.Duration
+}
+
+// batchFormat configures how a batch of events is sent.
+type batchFormat uint8
+
+const (
+	// formatArray sends all events in a batch as JSON array.
+	formatArray batchFormat = iota
+
+	// formatNDJSON sends all events in a batch, one event per line.
+	formatNDJSON
+
+	// formatSingle sends one request per event.
+	formatSingle
+)
+
+var batchFormats = map[string]batchFormat{
+	"array":  formatArray,
+	"ndjson": formatNDJSON,
+	"single": formatSingle,
+}
+
+const defaultBulkSize = 50
+
+var (
+	defaultConfig = httpConfig{
+		Method:           http.MethodPost,
+		BatchFormat:      formatArray,
+		CompressionLevel: 0,
+		LoadBalance:      true,
+		TLS:              nil,
+		BulkMaxSize:      defaultBulkSize,
+		MaxRetries:       3,
+		Timeout:          90 * time.Second,
+		Backoff: backoff{
+			Init: 1 * time.Second,
+			Max:  60 * time.Second,
+		},
+	}
+)
+
+func (c *httpConfig) Validate() error {
+	switch strings.ToUpper(c.Method) {
+	case http.MethodPost, http.MethodPut:
+	default:
+		return fmt.Errorf("http method '%v' not supported", c.Method)
+	}
+
+	if c.BearerToken != "" && c.Username != "" {
+		return errors.New("username and bearer_token can not be used at the same time")
+	}
+
+	return nil
+}
+
+func (f *batchFormat) Unpack(value string) error {
+	format, exists := batchFormats[strings.ToLower(value)]
+	if !exists {
+		return fmt.Errorf("batch_format '%v' not supported", value)
+	}
+
+	*f = format
+	return nil
+}
+
+func (f batchFormat) contentType() string {
+	if f == formatNDJSON {
+		return "application/x-ndjson"
+	}
+	return "application/json; charset=UTF-8"
+}
@@ -0,0 +1,97 @@
+package httpout
+
+import (
+	"strings"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+)
+
+func init() {
+	outputs.RegisterType("http", makeHTTP)
+}
+
+var debugf = logp.MakeDebug("http")
+
+const (
+	defaultHTTPPort  = 80
+	defaultHTTPSPort = 443
+)
+
+func makeHTTP(
+	beat beat.Info,
+	observer outputs.Observer,
+	cfg *common.Config,
+) (outputs.Group, error) {
+	config := defaultConfig
+	if err := cfg.Unpack(&config); err != nil {
+		return outputs.Fail(err)
+	}
+
+	hosts, err := outputs.ReadHostList(cfg)
+	if err != nil {
+		return outputs.Fail(err)
+	}
+
+	tls, err := outputs.LoadTLSConfig(config.TLS)
+	if err != nil {
+		return outputs.Fail(err)
+	}
+
+	// disable bulk support in publisher pipeline, if events are send one by one
+	batchSize := config.BulkMaxSize
+	if config.BatchFormat == formatSingle && !cfg.HasField("bulk_max_size") {
+		batchSize = 1
+	}
+
+	clients := make([]outputs.NetworkClient, len(hosts))
+	for i, host := range hosts {
+		url, err := common.MakeURL(config.Protocol, config.Path, host, defaultPort(config.Protocol, host))
+		if err != nil {
+			logp.Err("Invalid host param set: %s, Error: %v", host, err)
+			return outputs.Fail(err)
+		}
+
+		enc, err := codec.CreateEncoder(beat, config.Codec)
+		if err != nil {
+			return outputs.Fail(err)
+		}
+
+		var client outputs.NetworkClient
+		client, err = newClient(clientSettings{
+			URL:              url,
+			Index:            beat.Beat,
+			Method:           config.Method,
+			Headers:          config.Headers,
+			Username:         config.Username,
+			Password:         config.Password,
+			BearerToken:      config.BearerToken,
+			Format:           config.BatchFormat,
+			CompressionLevel: config.CompressionLevel,
+			TLS:              tls,
+			Proxy:            &config.Proxy,
+			Timeout:          config.Timeout,
+			Codec:            enc,
+			Observer:         observer,
+		})
+		if err != nil {
+			return outputs.Fail(err)
+		}
+
+		client = outputs.WithBackoff(client, config.Backoff.Init, config.Backoff.Max)
+		clients[i] = client
+	}
+
+	return outputs.SuccessNet(config.LoadBalance, batchSize, config.MaxRetries, clients)
+}
+
+func defaultPort(protocol, host string) int {
+	if strings.HasPrefix(strings.ToLower(host), "https://") ||
+		(!strings.Contains(host, "://") && strings.ToLower(protocol) == "https") {
+		return defaultHTTPSPort
+	}
+	return defaultHTTPPort
+}
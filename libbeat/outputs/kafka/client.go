@@ -8,6 +8,7 @@ import (
 
 	"github.com/Shopify/sarama"
 
+	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/common/fmtstr"
 	"github.com/elastic/beats/libbeat/logp"
 	"github.com/elastic/beats/libbeat/outputs"
@@ -21,6 +22,7 @@ type client struct {
 	hosts    []string
 	topic    outil.Selector
 	key      *fmtstr.EventFormatString
+	headers  []headerConfig
 	index    string
 	codec    codec.Codec
 	config   sarama.Config
@@ -50,6 +52,7 @@ func newKafkaClient(
 	index string,
 	key *fmtstr.EventFormatString,
 	topic outil.Selector,
+	headers []headerConfig,
 	writer codec.Codec,
 	cfg *sarama.Config,
 ) (*client, error) {
@@ -58,6 +61,7 @@ func newKafkaClient(
 		hosts:    hosts,
 		topic:    topic,
 		key:      key,
+		headers:  headers,
 		index:    index,
 		codec:    writer,
 		config:   *cfg,
@@ -175,9 +179,33 @@ func (c *client) getEventMessage(data *publisher.Event) (*message, error) {
 		}
 	}
 
+	msg.headers = c.getEventHeaders(event)
+
 	return msg, nil
 }
 
+// getEventHeaders formats the configured record headers. Headers whose value
+// can not be formatted for the event are not added.
+func (c *client) getEventHeaders(event *beat.Event) []sarama.RecordHeader {
+	if len(c.headers) == 0 {
+		return nil
+	}
+
+	headers := make([]sarama.RecordHeader, 0, len(c.headers))
+	for _, h := range c.headers {
+		value, err := h.Value.RunBytes(event)
+		if err != nil {
+			debugf("Skipping header '%v': %v", h.Key, err)
+			continue
+		}
+		headers = append(headers, sarama.RecordHeader{
+			Key:   []byte(h.Key),
+			Value: value,
+		})
+	}
+	return headers
+}
+
 func (c *client) successWorker(ch <-chan *sarama.ProducerMessage) {
 	defer c.wg.Done()
 	defer debugf("Stop kafka ack worker")
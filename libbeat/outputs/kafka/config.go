@@ -6,8 +6,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/Shopify/sarama"
+
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/common/fmtstr"
+	libkafka "github.com/elastic/beats/libbeat/common/kafka"
 	"github.com/elastic/beats/libbeat/outputs"
 	"github.com/elastic/beats/libbeat/outputs/codec"
 )
@@ -31,9 +34,17 @@ type kafkaConfig struct {
 	ChanBufferSize  int                       `config:"channel_buffer_size" validate:"min=1"`
 	Username        string                    `config:"username"`
 	Password        string                    `config:"password"`
+	Sasl            libkafka.SaslConfig       `config:"sasl"`
+	Idempotent      bool                      `config:"idempotent"`
+	Headers         []headerConfig            `config:"headers"`
 	Codec           codec.Config              `config:"codec"`
 }
 
+type headerConfig struct {
+	Key   string                    `config:"key"   validate:"required"`
+	Value *fmtstr.EventFormatString `config:"value" validate:"required"`
+}
+
 type metaConfig struct {
 	Retry       metaRetryConfig `config:"retry"`
 	RefreshFreq time.Duration   `config:"refresh_frequency" validate:"min=0"`
@@ -80,12 +91,26 @@ func (c *kafkaConfig) Validate() error {
 		return fmt.Errorf("compression mode '%v' unknown", c.Compression)
 	}
 
-	if _, ok := kafkaVersions[c.Version]; !ok {
+	version, ok := libkafka.Version(c.Version)
+	if !ok {
 		return fmt.Errorf("unknown/unsupported kafka version '%v'", c.Version)
 	}
 
-	if c.Username != "" && c.Password == "" {
-		return fmt.Errorf("password must be set when username is configured")
+	if err := libkafka.ValidateAuth(c.Username, c.Password, c.Sasl); err != nil {
+		return err
+	}
+
+	if c.Idempotent {
+		if !version.IsAtLeast(sarama.V0_11_0_0) {
+			return errors.New("idempotent producer requires version 0.11 or newer")
+		}
+		if c.RequiredACKs != nil && *c.RequiredACKs != int(sarama.WaitForAll) {
+			return errors.New("idempotent producer requires required_acks to be -1")
+		}
+	}
+
+	if len(c.Headers) > 0 && !version.IsAtLeast(sarama.V0_11_0_0) {
+		return errors.New("headers require version 0.11 or newer")
 	}
 
 	return nil
@@ -0,0 +1,152 @@
+package kafka
+
+import (
+	"testing"
+
+	"github.com/Shopify/sarama"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/fmtstr"
+)
+
+func readConfig(t *testing.T, settings map[string]interface{}) (kafkaConfig, error) {
+	cfg, err := common.NewConfigFrom(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := defaultConfig
+	err = cfg.Unpack(&config)
+	return config, err
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		settings map[string]interface{}
+		ok       bool
+	}{
+		"default": {
+			settings: map[string]interface{}{},
+			ok:       true,
+		},
+		"scram": {
+			settings: map[string]interface{}{
+				"username":       "user",
+				"password":       "secret",
+				"sasl.mechanism": "scram-sha-512",
+			},
+			ok: true,
+		},
+		"unknown sasl mechanism": {
+			settings: map[string]interface{}{
+				"username":       "user",
+				"password":       "secret",
+				"sasl.mechanism": "GSSAPI",
+			},
+		},
+		"sasl mechanism without username": {
+			settings: map[string]interface{}{
+				"sasl.mechanism": "PLAIN",
+			},
+		},
+		"idempotent": {
+			settings: map[string]interface{}{
+				"version":    "1.0.0",
+				"idempotent": true,
+			},
+			ok: true,
+		},
+		"idempotent with old version": {
+			settings: map[string]interface{}{
+				"version":    "0.10.2.0",
+				"idempotent": true,
+			},
+		},
+		"idempotent without required_acks -1": {
+			settings: map[string]interface{}{
+				"version":       "1.0.0",
+				"idempotent":    true,
+				"required_acks": 1,
+			},
+		},
+		"headers": {
+			settings: map[string]interface{}{
+				"version": "0.11.0.0",
+				"headers": []map[string]interface{}{
+					{"key": "host", "value": "%{[beat.hostname]}"},
+				},
+			},
+			ok: true,
+		},
+		"headers with old version": {
+			settings: map[string]interface{}{
+				"version": "0.10.2.0",
+				"headers": []map[string]interface{}{
+					{"key": "host", "value": "%{[beat.hostname]}"},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		test.settings["hosts"] = []string{"localhost:9092"}
+		_, err := readConfig(t, test.settings)
+		if test.ok {
+			assert.NoError(t, err, name)
+		} else {
+			assert.Error(t, err, name)
+		}
+	}
+}
+
+func TestNewKafkaConfig(t *testing.T) {
+	config, err := readConfig(t, map[string]interface{}{
+		"hosts":          []string{"localhost:9092"},
+		"version":        "2.0.0",
+		"username":       "user",
+		"password":       "secret",
+		"sasl.mechanism": "SCRAM-SHA-256",
+		"idempotent":     true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	k, err := newKafkaConfig(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, sarama.V2_0_0_0, k.Version)
+	assert.Equal(t, sarama.SASLMechanism(sarama.SASLTypeSCRAMSHA256), k.Net.SASL.Mechanism)
+	if assert.NotNil(t, k.Net.SASL.SCRAMClientGeneratorFunc) {
+		assert.NotNil(t, k.Net.SASL.SCRAMClientGeneratorFunc())
+	}
+	assert.True(t, k.Producer.Idempotent)
+	assert.Equal(t, sarama.WaitForAll, k.Producer.RequiredAcks)
+	assert.Equal(t, 1, k.Net.MaxOpenRequests)
+}
+
+func TestEventHeaders(t *testing.T) {
+	c := &client{
+		headers: []headerConfig{
+			{Key: "host", Value: fmtstr.MustCompileEvent("%{[beat.hostname]}")},
+			{Key: "missing", Value: fmtstr.MustCompileEvent("%{[not.available]}")},
+			{Key: "static", Value: fmtstr.MustCompileEvent("value")},
+		},
+	}
+
+	event := &beat.Event{
+		Fields: common.MapStr{
+			"beat": common.MapStr{"hostname": "test-host"},
+		},
+	}
+
+	headers := c.getEventHeaders(event)
+	assert.Equal(t, []sarama.RecordHeader{
+		{Key: []byte("host"), Value: []byte("test-host")},
+		{Key: []byte("static"), Value: []byte("value")},
+	}, headers)
+}
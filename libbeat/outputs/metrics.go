@@ -25,6 +25,9 @@ type Stats struct {
 
 	readBytes  *monitoring.Uint // total amount of bytes read
 	readErrors *monitoring.Uint // total number of errors while waiting for response on output
+
+	health   *Health
+	registry *monitoring.Registry
 }
 
 // NewStats creates a new Stats instance using a backing monitoring registry.
@@ -45,7 +48,27 @@ func NewStats(reg *monitoring.Registry) *Stats {
 
 		readBytes:  monitoring.NewUint(reg, "read.bytes"),
 		readErrors: monitoring.NewUint(reg, "read.errors"),
+
+		health:   NewHealth(),
+		registry: reg,
+	}
+}
+
+// Registry returns the monitoring registry the stats are reported in. Outputs
+// can register additional metrics in this registry.
+func (s *Stats) Registry() *monitoring.Registry {
+	if s == nil {
+		return nil
+	}
+	return s.registry
+}
+
+// Health returns the health tracker updated by the stats.
+func (s *Stats) Health() *Health {
+	if s == nil {
+		return nil
 	}
+	return s.health
 }
 
 // NewBatch updates active batch and event metrics.
@@ -62,6 +85,9 @@ func (s *Stats) Acked(n int) {
 	if s != nil {
 		s.acked.Add(uint64(n))
 		s.active.Sub(uint64(n))
+		if n > 0 {
+			s.health.Success()
+		}
 	}
 }
 
@@ -70,6 +96,9 @@ func (s *Stats) Failed(n int) {
 	if s != nil {
 		s.failed.Add(uint64(n))
 		s.active.Sub(uint64(n))
+		if n > 0 {
+			s.health.Failure(nil)
+		}
 	}
 }
 
@@ -104,6 +133,7 @@ func (s *Stats) Cancelled(n int) {
 func (s *Stats) WriteError(err error) {
 	if s != nil {
 		s.writeErrors.Inc()
+		s.health.Failure(err)
 	}
 }
 
@@ -118,6 +148,7 @@ func (s *Stats) WriteBytes(n int) {
 func (s *Stats) ReadError(err error) {
 	if s != nil {
 		s.readErrors.Inc()
+		s.health.Failure(err)
 	}
 }
 
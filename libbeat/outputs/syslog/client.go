@@ -0,0 +1,109 @@
+package syslog
+
+import (
+	"time"
+
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/transport"
+	"github.com/elastic/beats/libbeat/publisher"
+)
+
+// maxDatagramSize is the maximum payload of an UDP datagram. Bigger messages
+// are truncated.
+const maxDatagramSize = 65507
+
+type client struct {
+	*transport.Client
+	observer outputs.Observer
+	timeout  time.Duration
+	format   *formatter
+	stream   bool
+}
+
+func newClient(
+	tc *transport.Client,
+	observer outputs.Observer,
+	timeout time.Duration,
+	format *formatter,
+	stream bool,
+) *client {
+	if observer == nil {
+		observer = outputs.NewNilObserver()
+	}
+
+	return &client{
+		Client:   tc,
+		observer: observer,
+		timeout:  timeout,
+		format:   format,
+		stream:   stream,
+	}
+}
+
+func (c *client) Connect() error {
+	debugf("connect to %v", c.Host())
+	return c.Client.Connect()
+}
+
+func (c *client) Publish(batch publisher.Batch) error {
+	events := batch.Events()
+	rest, err := c.publishEvents(events)
+	if len(rest) == 0 {
+		batch.ACK()
+	} else {
+		batch.RetryEvents(rest)
+	}
+	return err
+}
+
+// publishEvents writes one syslog message per event. On write errors the
+// remaining events are returned, so they can be retried once the connection
+// has been reestablished.
+func (c *client) publishEvents(data []publisher.Event) ([]publisher.Event, error) {
+	st := c.observer
+	st.NewBatch(len(data))
+
+	if c.timeout > 0 {
+		if err := c.SetWriteDeadline(time.Now().Add(c.timeout)); err != nil {
+			st.Failed(len(data))
+			return data, err
+		}
+	}
+
+	dropped := 0
+	for i := range data {
+		event := &data[i]
+
+		msg, err := c.format.Format(&event.Content)
+		if err != nil {
+			if event.Guaranteed() {
+				logp.Critical("Failed to format the syslog message: %v", err)
+			} else {
+				logp.Warn("Failed to format the syslog message: %v", err)
+			}
+			dropped++
+			continue
+		}
+
+		if !c.stream && len(msg) > maxDatagramSize {
+			msg = msg[:maxDatagramSize]
+		}
+
+		n, err := c.Write(msg)
+		if err != nil {
+			st.WriteError(err)
+			logp.Err("Failed to send syslog message to %v: %v", c.Host(), err)
+
+			st.Dropped(dropped)
+			st.Acked(i - dropped)
+			st.Failed(len(data) - i)
+			return data[i:], err
+		}
+		st.WriteBytes(n)
+	}
+
+	st.Dropped(dropped)
+	st.Acked(len(data) - dropped)
+	return nil, nil
+}
@@ -0,0 +1,175 @@
+package syslog
+
+import (
+	"bufio"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs/outest"
+	"github.com/elastic/beats/libbeat/outputs/transport"
+)
+
+func testBatch(messages ...string) *outest.Batch {
+	events := make([]beat.Event, len(messages))
+	for i, msg := range messages {
+		events[i] = beat.Event{Timestamp: testTime, Fields: common.MapStr{"message": msg}}
+	}
+	return outest.NewBatch(events...)
+}
+
+func TestPublishTCPOctetCounting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	received := make(chan []string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		var msgs []string
+		reader := bufio.NewReader(conn)
+		for len(msgs) < 2 {
+			lenStr, err := reader.ReadString(' ')
+			if err != nil {
+				break
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(lenStr))
+			if err != nil {
+				break
+			}
+			buf := make([]byte, n)
+			if _, err := io.ReadFull(reader, buf); err != nil {
+				break
+			}
+			msgs = append(msgs, string(buf))
+		}
+		received <- msgs
+	}()
+
+	conn, err := transport.NewClient(&transport.Config{Timeout: time.Second}, "tcp", listener.Addr().String(), defaultPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	f := testFormatter(func(f *formatter) { f.stream = true })
+	client := newClient(conn, nil, time.Second, f, true)
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	batch := testBatch("first", "second\nline")
+	assert.NoError(t, client.Publish(batch))
+	assert.Equal(t, []outest.BatchSignal{{Tag: outest.BatchACK}}, batch.Signals)
+
+	select {
+	case msgs := <-received:
+		assert.Equal(t, []string{
+			"<14>1 2018-06-01T12:30:45.123456Z myhost testbeat - - - first",
+			"<14>1 2018-06-01T12:30:45.123456Z myhost testbeat - - - second\nline",
+		}, msgs)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for messages")
+	}
+}
+
+func TestPublishUDP(t *testing.T) {
+	server, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	conn, err := transport.NewClient(&transport.Config{Timeout: time.Second}, "udp", server.LocalAddr().String(), defaultPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newClient(conn, nil, time.Second, testFormatter(nil), false)
+	if err := client.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	batch := testBatch("first", "second")
+	assert.NoError(t, client.Publish(batch))
+	assert.Equal(t, []outest.BatchSignal{{Tag: outest.BatchACK}}, batch.Signals)
+
+	server.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 1024)
+	for _, expected := range []string{"first", "second"} {
+		n, _, err := server.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, "<14>1 2018-06-01T12:30:45.123456Z myhost testbeat - - - "+expected, string(buf[:n]))
+	}
+}
+
+func TestPublishNotConnected(t *testing.T) {
+	conn, err := transport.NewClient(&transport.Config{Timeout: time.Second}, "tcp", "127.0.0.1:1", defaultPort)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client := newClient(conn, nil, time.Second, testFormatter(nil), true)
+	batch := testBatch("first", "second")
+	assert.Error(t, client.Publish(batch))
+
+	if assert.Len(t, batch.Signals, 1) {
+		assert.Equal(t, outest.BatchRetryEvents, batch.Signals[0].Tag)
+		assert.Len(t, batch.Signals[0].Events, 2)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := map[string]struct {
+		config map[string]interface{}
+		ok     bool
+	}{
+		"defaults":         {map[string]interface{}{}, true},
+		"tcp with tls":     {map[string]interface{}{"network": "tcp", "ssl.enabled": true}, true},
+		"udp with tls":     {map[string]interface{}{"network": "udp", "ssl.enabled": true}, false},
+		"udp tls disabled": {map[string]interface{}{"network": "udp", "ssl.enabled": false}, true},
+		"invalid network":  {map[string]interface{}{"network": "unix"}, false},
+		"rfc3164":          {map[string]interface{}{"format": "RFC3164"}, true},
+		"invalid format":   {map[string]interface{}{"format": "cef"}, false},
+		"framing":          {map[string]interface{}{"framing": "non_transparent"}, true},
+		"invalid framing":  {map[string]interface{}{"framing": "newline"}, false},
+		"facility name":    {map[string]interface{}{"facility": "local3"}, true},
+		"facility number":  {map[string]interface{}{"facility": 4}, true},
+		"invalid facility": {map[string]interface{}{"facility": "local9"}, false},
+		"severity":         {map[string]interface{}{"severity": "warning"}, true},
+		"invalid severity": {map[string]interface{}{"severity": 9}, false},
+		"message":          {map[string]interface{}{"message": "%{[message]}"}, true},
+	}
+
+	for name, test := range tests {
+		cfg, err := common.NewConfigFrom(test.config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		config := defaultConfig
+		err = cfg.Unpack(&config)
+		if test.ok {
+			assert.NoError(t, err, name)
+		} else {
+			assert.Error(t, err, name)
+		}
+	}
+}
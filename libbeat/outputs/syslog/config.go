@@ -0,0 +1,141 @@
+package syslog
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common/fmtstr"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+	"github.com/elastic/beats/libbeat/outputs/transport"
+)
+
+type syslogConfig struct {
+	Network       string                    `config:"network"`
+	Port          int                       `config:"port"`
+	Format        rfcFormat                 `config:"format"`
+	Framing       framing                   `config:"framing"`
+	Message       *fmtstr.EventFormatString `config:"message"`
+	Hostname      *fmtstr.EventFormatString `config:"hostname"`
+	AppName       *fmtstr.EventFormatString `config:"app_name"`
+	ProcID        *fmtstr.EventFormatString `config:"proc_id"`
+	MsgID         *fmtstr.EventFormatString `config:"msg_id"`
+	Facility      facility                  `config:"facility"`
+	Severity      severity                  `config:"severity"`
+	FacilityField string                    `config:"facility_field"`
+	SeverityField string                    `config:"severity_field"`
+	LoadBalance   bool                      `config:"loadbalance"`
+	Timeout       time.Duration             `config:"timeout"`
+	BulkMaxSize   int                       `config:"bulk_max_size"`
+	MaxRetries    int                       `config:"max_retries"`
+	Backoff       backoff                   `config:"backoff"`
+	TLS           *outputs.TLSConfig        `config:"ssl"`
+	Proxy         transport.ProxyConfig     `config:",inline"`
+	Codec         codec.Config              `config:"codec"`
+}
+
+type backoff struct {
+	Init time.Duration
+	Max  time.Duration
+}
+
+// rfcFormat selects the syslog message format.
+type rfcFormat uint8
+
+const (
+	formatRFC5424 rfcFormat = iota
+	formatRFC3164
+)
+
+// framing selects how messages are delimited on stream based transports.
+type framing uint8
+
+const (
+	// framingOctetCounting prefixes each message with its length (RFC 6587, 3.4.1).
+	framingOctetCounting framing = iota
+
+	// framingNonTransparent terminates each message with a newline (RFC 6587, 3.4.2).
+	framingNonTransparent
+)
+
+const defaultPort = 514
+
+var (
+	defaultConfig = syslogConfig{
+		Network:     "udp",
+		Port:        defaultPort,
+		Format:      formatRFC5424,
+		Framing:     framingOctetCounting,
+		Facility:    facilityUser,
+		Severity:    severityInformational,
+		LoadBalance: false,
+		Timeout:     5 * time.Second,
+		BulkMaxSize: 2048,
+		MaxRetries:  3,
+		Backoff: backoff{
+			Init: 1 * time.Second,
+			Max:  60 * time.Second,
+		},
+	}
+)
+
+func (c *syslogConfig) Validate() error {
+	switch c.Network {
+	case "tcp", "tcp4", "tcp6":
+	case "udp", "udp4", "udp6":
+		if c.TLS.IsEnabled() {
+			return errors.New("ssl can only be used with the tcp network")
+		}
+		if c.Proxy.URL != "" {
+			return errors.New("proxy_url can only be used with the tcp network")
+		}
+	default:
+		return fmt.Errorf("network '%v' not supported", c.Network)
+	}
+
+	return nil
+}
+
+func (f *rfcFormat) Unpack(value string) error {
+	switch strings.ToLower(value) {
+	case "rfc5424":
+		*f = formatRFC5424
+	case "rfc3164":
+		*f = formatRFC3164
+	default:
+		return fmt.Errorf("syslog format '%v' not supported", value)
+	}
+	return nil
+}
+
+func (f *framing) Unpack(value string) error {
+	switch strings.ToLower(value) {
+	case "octet_counting":
+		*f = framingOctetCounting
+	case "non_transparent":
+		*f = framingNonTransparent
+	default:
+		return fmt.Errorf("framing '%v' not supported", value)
+	}
+	return nil
+}
+
+func (f *facility) Unpack(value interface{}) error {
+	v, ok := parseFacility(value)
+	if !ok {
+		return fmt.Errorf("invalid syslog facility '%v'", value)
+	}
+	*f = v
+	return nil
+}
+
+func (s *severity) Unpack(value interface{}) error {
+	v, ok := parseSeverity(value)
+	if !ok {
+		return fmt.Errorf("invalid syslog severity '%v'", value)
+	}
+	*s = v
+	return nil
+}
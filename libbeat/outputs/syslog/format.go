@@ -0,0 +1,217 @@
+package syslog
+
+import (
+	"bytes"
+	"strconv"
+	"time"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common/fmtstr"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+)
+
+// Maximum lengths of the RFC 5424 header fields.
+const (
+	maxHostnameLen = 255
+	maxAppNameLen  = 48
+	maxProcIDLen   = 128
+	maxMsgIDLen    = 32
+
+	// RFC 3164 limits the TAG to 32 characters.
+	maxTagLen = 32
+
+	nilValue = "-"
+
+	rfc5424TimeFormat = "2006-01-02T15:04:05.000000Z07:00"
+	rfc3164TimeFormat = time.Stamp
+)
+
+// formatter renders events into syslog messages.
+type formatter struct {
+	format  rfcFormat
+	framing framing
+	stream  bool // apply framing for stream based transports
+
+	message  *fmtstr.EventFormatString
+	hostname *fmtstr.EventFormatString
+	appName  *fmtstr.EventFormatString
+	procID   *fmtstr.EventFormatString
+	msgID    *fmtstr.EventFormatString
+
+	facility      facility
+	severity      severity
+	facilityField string
+	severityField string
+
+	// defaults used if hostname or app_name are not configured
+	defaultHostname string
+	defaultAppName  string
+
+	index string
+	codec codec.Codec
+
+	buf bytes.Buffer
+	msg bytes.Buffer
+}
+
+// Format renders the event into a syslog message. The returned buffer is only
+// valid until Format is called again.
+func (f *formatter) Format(event *beat.Event) ([]byte, error) {
+	f.msg.Reset()
+	if f.message != nil {
+		if err := f.message.Eval(&f.msg, event); err != nil {
+			return nil, err
+		}
+	} else {
+		serialized, err := f.codec.Encode(f.index, event)
+		if err != nil {
+			return nil, err
+		}
+		f.msg.Write(serialized)
+	}
+
+	pri := strconv.Itoa(priority(f.eventFacility(event), f.eventSeverity(event)))
+	hostname := f.field(event, f.hostname, f.defaultHostname)
+
+	f.buf.Reset()
+	f.buf.WriteByte('<')
+	f.buf.WriteString(pri)
+	f.buf.WriteByte('>')
+	if f.format == formatRFC3164 {
+		f.formatRFC3164Header(event, hostname)
+	} else {
+		f.formatRFC5424Header(event, hostname)
+	}
+	f.buf.Write(f.msg.Bytes())
+
+	if !f.stream {
+		return f.buf.Bytes(), nil
+	}
+
+	if f.framing == framingNonTransparent {
+		// newlines in the message would break the framing
+		msg := bytes.Replace(f.buf.Bytes(), []byte{'\n'}, []byte{' '}, -1)
+		return append(msg, '\n'), nil
+	}
+
+	// octet counting: MSG-LEN SP SYSLOG-MSG
+	msg := make([]byte, 0, f.buf.Len()+8)
+	msg = strconv.AppendInt(msg, int64(f.buf.Len()), 10)
+	msg = append(msg, ' ')
+	return append(msg, f.buf.Bytes()...), nil
+}
+
+// formatRFC5424Header writes: VERSION SP TIMESTAMP SP HOSTNAME SP APP-NAME SP
+// PROCID SP MSGID SP STRUCTURED-DATA SP
+func (f *formatter) formatRFC5424Header(event *beat.Event, hostname string) {
+	b := &f.buf
+	b.WriteString("1 ")
+	b.WriteString(event.Timestamp.Format(rfc5424TimeFormat))
+	b.WriteByte(' ')
+	b.WriteString(headerValue(hostname, maxHostnameLen))
+	b.WriteByte(' ')
+	b.WriteString(headerValue(f.field(event, f.appName, f.defaultAppName), maxAppNameLen))
+	b.WriteByte(' ')
+	b.WriteString(headerValue(f.field(event, f.procID, ""), maxProcIDLen))
+	b.WriteByte(' ')
+	b.WriteString(headerValue(f.field(event, f.msgID, ""), maxMsgIDLen))
+	b.WriteString(" - ")
+}
+
+// formatRFC3164Header writes: TIMESTAMP SP HOSTNAME SP TAG[PID]: SP
+func (f *formatter) formatRFC3164Header(event *beat.Event, hostname string) {
+	b := &f.buf
+	b.WriteString(event.Timestamp.Local().Format(rfc3164TimeFormat))
+	b.WriteByte(' ')
+	b.WriteString(headerValue(hostname, maxHostnameLen))
+	b.WriteByte(' ')
+
+	tag := sanitize(f.field(event, f.appName, f.defaultAppName), maxTagLen)
+	b.WriteString(tag)
+	if pid := sanitize(f.field(event, f.procID, ""), maxProcIDLen); pid != "" {
+		b.WriteByte('[')
+		b.WriteString(pid)
+		b.WriteByte(']')
+	}
+	b.WriteString(": ")
+}
+
+func (f *formatter) eventFacility(event *beat.Event) facility {
+	if f.facilityField == "" {
+		return f.facility
+	}
+
+	v, err := event.GetValue(f.facilityField)
+	if err != nil {
+		return f.facility
+	}
+	fac, ok := parseFacility(v)
+	if !ok {
+		debugf("Invalid facility '%v' in field %v, using default facility", v, f.facilityField)
+		return f.facility
+	}
+	return fac
+}
+
+func (f *formatter) eventSeverity(event *beat.Event) severity {
+	if f.severityField == "" {
+		return f.severity
+	}
+
+	v, err := event.GetValue(f.severityField)
+	if err != nil {
+		return f.severity
+	}
+	sev, ok := parseSeverity(v)
+	if !ok {
+		debugf("Invalid severity '%v' in field %v, using default severity", v, f.severityField)
+		return f.severity
+	}
+	return sev
+}
+
+// field evaluates an optional header field format string. The default is
+// returned if the format string is not configured or can not be evaluated.
+func (f *formatter) field(event *beat.Event, fs *fmtstr.EventFormatString, def string) string {
+	if fs == nil {
+		return def
+	}
+
+	s, err := fs.Run(event)
+	if err != nil {
+		debugf("Failed to format header field: %v", err)
+		return def
+	}
+	return s
+}
+
+// headerValue sanitizes a RFC 5424 header field, using the nil value for
+// empty fields.
+func headerValue(s string, maxLen int) string {
+	s = sanitize(s, maxLen)
+	if s == "" {
+		return nilValue
+	}
+	return s
+}
+
+// sanitize replaces all characters that are not printable US-ASCII (including
+// spaces) with '_' and truncates s to maxLen.
+func sanitize(s string, maxLen int) string {
+	if len(s) > maxLen {
+		s = s[:maxLen]
+	}
+
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; c < 33 || c > 126 {
+			b := []byte(s)
+			for j := i; j < len(b); j++ {
+				if b[j] < 33 || b[j] > 126 {
+					b[j] = '_'
+				}
+			}
+			return string(b)
+		}
+	}
+	return s
+}
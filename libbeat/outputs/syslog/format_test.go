@@ -0,0 +1,174 @@
+package syslog
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/fmtstr"
+	"github.com/elastic/beats/libbeat/outputs/codec/json"
+)
+
+var testTime = time.Date(2018, 6, 1, 12, 30, 45, 123456000, time.UTC)
+
+func testFormatter(modify func(f *formatter)) *formatter {
+	f := &formatter{
+		format:          formatRFC5424,
+		message:         fmtstr.MustCompileEvent("%{[message]}"),
+		facility:        facilityUser,
+		severity:        severityInformational,
+		defaultHostname: "myhost",
+		defaultAppName:  "testbeat",
+		index:           "testbeat",
+		codec:           json.New(false, "1.2.3"),
+	}
+	if modify != nil {
+		modify(f)
+	}
+	return f
+}
+
+func testEvent(fields common.MapStr) *beat.Event {
+	return &beat.Event{Timestamp: testTime, Fields: fields}
+}
+
+func TestFormatRFC5424(t *testing.T) {
+	f := testFormatter(func(f *formatter) {
+		f.procID = fmtstr.MustCompileEvent("%{[process.pid]}")
+		f.msgID = fmtstr.MustCompileEvent("%{[event.type]}")
+	})
+
+	msg, err := f.Format(testEvent(common.MapStr{
+		"message": "hello world",
+		"process": common.MapStr{"pid": 42},
+		"event":   common.MapStr{"type": "login attempt"},
+	}))
+	assert.NoError(t, err)
+	assert.Equal(t, "<14>1 2018-06-01T12:30:45.123456Z myhost testbeat 42 login_attempt - hello world", string(msg))
+}
+
+func TestFormatRFC5424NilValues(t *testing.T) {
+	f := testFormatter(func(f *formatter) {
+		f.hostname = fmtstr.MustCompileEvent("%{[host.name]}")
+		f.defaultHostname = ""
+	})
+
+	msg, err := f.Format(testEvent(common.MapStr{"message": "hello"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "<14>1 2018-06-01T12:30:45.123456Z - testbeat - - - hello", string(msg))
+}
+
+func TestFormatRFC3164(t *testing.T) {
+	f := testFormatter(func(f *formatter) {
+		f.format = formatRFC3164
+		f.procID = fmtstr.MustCompileEvent("%{[process.pid]}")
+	})
+
+	msg, err := f.Format(testEvent(common.MapStr{
+		"message": "hello world",
+		"process": common.MapStr{"pid": 42},
+	}))
+	assert.NoError(t, err)
+
+	ts := testTime.Local().Format(time.Stamp)
+	assert.Equal(t, "<14>"+ts+" myhost testbeat[42]: hello world", string(msg))
+}
+
+func TestFormatFraming(t *testing.T) {
+	event := testEvent(common.MapStr{"message": "line1\nline2"})
+
+	f := testFormatter(func(f *formatter) {
+		f.format = formatRFC3164
+		f.defaultHostname = "h"
+		f.stream = true
+	})
+	msg, err := f.Format(event)
+	assert.NoError(t, err)
+	expected := "<14>" + testTime.Local().Format(time.Stamp) + " h testbeat: line1\nline2"
+	assert.Equal(t, strconv.Itoa(len(expected))+" "+expected, string(msg))
+
+	f.framing = framingNonTransparent
+	msg, err = f.Format(event)
+	assert.NoError(t, err)
+	assert.Equal(t, "<14>"+testTime.Local().Format(time.Stamp)+" h testbeat: line1 line2\n", string(msg))
+}
+
+func TestFormatCodec(t *testing.T) {
+	f := testFormatter(func(f *formatter) {
+		f.message = nil
+	})
+
+	msg, err := f.Format(testEvent(common.MapStr{"message": "hello"}))
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`<14>1 2018-06-01T12:30:45.123456Z myhost testbeat - - - {"@timestamp":"2018-06-01T12:30:45.123Z","@metadata":{"beat":"testbeat","type":"doc","version":"1.2.3"},"message":"hello"}`,
+		string(msg))
+}
+
+func TestFormatMissingMessage(t *testing.T) {
+	f := testFormatter(nil)
+	_, err := f.Format(testEvent(common.MapStr{"other": "value"}))
+	assert.Error(t, err)
+}
+
+func TestFormatPriorityFromFields(t *testing.T) {
+	f := testFormatter(func(f *formatter) {
+		f.facilityField = "syslog.facility"
+		f.severityField = "log.level"
+	})
+
+	tests := []struct {
+		fields   common.MapStr
+		expected string
+	}{
+		{common.MapStr{}, "<14>"},
+		{common.MapStr{"log": common.MapStr{"level": "error"}}, "<11>"},
+		{common.MapStr{"log": common.MapStr{"level": "WARN"}}, "<12>"},
+		{common.MapStr{"log": common.MapStr{"level": 7}}, "<15>"},
+		{common.MapStr{"log": common.MapStr{"level": "unknown"}}, "<14>"},
+		{common.MapStr{"syslog": common.MapStr{"facility": "local0"}, "log": common.MapStr{"level": "crit"}}, "<130>"},
+		{common.MapStr{"syslog": common.MapStr{"facility": 4}}, "<38>"},
+		{common.MapStr{"syslog": common.MapStr{"facility": 24}}, "<14>"},
+	}
+
+	for _, test := range tests {
+		test.fields["message"] = "msg"
+		msg, err := f.Format(testEvent(test.fields))
+		if assert.NoError(t, err) {
+			assert.Equal(t, test.expected, string(msg[:len(test.expected)]), "%v", test.fields)
+		}
+	}
+}
+
+func TestParseFacilitySeverity(t *testing.T) {
+	fac, ok := parseFacility("LOCAL7")
+	assert.True(t, ok)
+	assert.Equal(t, facility(23), fac)
+
+	fac, ok = parseFacility(uint64(3))
+	assert.True(t, ok)
+	assert.Equal(t, facility(3), fac)
+
+	_, ok = parseFacility("local8")
+	assert.False(t, ok)
+
+	sev, ok := parseSeverity("3")
+	assert.True(t, ok)
+	assert.Equal(t, severity(3), sev)
+
+	_, ok = parseSeverity(8)
+	assert.False(t, ok)
+
+	_, ok = parseSeverity(1.5)
+	assert.False(t, ok)
+}
+
+func TestSanitize(t *testing.T) {
+	assert.Equal(t, "a_b_c", sanitize("a b\tc", 10))
+	assert.Equal(t, "abc", sanitize("abcdef", 3))
+	assert.Equal(t, "-", headerValue("", 10))
+}
@@ -0,0 +1,140 @@
+package syslog
+
+import (
+	"strconv"
+	"strings"
+)
+
+type facility uint8
+
+type severity uint8
+
+const (
+	facilityUser facility = 1
+
+	severityInformational severity = 6
+
+	maxFacility = 23
+	maxSeverity = 7
+)
+
+var facilityNames = map[string]facility{
+	"kern":     0,
+	"user":     1,
+	"mail":     2,
+	"daemon":   3,
+	"auth":     4,
+	"syslog":   5,
+	"lpr":      6,
+	"news":     7,
+	"uucp":     8,
+	"cron":     9,
+	"authpriv": 10,
+	"ftp":      11,
+	"ntp":      12,
+	"security": 13,
+	"console":  14,
+	"clock":    15,
+	"local0":   16,
+	"local1":   17,
+	"local2":   18,
+	"local3":   19,
+	"local4":   20,
+	"local5":   21,
+	"local6":   22,
+	"local7":   23,
+}
+
+var severityNames = map[string]severity{
+	"emerg":         0,
+	"emergency":     0,
+	"panic":         0,
+	"alert":         1,
+	"crit":          2,
+	"critical":      2,
+	"fatal":         2,
+	"err":           3,
+	"error":         3,
+	"warning":       4,
+	"warn":          4,
+	"notice":        5,
+	"info":          6,
+	"informational": 6,
+	"debug":         7,
+	"trace":         7,
+}
+
+// parseFacility parses a facility name or number.
+func parseFacility(v interface{}) (facility, bool) {
+	if name, ok := v.(string); ok {
+		if f, exists := facilityNames[strings.ToLower(strings.TrimSpace(name))]; exists {
+			return f, true
+		}
+	}
+
+	n, ok := parseCode(v, maxFacility)
+	return facility(n), ok
+}
+
+// parseSeverity parses a severity (log level) name or number.
+func parseSeverity(v interface{}) (severity, bool) {
+	if name, ok := v.(string); ok {
+		if s, exists := severityNames[strings.ToLower(strings.TrimSpace(name))]; exists {
+			return s, true
+		}
+	}
+
+	n, ok := parseCode(v, maxSeverity)
+	return severity(n), ok
+}
+
+func parseCode(v interface{}, max int64) (int64, bool) {
+	var n int64
+	switch val := v.(type) {
+	case int:
+		n = int64(val)
+	case int8:
+		n = int64(val)
+	case int16:
+		n = int64(val)
+	case int32:
+		n = int64(val)
+	case int64:
+		n = val
+	case uint:
+		n = int64(val)
+	case uint8:
+		n = int64(val)
+	case uint16:
+		n = int64(val)
+	case uint32:
+		n = int64(val)
+	case uint64:
+		if val > uint64(max) {
+			return 0, false
+		}
+		n = int64(val)
+	case float64:
+		if val != float64(int64(val)) {
+			return 0, false
+		}
+		n = int64(val)
+	case string:
+		i, err := strconv.ParseInt(strings.TrimSpace(val), 10, 64)
+		if err != nil {
+			return 0, false
+		}
+		n = i
+	default:
+		return 0, false
+	}
+
+	if n < 0 || n > max {
+		return 0, false
+	}
+	return n, true
+}
+
+func priority(f facility, s severity) int {
+	return int(f)*8 + int(s)
+}
@@ -0,0 +1,88 @@
+package syslog
+
+import (
+	"strings"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/outputs/codec"
+	"github.com/elastic/beats/libbeat/outputs/transport"
+)
+
+var debugf = logp.MakeDebug("syslog")
+
+func init() {
+	outputs.RegisterType("syslog", makeSyslog)
+}
+
+func makeSyslog(
+	beat beat.Info,
+	observer outputs.Observer,
+	cfg *common.Config,
+) (outputs.Group, error) {
+	config := defaultConfig
+	if err := cfg.Unpack(&config); err != nil {
+		return outputs.Fail(err)
+	}
+
+	hosts, err := outputs.ReadHostList(cfg)
+	if err != nil {
+		return outputs.Fail(err)
+	}
+
+	tls, err := outputs.LoadTLSConfig(config.TLS)
+	if err != nil {
+		return outputs.Fail(err)
+	}
+
+	transp := &transport.Config{
+		Timeout: config.Timeout,
+		TLS:     tls,
+		Stats:   observer,
+	}
+	if config.Proxy.URL != "" {
+		transp.Proxy = &config.Proxy
+	}
+
+	stream := strings.HasPrefix(config.Network, "tcp")
+	clients := make([]outputs.NetworkClient, len(hosts))
+	for i, host := range hosts {
+		enc, err := codec.CreateEncoder(beat, config.Codec)
+		if err != nil {
+			return outputs.Fail(err)
+		}
+
+		conn, err := transport.NewClient(transp, config.Network, host, config.Port)
+		if err != nil {
+			return outputs.Fail(err)
+		}
+
+		f := &formatter{
+			format:          config.Format,
+			framing:         config.Framing,
+			stream:          stream,
+			message:         config.Message,
+			hostname:        config.Hostname,
+			appName:         config.AppName,
+			procID:          config.ProcID,
+			msgID:           config.MsgID,
+			facility:        config.Facility,
+			severity:        config.Severity,
+			facilityField:   config.FacilityField,
+			severityField:   config.SeverityField,
+			defaultHostname: beat.Hostname,
+			defaultAppName:  beat.Beat,
+			index:           beat.Beat,
+			codec:           enc,
+		}
+
+		var client outputs.NetworkClient
+		client = newClient(conn, observer, config.Timeout, f, stream)
+		client = outputs.WithBackoff(client, config.Backoff.Init, config.Backoff.Max)
+		clients[i] = client
+	}
+
+	return outputs.SuccessNet(config.LoadBalance, config.BulkMaxSize, config.MaxRetries, clients)
+}
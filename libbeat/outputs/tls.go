@@ -39,6 +39,17 @@ type TLSConfig struct {
 	Renegotiation    tlsRenegotiationSupport       `config:"renegotiation"`
 }
 
+// TLSServerConfig defines config file options for TLS servers.
+type TLSServerConfig struct {
+	Enabled      *bool                  `config:"enabled"`
+	Versions     []transport.TLSVersion `config:"supported_protocols"`
+	CipherSuites []tlsCipherSuite       `config:"cipher_suites"`
+	CAs          []string               `config:"certificate_authorities"`
+	Certificate  CertificateConfig      `config:",inline"`
+	CurveTypes   []tlsCurveType         `config:"curve_types"`
+	ClientAuth   *tlsClientAuth         `config:"client_authentication"`
+}
+
 type CertificateConfig struct {
 	Certificate string `config:"certificate"`
 	Key         string `config:"key"`
@@ -51,6 +62,8 @@ type tlsCurveType tls.CurveID
 
 type tlsRenegotiationSupport tls.RenegotiationSupport
 
+type tlsClientAuth tls.ClientAuthType
+
 var tlsCipherSuites = map[string]tlsCipherSuite{
 	"ECDHE-ECDSA-AES-128-CBC-SHA":    tlsCipherSuite(tls.TLS_ECDHE_ECDSA_WITH_AES_128_CBC_SHA),
 	"ECDHE-ECDSA-AES-128-GCM-SHA256": tlsCipherSuite(tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256),
@@ -83,6 +96,12 @@ var tlsRenegotiationSupportTypes = map[string]tlsRenegotiationSupport{
 	"freely": tlsRenegotiationSupport(tls.RenegotiateFreelyAsClient),
 }
 
+var tlsClientAuthTypes = map[string]tlsClientAuth{
+	"none":     tlsClientAuth(tls.NoClientCert),
+	"optional": tlsClientAuth(tls.VerifyClientCertIfGiven),
+	"required": tlsClientAuth(tls.RequireAndVerifyClientCert),
+}
+
 func (c *TLSConfig) Validate() error {
 	hasCertificate := c.Certificate.Certificate != ""
 	hasKey := c.Certificate.Key != ""
@@ -157,6 +176,80 @@ func LoadTLSConfig(config *TLSConfig) (*transport.TLSConfig, error) {
 	}, nil
 }
 
+func (c *TLSServerConfig) Validate() error {
+	if !c.IsEnabled() {
+		return nil
+	}
+
+	if c.Certificate.Certificate == "" {
+		return ErrKeyNoCertificate
+	}
+	if c.Certificate.Key == "" {
+		return ErrCertificateNoKey
+	}
+	if c.clientAuth() != tls.NoClientCert && len(c.CAs) == 0 {
+		return errors.New("certificate_authorities must be set to verify client certificates")
+	}
+	return nil
+}
+
+// clientAuth returns the configured client authentication. Client
+// certificates are required by default, if certificate authorities are
+// configured.
+func (c *TLSServerConfig) clientAuth() tls.ClientAuthType {
+	if c.ClientAuth != nil {
+		return tls.ClientAuthType(*c.ClientAuth)
+	}
+	if len(c.CAs) > 0 {
+		return tls.RequireAndVerifyClientCert
+	}
+	return tls.NoClientCert
+}
+
+func (c *TLSServerConfig) IsEnabled() bool {
+	return c != nil && (c.Enabled == nil || *c.Enabled)
+}
+
+// LoadTLSServerConfig loads the server certificate and the TLS settings of a
+// server. Nil is returned if TLS is not enabled.
+func LoadTLSServerConfig(config *TLSServerConfig) (*transport.TLSConfig, error) {
+	if !config.IsEnabled() {
+		return nil, nil
+	}
+
+	var cipherSuites []uint16
+	for _, suite := range config.CipherSuites {
+		cipherSuites = append(cipherSuites, uint16(suite))
+	}
+
+	var curves []tls.CurveID
+	for _, id := range config.CurveTypes {
+		curves = append(curves, tls.CurveID(id))
+	}
+
+	cert, err := LoadCertificate(&config.Certificate)
+	if err != nil {
+		return nil, err
+	}
+	if cert == nil {
+		return nil, ErrKeyNoCertificate
+	}
+
+	cas, errs := LoadCertificateAuthorities(config.CAs)
+	if err := multierror.Errors(errs).Err(); err != nil {
+		return nil, err
+	}
+
+	return &transport.TLSConfig{
+		Versions:         config.Versions,
+		Certificates:     []tls.Certificate{*cert},
+		ClientCAs:        cas,
+		ClientAuth:       config.clientAuth(),
+		CipherSuites:     cipherSuites,
+		CurvePreferences: curves,
+	}, nil
+}
+
 func LoadCertificate(config *CertificateConfig) (*tls.Certificate, error) {
 	certificate := config.Certificate
 	key := config.Key
@@ -309,3 +402,13 @@ func (r *tlsRenegotiationSupport) Unpack(s string) error {
 	*r = t
 	return nil
 }
+
+func (a *tlsClientAuth) Unpack(s string) error {
+	t, found := tlsClientAuthTypes[s]
+	if !found {
+		return fmt.Errorf("invalid tls client authentication type '%v'", s)
+	}
+
+	*a = t
+	return nil
+}
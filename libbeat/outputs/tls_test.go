@@ -199,3 +199,123 @@ func TestCertificateFails(t *testing.T) {
 		assert.Error(t, err)
 	}
 }
+
+func loadServer(yamlStr string) (*TLSServerConfig, error) {
+	var cfg TLSServerConfig
+	config, err := common.NewConfigWithYAML([]byte(yamlStr), "")
+	if err != nil {
+		return nil, err
+	}
+
+	if err = config.Unpack(&cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+func TestNoLoadDisabledServerConfig(t *testing.T) {
+	cfg, err := LoadTLSServerConfig(nil)
+	assert.Nil(t, err)
+	assert.Nil(t, cfg)
+
+	enabled := false
+	cfg, err = LoadTLSServerConfig(&TLSServerConfig{Enabled: &enabled})
+	assert.Nil(t, err)
+	assert.Nil(t, cfg)
+}
+
+func TestApplyServerConfig(t *testing.T) {
+	config, err := loadServer(`
+    certificate: logstash/ca_test.pem
+    key: logstash/ca_test.key
+    supported_protocols: [TLSv1.1, TLSv1.2]
+    cipher_suites:
+      - "ECDHE-ECDSA-AES-256-CBC-SHA"
+  `)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmp, err := LoadTLSServerConfig(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg := tmp.BuildServerConfig()
+	assert.Len(t, cfg.Certificates, 1)
+	assert.Len(t, cfg.CipherSuites, 1)
+	assert.Equal(t, int(tls.VersionTLS11), int(cfg.MinVersion))
+	assert.Equal(t, int(tls.VersionTLS12), int(cfg.MaxVersion))
+}
+
+func TestServerConfigRequiresCertificate(t *testing.T) {
+	tests := []string{
+		"enabled: true",
+		"certificate: mycert.pem",
+		"key: mycert.key",
+	}
+
+	for _, yaml := range tests {
+		_, err := loadServer(yaml)
+		assert.Error(t, err, yaml)
+	}
+
+	_, err := loadServer(`
+    certificate: logstash/ca_test.pem
+    key: logstash/ca_test.key
+    client_authentication: required
+  `)
+	assert.Error(t, err, "client authentication without certificate authorities")
+
+	_, err = loadServer(`
+    certificate: logstash/ca_test.pem
+    key: logstash/ca_test.key
+    client_authentication: always
+  `)
+	assert.Error(t, err, "unknown client authentication type")
+
+	_, err = loadServer("enabled: false")
+	assert.NoError(t, err)
+}
+
+func TestServerClientAuth(t *testing.T) {
+	tests := []struct {
+		yaml     string
+		expected tls.ClientAuthType
+	}{
+		{"", tls.NoClientCert},
+		{"certificate_authorities: [logstash/ca_test.pem]", tls.RequireAndVerifyClientCert},
+		{"{certificate_authorities: [logstash/ca_test.pem], client_authentication: optional}", tls.VerifyClientCertIfGiven},
+		{"{certificate_authorities: [logstash/ca_test.pem], client_authentication: none}", tls.NoClientCert},
+	}
+
+	for _, test := range tests {
+		config, err := loadServer(`
+    certificate: logstash/ca_test.pem
+    key: logstash/ca_test.key
+  `)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if test.yaml != "" {
+			cfg, err := common.NewConfigWithYAML([]byte(test.yaml), "")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := cfg.Unpack(config); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		tmp, err := LoadTLSServerConfig(config)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		cfg := tmp.BuildServerConfig()
+		assert.Equal(t, test.expected, cfg.ClientAuth, test.yaml)
+		if test.expected != tls.NoClientCert {
+			assert.NotNil(t, cfg.ClientCAs, test.yaml)
+		}
+	}
+}
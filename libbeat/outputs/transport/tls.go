@@ -32,6 +32,13 @@ type TLSConfig struct {
 	// on MS Windows).
 	RootCAs *x509.CertPool
 
+	// Set of root certificate authorities used by servers to verify client
+	// certificates.
+	ClientCAs *x509.CertPool
+
+	// Client authentication required by servers.
+	ClientAuth tls.ClientAuthType
+
 	// List of supported cipher suites. If nil, a default list provided by the
 	// implementation will be used.
 	CipherSuites []uint16
@@ -199,28 +206,48 @@ func postVerifyTLSConnection(d testing.Driver, conn *tls.Conn, config *TLSConfig
 	return nil
 }
 
-func (c *TLSConfig) BuildModuleConfig(host string) *tls.Config {
-	if c == nil {
-		// use default TLS settings, if config is empty.
-		return &tls.Config{ServerName: host}
+// BuildServerConfig builds the tls.Config of a server presenting the
+// configured certificates. Client certificates are verified using ClientCAs.
+func (c *TLSConfig) BuildServerConfig() *tls.Config {
+	minVersion, maxVersion := c.versionRange()
+	return &tls.Config{
+		MinVersion:               minVersion,
+		MaxVersion:               maxVersion,
+		Certificates:             c.Certificates,
+		ClientCAs:                c.ClientCAs,
+		ClientAuth:               c.ClientAuth,
+		CipherSuites:             c.CipherSuites,
+		CurvePreferences:         c.CurvePreferences,
+		PreferServerCipherSuites: true,
 	}
+}
 
+func (c *TLSConfig) versionRange() (min, max uint16) {
 	versions := c.Versions
 	if len(versions) == 0 {
 		versions = tlsDefaultVersions
 	}
 
-	minVersion := uint16(0xffff)
-	maxVersion := uint16(0)
+	min, max = uint16(0xffff), uint16(0)
 	for _, version := range versions {
 		v := uint16(version)
-		if v < minVersion {
-			minVersion = v
+		if v < min {
+			min = v
 		}
-		if v > maxVersion {
-			maxVersion = v
+		if v > max {
+			max = v
 		}
 	}
+	return min, max
+}
+
+func (c *TLSConfig) BuildModuleConfig(host string) *tls.Config {
+	if c == nil {
+		// use default TLS settings, if config is empty.
+		return &tls.Config{ServerName: host}
+	}
+
+	minVersion, maxVersion := c.versionRange()
 
 	insecure := c.Verification != VerifyFull
 	if insecure {
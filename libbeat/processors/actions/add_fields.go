@@ -0,0 +1,78 @@
+package actions
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+type addFields struct {
+	fields    common.MapStr
+	overwrite bool
+}
+
+func init() {
+	processors.RegisterPlugin("add_fields",
+		configChecked(newAddFields,
+			requireFields("fields"),
+			allowedFields("fields", "target", "overwrite", "when")))
+}
+
+func newAddFields(c *common.Config) (processors.Processor, error) {
+	cfgwarn.Beta("Beta add_fields processor is used.")
+	config := struct {
+		Fields    common.MapStr `config:"fields" validate:"required"`
+		Target    *string       `config:"target"`
+		Overwrite bool          `config:"overwrite"`
+	}{
+		Overwrite: true,
+	}
+	err := c.Unpack(&config)
+	if err != nil {
+		return nil, fmt.Errorf("fail to unpack the add_fields configuration: %s", err)
+	}
+
+	target := common.FieldsKey
+	if config.Target != nil {
+		target = *config.Target
+	}
+
+	return makeFieldsProcessor(target, config.Fields, config.Overwrite), nil
+}
+
+// makeFieldsProcessor creates a processor adding fields to events. The fields
+// are added to the target namespace, or to the root of the event if target
+// is empty. Dotted targets are expanded into nested objects.
+func makeFieldsProcessor(target string, fields common.MapStr, overwrite bool) *addFields {
+	if target != "" {
+		namespaced := common.MapStr{}
+		namespaced.Put(target, fields)
+		fields = namespaced
+	}
+	return &addFields{fields: fields, overwrite: overwrite}
+}
+
+// Run adds the configured fields to the event. The fields are copied, as
+// the processor is shared between all events.
+func (af *addFields) Run(event *beat.Event) (*beat.Event, error) {
+	if event.Fields == nil {
+		event.Fields = common.MapStr{}
+	}
+
+	fields := af.fields.Clone()
+	if af.overwrite {
+		event.Fields.DeepUpdate(fields)
+	} else {
+		event.Fields.DeepUpdateNoOverwrite(fields)
+	}
+	return event, nil
+}
+
+func (af *addFields) String() string {
+	s, _ := json.Marshal(af.fields)
+	return fmt.Sprintf("add_fields=%s", s)
+}
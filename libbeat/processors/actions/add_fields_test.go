@@ -0,0 +1,123 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestAddFields(t *testing.T) {
+	multi := func(strs ...string) []string { return strs }
+	single := func(str string) []string { return multi(str) }
+
+	testProcessors(t, map[string]testCase{
+		"add field": {
+			eventFields: common.MapStr{},
+			wantFields: common.MapStr{
+				"fields": common.MapStr{"field": "test"},
+			},
+			cfg: single(`{add_fields: {fields: {field: test}}}`),
+		},
+		"custom target": {
+			eventFields: common.MapStr{},
+			wantFields: common.MapStr{
+				"my": common.MapStr{"field": "test"},
+			},
+			cfg: single(`{add_fields: {target: my, fields: {field: test}}}`),
+		},
+		"dotted target": {
+			eventFields: common.MapStr{},
+			wantFields: common.MapStr{
+				"my": common.MapStr{"ns": common.MapStr{"field": "test"}},
+			},
+			cfg: single(`{add_fields: {target: my.ns, fields: {field: test}}}`),
+		},
+		"under root": {
+			eventFields: common.MapStr{},
+			wantFields: common.MapStr{
+				"field": "test",
+			},
+			cfg: single(`{add_fields: {target: "", fields: {field: test}}}`),
+		},
+		"merge with existing fields": {
+			eventFields: common.MapStr{
+				"fields": common.MapStr{"existing": "a"},
+			},
+			wantFields: common.MapStr{
+				"fields": common.MapStr{"existing": "a", "field": "test"},
+			},
+			cfg: single(`{add_fields: {fields: {field: test}}}`),
+		},
+		"overwrite existing field": {
+			eventFields: common.MapStr{
+				"fields": common.MapStr{"field": "old"},
+			},
+			wantFields: common.MapStr{
+				"fields": common.MapStr{"field": "test"},
+			},
+			cfg: single(`{add_fields: {fields: {field: test}}}`),
+		},
+		"keep existing field": {
+			eventFields: common.MapStr{
+				"fields": common.MapStr{"field": "old"},
+			},
+			wantFields: common.MapStr{
+				"fields": common.MapStr{"field": "old", "other": "test"},
+			},
+			cfg: single(`{add_fields: {overwrite: false, fields: {field: test, other: test}}}`),
+		},
+		"multiple processors": {
+			eventFields: common.MapStr{},
+			wantFields: common.MapStr{
+				"fields": common.MapStr{"l1": "a", "l2": "b"},
+			},
+			cfg: multi(
+				`{add_fields: {fields: {l1: a}}}`,
+				`{add_fields: {fields: {l2: b}}}`,
+			),
+		},
+		"condition matches": {
+			eventFields: common.MapStr{"type": "a"},
+			wantFields: common.MapStr{
+				"type":   "a",
+				"fields": common.MapStr{"field": "test"},
+			},
+			cfg: single(`{add_fields: {fields: {field: test}, when.equals.type: a}}`),
+		},
+		"condition does not match": {
+			eventFields: common.MapStr{"type": "b"},
+			wantFields:  common.MapStr{"type": "b"},
+			cfg:         single(`{add_fields: {fields: {field: test}, when.equals.type: a}}`),
+		},
+	})
+}
+
+func TestAddFieldsIsolation(t *testing.T) {
+	c, err := common.NewConfigFrom(map[string]interface{}{
+		"fields": map[string]interface{}{"nested": map[string]interface{}{"field": "test"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	p, err := newAddFields(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := &beat.Event{Fields: common.MapStr{}}
+	if _, err := p.Run(first); err != nil {
+		t.Fatal(err)
+	}
+	first.Fields.Put("fields.nested.field", "modified")
+
+	second := &beat.Event{Fields: common.MapStr{}}
+	if _, err := p.Run(second); err != nil {
+		t.Fatal(err)
+	}
+
+	v, _ := second.Fields.GetValue("fields.nested.field")
+	if v != "test" {
+		t.Errorf("fields are shared between events, got %v", v)
+	}
+}
@@ -0,0 +1,38 @@
+package actions
+
+import (
+	"fmt"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+func init() {
+	processors.RegisterPlugin("add_labels",
+		configChecked(newAddLabels,
+			requireFields("labels"),
+			allowedFields("labels", "overwrite", "when")))
+}
+
+// newAddLabels creates a processor adding labels to the `labels` namespace.
+// Nested labels are flattened into dotted keys, so every label is a single
+// key value pair.
+func newAddLabels(c *common.Config) (processors.Processor, error) {
+	cfgwarn.Beta("Beta add_labels processor is used.")
+	config := struct {
+		Labels    common.MapStr `config:"labels" validate:"required"`
+		Overwrite bool          `config:"overwrite"`
+	}{
+		Overwrite: true,
+	}
+	err := c.Unpack(&config)
+	if err != nil {
+		return nil, fmt.Errorf("fail to unpack the add_labels configuration: %s", err)
+	}
+
+	labels := common.MapStr{
+		common.LabelsKey: config.Labels.Flatten(),
+	}
+	return makeFieldsProcessor("", labels, config.Overwrite), nil
+}
@@ -0,0 +1,38 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestAddLabels(t *testing.T) {
+	multi := func(strs ...string) []string { return strs }
+	single := func(str string) []string { return multi(str) }
+
+	testProcessors(t, map[string]testCase{
+		"add label": {
+			eventFields: common.MapStr{},
+			wantFields: common.MapStr{
+				"labels": common.MapStr{"label": "test"},
+			},
+			cfg: single(`{add_labels: {labels: {label: test}}}`),
+		},
+		"add nested labels": {
+			eventFields: common.MapStr{},
+			wantFields: common.MapStr{
+				"labels": common.MapStr{"a.b": "test", "a.c": "test2"},
+			},
+			cfg: single(`{add_labels: {labels: {a.b: test, a.c: test2}}}`),
+		},
+		"keep existing label": {
+			eventFields: common.MapStr{
+				"labels": common.MapStr{"label": "old"},
+			},
+			wantFields: common.MapStr{
+				"labels": common.MapStr{"label": "old", "other": "test"},
+			},
+			cfg: single(`{add_labels: {overwrite: false, labels: {label: test, other: test}}}`),
+		},
+	})
+}
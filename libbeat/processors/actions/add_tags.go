@@ -0,0 +1,62 @@
+package actions
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+type addTags struct {
+	tags   []string
+	target string
+}
+
+func init() {
+	processors.RegisterPlugin("add_tags",
+		configChecked(newAddTags,
+			requireFields("tags"),
+			allowedFields("tags", "target", "when")))
+}
+
+func newAddTags(c *common.Config) (processors.Processor, error) {
+	cfgwarn.Beta("Beta add_tags processor is used.")
+	config := struct {
+		Tags   []string `config:"tags" validate:"required"`
+		Target string   `config:"target"`
+	}{
+		Target: common.TagsKey,
+	}
+	err := c.Unpack(&config)
+	if err != nil {
+		return nil, fmt.Errorf("fail to unpack the add_tags configuration: %s", err)
+	}
+
+	if config.Target == "" {
+		return nil, fmt.Errorf("add_tags target must not be empty")
+	}
+
+	return &addTags{tags: config.Tags, target: config.Target}, nil
+}
+
+// Run appends the configured tags to the target field. The tags are copied,
+// as the processor is shared between all events.
+func (at *addTags) Run(event *beat.Event) (*beat.Event, error) {
+	if event.Fields == nil {
+		event.Fields = common.MapStr{}
+	}
+
+	tags := make([]string, len(at.tags))
+	copy(tags, at.tags)
+	if err := common.AddTagsWithKey(event.Fields, at.target, tags); err != nil {
+		return event, fmt.Errorf("failed to add tags to %v: %v", at.target, err)
+	}
+	return event, nil
+}
+
+func (at *addTags) String() string {
+	return fmt.Sprintf("add_tags=%v (target=%v)", strings.Join(at.tags, ","), at.target)
+}
@@ -0,0 +1,46 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestAddTags(t *testing.T) {
+	multi := func(strs ...string) []string { return strs }
+	single := func(str string) []string { return multi(str) }
+
+	testProcessors(t, map[string]testCase{
+		"create tags": {
+			eventFields: common.MapStr{},
+			wantFields:  common.MapStr{"tags": []string{"t1", "t2"}},
+			cfg:         single(`{add_tags: {tags: [t1, t2]}}`),
+		},
+		"append to tags": {
+			eventFields: common.MapStr{"tags": []string{"t1"}},
+			wantFields:  common.MapStr{"tags": []string{"t1", "t2", "t3"}},
+			cfg:         single(`{add_tags: {tags: [t2, t3]}}`),
+		},
+		"combine tags": {
+			eventFields: common.MapStr{},
+			wantFields:  common.MapStr{"tags": []string{"t1", "t2"}},
+			cfg: multi(
+				`{add_tags: {tags: [t1]}}`,
+				`{add_tags: {tags: [t2]}}`,
+			),
+		},
+		"custom target": {
+			eventFields: common.MapStr{"a": "b"},
+			wantFields: common.MapStr{
+				"a":    "b",
+				"meta": common.MapStr{"tags": []string{"t1"}},
+			},
+			cfg: single(`{add_tags: {tags: [t1], target: meta.tags}}`),
+		},
+		"condition": {
+			eventFields: common.MapStr{"type": "b"},
+			wantFields:  common.MapStr{"type": "b"},
+			cfg:         single(`{add_tags: {tags: [t1], when.equals.type: a}}`),
+		},
+	})
+}
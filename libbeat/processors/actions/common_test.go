@@ -0,0 +1,60 @@
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+type testCase struct {
+	eventFields common.MapStr
+	eventMeta   common.MapStr
+	cfg         []string
+	wantFields  common.MapStr
+	wantMeta    common.MapStr
+}
+
+// testProcessors runs the processors configured in YAML on every test case
+// event and compares the result.
+func testProcessors(t *testing.T, cases map[string]testCase) {
+	for name, test := range cases {
+		test := test
+		t.Run(name, func(t *testing.T) {
+			var configs []map[string]*common.Config
+			for _, cfg := range test.cfg {
+				tmp, err := common.NewConfigWithYAML([]byte(cfg), "test")
+				if err != nil {
+					t.Fatalf("Failed to parse config: %+v", err)
+				}
+
+				var pluginConfig map[string]*common.Config
+				if err := tmp.Unpack(&pluginConfig); err != nil {
+					t.Fatalf("Failed to unpack config: %+v", err)
+				}
+				configs = append(configs, pluginConfig)
+			}
+
+			ps, err := processors.New(configs)
+			if err != nil {
+				t.Fatalf("Failed to create processors: %+v", err)
+			}
+
+			current := &beat.Event{Fields: test.eventFields.Clone()}
+			if test.eventMeta != nil {
+				current.Meta = test.eventMeta.Clone()
+			}
+
+			actual := ps.Run(current)
+			if actual == nil {
+				t.Fatal("event was dropped")
+			}
+
+			assert.Equal(t, test.wantFields, actual.Fields)
+			assert.Equal(t, test.wantMeta, actual.Meta)
+		})
+	}
+}
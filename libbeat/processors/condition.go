@@ -3,6 +3,7 @@ package processors
 import (
 	"errors"
 	"fmt"
+	"net"
 	"reflect"
 	"strconv"
 	"strings"
@@ -34,6 +35,7 @@ type Condition struct {
 	}
 	hasfields []string
 	rangexp   map[string]RangeValue
+	network   map[string][]namedNetwork
 	or        []Condition
 	and       []Condition
 	not       *Condition
@@ -83,6 +85,8 @@ func NewCondition(config *ConditionConfig) (*Condition, error) {
 		c.matches.filters, err = compileMatches(config.Regexp.fields, match.Compile)
 	case config.Range != nil:
 		err = c.setRange(config.Range)
+	case config.Network != nil:
+		err = c.setNetwork(config.Network)
 	case config.HasFields != nil:
 		c.hasfields = config.HasFields
 	case len(config.OR) > 0:
@@ -214,6 +218,26 @@ func (c *Condition) setRange(cfg *ConditionFields) error {
 	return nil
 }
 
+func (c *Condition) setNetwork(cfg *ConditionFields) error {
+	fields, err := networkFields(cfg.fields)
+	if err != nil {
+		return err
+	}
+
+	c.network = map[string][]namedNetwork{}
+	for field, networks := range fields {
+		for _, s := range networks {
+			n, err := parseNetwork(s)
+			if err != nil {
+				return err
+			}
+			c.network[field] = append(c.network[field], n)
+		}
+	}
+
+	return nil
+}
+
 func (c *Condition) Check(event ValuesMap) bool {
 	if len(c.or) > 0 {
 		return c.checkOR(event)
@@ -230,6 +254,7 @@ func (c *Condition) Check(event ValuesMap) bool {
 	return c.checkEquals(event) &&
 		c.checkMatches(event) &&
 		c.checkRange(event) &&
+		c.checkNetwork(event) &&
 		c.checkHasFields(event)
 }
 
@@ -403,6 +428,42 @@ func (c *Condition) checkRange(event ValuesMap) bool {
 	return true
 }
 
+func (c *Condition) checkNetwork(event ValuesMap) bool {
+	contains := func(ip net.IP, networks []namedNetwork) bool {
+		for _, n := range networks {
+			if n.contains(ip) {
+				return true
+			}
+		}
+		return false
+	}
+
+	for field, networks := range c.network {
+		value, err := event.GetValue(field)
+		if err != nil {
+			return false
+		}
+
+		ips := extractIPs(value)
+		if len(ips) == 0 {
+			logp.Warn("unexpected type %T in network condition as it accepts only IP addresses.", value)
+			return false
+		}
+
+		found := false
+		for _, ip := range ips {
+			if contains(ip, networks) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
 func (c *Condition) checkHasFields(event ValuesMap) bool {
 	for _, field := range c.hasfields {
 		_, err := event.GetValue(field)
@@ -425,6 +486,9 @@ func (c Condition) String() string {
 	if len(c.rangexp) > 0 {
 		s = s + fmt.Sprintf("range: %v", c.rangexp)
 	}
+	if len(c.network) > 0 {
+		s = s + fmt.Sprintf("network: %v", c.network)
+	}
 	if len(c.hasfields) > 0 {
 		s = s + fmt.Sprintf("has_fields: %v", c.hasfields)
 	}
@@ -509,6 +573,94 @@ func (r *WhenProcessor) String() string {
 	return fmt.Sprintf("%v, condition=%v", r.p.String(), r.condition.String())
 }
 
+// IfThenElseProcessor executes the processors of the `then` branch if the
+// condition is true, or the processors of the optional `else` branch
+// otherwise.
+type IfThenElseProcessor struct {
+	condition *Condition
+	then      *Processors
+	els       *Processors
+}
+
+// NewIfThenElseProcessor creates an IfThenElseProcessor from the `if`, `then`
+// and `else` keys of a processor definition. The branches are lists of
+// processors, built with New.
+func NewIfThenElseProcessor(config map[string]*common.Config) (*IfThenElseProcessor, error) {
+	for key := range config {
+		switch key {
+		case "if", "then", "else":
+		default:
+			return nil, fmt.Errorf("unexpected %v option in if processor", key)
+		}
+	}
+
+	if config["then"] == nil {
+		return nil, errors.New("missing then option in if processor")
+	}
+
+	condConfig := ConditionConfig{}
+	if err := config["if"].Unpack(&condConfig); err != nil {
+		return nil, err
+	}
+
+	cond, err := NewCondition(&condConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	then, err := newBranch(config["then"])
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize then processors: %v", err)
+	}
+
+	var els *Processors
+	if config["else"] != nil {
+		els, err = newBranch(config["else"])
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize else processors: %v", err)
+		}
+	}
+
+	return &IfThenElseProcessor{cond, then, els}, nil
+}
+
+// newBranch creates the processors of a branch, which is either a single
+// processor or a list of processors.
+func newBranch(cfg *common.Config) (*Processors, error) {
+	var branch PluginConfig
+	if cfg.IsDict() {
+		single := map[string]*common.Config{}
+		if err := cfg.Unpack(&single); err != nil {
+			return nil, err
+		}
+		branch = PluginConfig{single}
+	} else if err := cfg.Unpack(&branch); err != nil {
+		return nil, err
+	}
+
+	if len(branch) == 0 {
+		return nil, errors.New("no processors defined")
+	}
+	return New(branch)
+}
+
+func (p *IfThenElseProcessor) Run(event *beat.Event) (*beat.Event, error) {
+	if p.condition.Check(event) {
+		return p.then.Run(event), nil
+	} else if p.els != nil {
+		return p.els.Run(event), nil
+	}
+	return event, nil
+}
+
+func (p *IfThenElseProcessor) String() string {
+	s := fmt.Sprintf("if %v then %v", p.condition, p.then)
+	if p.els != nil {
+		s = s + fmt.Sprintf(" else %v", p.els)
+	}
+	return s
+}
+
 func addCondition(
 	cfg *common.Config,
 	p Processor,
@@ -342,6 +342,125 @@ func TestRangeCondition(t *testing.T) {
 	assert.False(t, conds[3].Check(event))
 }
 
+func TestNetworkCondition(t *testing.T) {
+	logp.TestingSetup()
+
+	configs := []ConditionConfig{
+		{
+			Network: &ConditionFields{fields: map[string]interface{}{
+				"source.ip": "192.168.0.0/16",
+			}},
+		},
+
+		{
+			Network: &ConditionFields{fields: map[string]interface{}{
+				"source.ip":      "private",
+				"destination.ip": "public",
+			}},
+		},
+
+		{
+			Network: &ConditionFields{fields: map[string]interface{}{
+				"destination.ip.0": "loopback",
+				"destination.ip.1": "8.8.8.8",
+			}},
+		},
+
+		{
+			Network: &ConditionFields{fields: map[string]interface{}{
+				"ips": "fe80::/10",
+			}},
+		},
+
+		{
+			Network: &ConditionFields{fields: map[string]interface{}{
+				"source.ip": "multicast",
+			}},
+		},
+	}
+
+	conds := GetConditions(t, configs)
+
+	event := &beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"source": common.MapStr{
+				"ip": "192.168.1.1",
+			},
+			"destination": common.MapStr{
+				"ip": "8.8.8.8",
+			},
+			"ips": []string{"10.0.0.1", "fe80::1"},
+		},
+	}
+
+	event1 := &beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"source": common.MapStr{
+				"ip": "224.0.0.1",
+			},
+			"destination": common.MapStr{
+				"ip": "127.0.0.1",
+			},
+			"ips": "not an ip",
+		},
+	}
+
+	assert.True(t, conds[0].Check(event))
+	assert.False(t, conds[0].Check(event1))
+	assert.True(t, conds[1].Check(event))
+	assert.False(t, conds[1].Check(event1))
+	assert.True(t, conds[2].Check(event))
+	assert.True(t, conds[2].Check(event1))
+	assert.True(t, conds[3].Check(event))
+	assert.False(t, conds[3].Check(event1))
+	assert.False(t, conds[4].Check(event))
+	assert.True(t, conds[4].Check(event1))
+}
+
+func TestNetworkConditionConfig(t *testing.T) {
+	logp.TestingSetup()
+
+	cfg, err := common.NewConfigFrom(map[string]interface{}{
+		"network": map[string]interface{}{
+			"source.ip": []string{"10.0.0.0/8", "link_local"},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := ConditionConfig{}
+	if err := cfg.Unpack(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	cond, err := NewCondition(&config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for ip, matches := range map[string]bool{
+		"10.1.2.3":    true,
+		"169.254.1.1": true,
+		"fe80::1":     true,
+		"192.0.2.1":   false,
+	} {
+		event := &beat.Event{Fields: common.MapStr{"source": common.MapStr{"ip": ip}}}
+		assert.Equal(t, matches, cond.Check(event), ip)
+	}
+
+	for _, network := range []string{"10.0.0.0/33", "intranet"} {
+		_, err := NewCondition(&ConditionConfig{
+			Network: &ConditionFields{fields: map[string]interface{}{
+				"source.ip": network,
+			}},
+		})
+		assert.Error(t, err, network)
+	}
+}
+
 func TestORCondition(t *testing.T) {
 	logp.TestingSetup()
 
@@ -13,6 +13,7 @@ type ConditionConfig struct {
 	Contains  *ConditionFields  `config:"contains"`
 	Regexp    *ConditionFields  `config:"regexp"`
 	Range     *ConditionFields  `config:"range"`
+	Network   *ConditionFields  `config:"network"`
 	HasFields []string          `config:"has_fields"`
 	OR        []ConditionConfig `config:"or"`
 	AND       []ConditionConfig `config:"and"`
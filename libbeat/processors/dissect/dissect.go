@@ -0,0 +1,145 @@
+package dissect
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Map represents the keys and their values extracted with the defined tokenizer.
+type Map = map[string]string
+
+// Dissector is a tokenizer based on the Dissect syntax as defined at:
+// https://www.elastic.co/guide/en/logstash/current/plugins-filters-dissect.html
+type Dissector struct {
+	raw    string
+	parser *parser
+}
+
+// New creates a new Dissector from a tokenized string.
+func New(tokenizer string) (*Dissector, error) {
+	p, err := newParser(tokenizer)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dissector{parser: p, raw: tokenizer}, nil
+}
+
+// Dissect takes the raw string and will use the defined tokenizer to return a map with the
+// extracted keys and their values.
+//
+// Dissect uses a 2 steps process:
+// - Extract the raw value of every field by walking the delimiters
+// - Resolve the keys (append, indirect) and ignore the skipped fields
+func (d *Dissector) Dissect(s string) (Map, error) {
+	if len(s) == 0 {
+		return nil, errEmpty
+	}
+
+	values, err := d.extract(s)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(values) == 0 {
+		return nil, errParsingFailure
+	}
+
+	return d.resolve(values), nil
+}
+
+// Raw returns the raw tokenizer used to generate the actual parser.
+func (d *Dissector) Raw() string {
+	return d.raw
+}
+
+// extract walks the delimiters and returns the raw value of every field.
+func (d *Dissector) extract(s string) ([]string, error) {
+	p := d.parser
+	values := make([]string, len(p.fields))
+
+	// We assume a hard match on the first delimiter.
+	first := p.delimiters[0]
+	if !strings.HasPrefix(s, first) {
+		return nil, fmt.Errorf(
+			"could not find beginning delimiter: `%s` in remaining: `%s`, (offset: %d)",
+			first, s, 0,
+		)
+	}
+	offset := len(first)
+
+	for i, f := range p.fields {
+		next := p.delimiters[i+1]
+
+		// The last field consumes the remaining content.
+		if i == len(p.fields)-1 && len(next) == 0 {
+			values[i] = s[offset:]
+			break
+		}
+
+		end := strings.Index(s[offset:], next)
+		if end == -1 {
+			return nil, fmt.Errorf(
+				"could not find delimiter: `%s` in remaining: `%s`, (offset: %d)",
+				next, s[offset:], offset,
+			)
+		}
+		end += offset
+
+		values[i] = s[offset:end]
+		offset = end + len(next)
+
+		// Greedy fields consume repeated delimiters used as padding.
+		if f.greedy && len(next) > 0 {
+			for strings.HasPrefix(s[offset:], next) {
+				offset += len(next)
+			}
+		}
+	}
+
+	return values, nil
+}
+
+func (d *Dissector) resolve(values []string) Map {
+	m := make(Map, len(values))
+	refs := make(map[string]string)
+	appends := make(map[string][]field)
+
+	for i, f := range d.parser.fields {
+		if len(f.key) > 0 && f.kind != indirectField {
+			refs[f.key] = values[i]
+		}
+
+		switch f.kind {
+		case normalField:
+			m[f.key] = values[i]
+		case appendField:
+			appends[f.key] = append(appends[f.key], f)
+		}
+	}
+
+	for key, fields := range appends {
+		sort.SliceStable(fields, func(i, j int) bool {
+			return fields[i].ordinal < fields[j].ordinal
+		})
+
+		parts := make([]string, len(fields))
+		for i, f := range fields {
+			parts[i] = values[f.id]
+		}
+		m[key] = strings.Join(parts, defaultJoinString)
+	}
+
+	for i, f := range d.parser.fields {
+		if f.kind != indirectField {
+			continue
+		}
+
+		if key, found := refs[f.key]; found && len(key) > 0 {
+			m[key] = values[i]
+		}
+	}
+
+	return m
+}
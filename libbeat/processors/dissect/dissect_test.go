@@ -0,0 +1,141 @@
+package dissect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDissect(t *testing.T) {
+	tests := []struct {
+		name     string
+		tok      string
+		msg      string
+		expected Map
+		fail     bool
+	}{
+		{
+			name:     "simple",
+			tok:      "%{key1} %{key2}",
+			msg:      "hello world",
+			expected: Map{"key1": "hello", "key2": "world"},
+		},
+		{
+			name:     "with leading and trailing delimiters",
+			tok:      "[%{level}] %{msg}.",
+			msg:      "[INFO] started.",
+			expected: Map{"level": "INFO", "msg": "started"},
+		},
+		{
+			name:     "last field consumes remaining content",
+			tok:      "%{ts} %{msg}",
+			msg:      "2018-06-12 something went wrong",
+			expected: Map{"ts": "2018-06-12", "msg": "something went wrong"},
+		},
+		{
+			name:     "append",
+			tok:      "%{ts} %{+ts} [%{level}] %{msg}",
+			msg:      "2018-06-12 10:00:00 [ERROR] disk full",
+			expected: Map{"ts": "2018-06-12 10:00:00", "level": "ERROR", "msg": "disk full"},
+		},
+		{
+			name:     "append with ordinal",
+			tok:      "%{+name/2} %{+name/1}",
+			msg:      "doe john",
+			expected: Map{"name": "john doe"},
+		},
+		{
+			name:     "skip fields",
+			tok:      "%{} %{?ignored} %{key}",
+			msg:      "a b c",
+			expected: Map{"key": "c"},
+		},
+		{
+			name:     "indirect field",
+			tok:      "%{?key}=%{&key}",
+			msg:      "hello=world",
+			expected: Map{"hello": "world"},
+		},
+		{
+			name:     "pointer field",
+			tok:      "%{*key} %{&key}",
+			msg:      "hello world",
+			expected: Map{"hello": "world"},
+		},
+		{
+			name:     "greedy padding",
+			tok:      "%{level->} %{msg}",
+			msg:      "INFO     started",
+			expected: Map{"level": "INFO", "msg": "started"},
+		},
+		{
+			name:     "greedy skip",
+			tok:      "%{a} %{->} %{b}",
+			msg:      "x y    z",
+			expected: Map{"a": "x", "b": "z"},
+		},
+		{
+			name:     "multiline",
+			tok:      "%{key1}\n%{key2}",
+			msg:      "hello\nworld",
+			expected: Map{"key1": "hello", "key2": "world"},
+		},
+		{
+			name: "missing delimiter",
+			tok:  "%{key1} [%{key2}]",
+			msg:  "hello world",
+			fail: true,
+		},
+		{
+			name: "beginning delimiter must match",
+			tok:  "[%{key1}]",
+			msg:  "hello [world]",
+			fail: true,
+		},
+		{
+			name: "empty message",
+			tok:  "%{key1}",
+			msg:  "",
+			fail: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			d, err := New(test.tok)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			m, err := d.Dissect(test.msg)
+			if test.fail {
+				assert.Error(t, err)
+				return
+			}
+
+			if assert.NoError(t, err) {
+				assert.Equal(t, test.expected, m)
+			}
+		})
+	}
+}
+
+func TestInvalidTokenizer(t *testing.T) {
+	tests := []struct {
+		name string
+		tok  string
+	}{
+		{name: "no fields", tok: "hello world"},
+		{name: "mixed append indirect", tok: "%{+&key}"},
+		{name: "mixed indirect append", tok: "%{&+key}"},
+		{name: "empty append key", tok: "%{+}"},
+		{name: "unknown indirect reference", tok: "%{a} %{&b}"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(test.tok)
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package dissect
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type fieldKind int
+
+const (
+	normalField   fieldKind = iota // %{key}
+	skipField                      // %{} or %{?key}
+	appendField                    // %{+key} or %{+key/2}
+	indirectField                  // %{&key}
+	pointerField                   // %{*key}
+)
+
+var fieldKindNames = map[fieldKind]string{
+	normalField:   "normal",
+	skipField:     "skip",
+	appendField:   "append",
+	indirectField: "indirect",
+	pointerField:  "pointer",
+}
+
+func (k fieldKind) String() string {
+	return fieldKindNames[k]
+}
+
+// field is a single key extracted from the tokenizer.
+type field struct {
+	id      int
+	kind    fieldKind
+	key     string
+	ordinal int
+	greedy  bool
+}
+
+func (f field) String() string {
+	return fmt.Sprintf("field: %s, kind: %s, ordinal: %d, greedy: %v", f.key, f.kind, f.ordinal, f.greedy)
+}
+
+// newField parses the raw key found between `%{` and `}` in the tokenizer.
+func newField(id int, rawKey string) (field, error) {
+	if len(rawKey) == 0 {
+		return field{id: id, kind: skipField}, nil
+	}
+
+	if rawKey == greedySuffix {
+		return field{id: id, kind: skipField, greedy: true}, nil
+	}
+
+	key, ordinal, greedy := extractKeyParts(rawKey)
+
+	if strings.HasPrefix(key, appendIndirectPrefix) {
+		return field{}, errMixedPrefixAppendIndirect
+	}
+	if strings.HasPrefix(key, indirectAppendPrefix) {
+		return field{}, errMixedPrefixIndirectAppend
+	}
+
+	f := field{id: id, ordinal: ordinal, greedy: greedy}
+	switch {
+	case strings.HasPrefix(key, skipFieldPrefix):
+		f.kind = skipField
+		f.key = key[len(skipFieldPrefix):]
+		return f, nil
+	case strings.HasPrefix(key, appendFieldPrefix):
+		f.kind = appendField
+		f.key = key[len(appendFieldPrefix):]
+	case strings.HasPrefix(key, indirectFieldPrefix):
+		f.kind = indirectField
+		f.key = key[len(indirectFieldPrefix):]
+	case strings.HasPrefix(key, pointerFieldPrefix):
+		f.kind = pointerField
+		f.key = key[len(pointerFieldPrefix):]
+	default:
+		f.kind = normalField
+		f.key = key
+	}
+
+	if len(f.key) == 0 {
+		return field{}, errEmptyKey
+	}
+	return f, nil
+}
+
+// extractKeyParts splits a raw key into the name, the optional append ordinal
+// and the greedy suffix.
+func extractKeyParts(rawKey string) (key string, ordinal int, greedy bool) {
+	m := suffixRE.FindStringSubmatch(rawKey)
+	if m == nil {
+		return rawKey, 0, false
+	}
+
+	if len(m[3]) > 0 {
+		ordinal, _ = strconv.Atoi(m[3])
+	}
+	return m[1], ordinal, m[4] == greedySuffix
+}
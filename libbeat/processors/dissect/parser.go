@@ -0,0 +1,83 @@
+package dissect
+
+import (
+	"fmt"
+)
+
+// parser extracts the delimiters and the fields from a tokenizer. A tokenizer
+// with N fields always produces N+1 delimiters, the first and the last
+// delimiter can be empty.
+type parser struct {
+	delimiters []string
+	fields     []field
+}
+
+func newParser(tokenizer string) (*parser, error) {
+	matches := delimiterRE.FindAllStringSubmatchIndex(tokenizer, -1)
+	if len(matches) == 0 {
+		return nil, errInvalidTokenizer
+	}
+
+	p := &parser{
+		delimiters: make([]string, 0, len(matches)+1),
+		fields:     make([]field, 0, len(matches)),
+	}
+
+	var last int
+	for id, m := range matches {
+		p.delimiters = append(p.delimiters, tokenizer[m[2]:m[3]])
+
+		f, err := newField(id, tokenizer[m[4]:m[5]])
+		if err != nil {
+			return nil, err
+		}
+		p.fields = append(p.fields, f)
+		last = m[1]
+	}
+	p.delimiters = append(p.delimiters, tokenizer[last:])
+
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	p.promoteAppendKeys()
+
+	return p, nil
+}
+
+// validate makes sure every indirect field references a field that is
+// defined in the same tokenizer.
+func (p *parser) validate() error {
+	names := map[string]bool{}
+	for _, f := range p.fields {
+		switch f.kind {
+		case normalField, skipField, pointerField:
+			if len(f.key) > 0 {
+				names[f.key] = true
+			}
+		}
+	}
+
+	for _, f := range p.fields {
+		if f.kind == indirectField && !names[f.key] {
+			return fmt.Errorf("indirect field `%s` does not reference any defined field", f.key)
+		}
+	}
+	return nil
+}
+
+// promoteAppendKeys converts normal fields into append fields when the same
+// key is also used with the append modifier, e.g. `%{ts} %{+ts}`.
+func (p *parser) promoteAppendKeys() {
+	appendKeys := map[string]bool{}
+	for _, f := range p.fields {
+		if f.kind == appendField {
+			appendKeys[f.key] = true
+		}
+	}
+
+	for i, f := range p.fields {
+		if f.kind == normalField && appendKeys[f.key] {
+			p.fields[i].kind = appendField
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package dissect
+
+import (
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+const flagParsingError = "dissect_parsing_error"
+
+type processor struct {
+	config config
+}
+
+func init() {
+	processors.RegisterPlugin("dissect", newProcessor)
+}
+
+func newProcessor(c *common.Config) (processors.Processor, error) {
+	cfgwarn.Beta("Beta dissect processor is used.")
+	config := defaultConfig
+	err := c.Unpack(&config)
+	if err != nil {
+		return nil, errors.Wrap(err, "fail to unpack the dissect configuration")
+	}
+
+	p := &processor{config: config}
+	return p, nil
+}
+
+// Run takes the event and will apply the tokenizer on the configured field.
+func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
+	v, err := event.GetValue(p.config.Field)
+	if err != nil {
+		return event, err
+	}
+
+	s, ok := v.(string)
+	if !ok {
+		return event, fmt.Errorf("field is not a string, value: `%v`, field: `%s`", v, p.config.Field)
+	}
+
+	m, err := p.config.Tokenizer.Dissect(s)
+	if err != nil {
+		if err := common.AddTags(event.Fields, []string{flagParsingError}); err != nil {
+			return event, errors.Wrap(err, "cannot add new flag to the event")
+		}
+		return event, err
+	}
+
+	if err := p.mapper(event, m); err != nil {
+		if err := common.AddTags(event.Fields, []string{flagParsingError}); err != nil {
+			return event, errors.Wrap(err, "cannot add new flag to the event")
+		}
+		return event, err
+	}
+
+	return event, nil
+}
+
+// mapper writes the extracted keys into the target prefix. When no prefix is
+// configured the keys are written at the root of the event, existing fields
+// are never overwritten in that case.
+func (p *processor) mapper(event *beat.Event, m Map) error {
+	prefix := p.config.TargetPrefix
+	if len(prefix) == 0 {
+		for k := range m {
+			if exists, _ := event.Fields.HasKey(k); exists {
+				return fmt.Errorf("cannot override existing key: `%s`", k)
+			}
+		}
+	} else {
+		prefix += "."
+	}
+
+	for k, v := range m {
+		if _, err := event.PutValue(prefix+k, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (p *processor) String() string {
+	return "dissect=" + p.config.Tokenizer.Raw() +
+		",field=" + p.config.Field +
+		",target_prefix=" + p.config.TargetPrefix
+}
@@ -0,0 +1,138 @@
+package dissect
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestProcessor(t *testing.T) {
+	tests := []struct {
+		name   string
+		c      map[string]interface{}
+		fields common.MapStr
+		values map[string]string
+	}{
+		{
+			name:   "default field and prefix",
+			c:      map[string]interface{}{"tokenizer": "%{key1} %{key2}"},
+			fields: common.MapStr{"message": "hello world"},
+			values: map[string]string{"dissect.key1": "hello", "dissect.key2": "world"},
+		},
+		{
+			name:   "custom field",
+			c:      map[string]interface{}{"tokenizer": "%{key1} %{key2}", "field": "msg"},
+			fields: common.MapStr{"msg": "hello world"},
+			values: map[string]string{"dissect.key1": "hello", "dissect.key2": "world"},
+		},
+		{
+			name:   "custom target prefix",
+			c:      map[string]interface{}{"tokenizer": "%{key1} %{key2}", "target_prefix": "extracted"},
+			fields: common.MapStr{"message": "hello world"},
+			values: map[string]string{"extracted.key1": "hello", "extracted.key2": "world"},
+		},
+		{
+			name:   "root prefix",
+			c:      map[string]interface{}{"tokenizer": "%{key1} %{key2}", "target_prefix": ""},
+			fields: common.MapStr{"message": "hello world"},
+			values: map[string]string{"key1": "hello", "key2": "world"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := common.NewConfigFrom(test.c)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			processor, err := newProcessor(c)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			e := &beat.Event{Fields: test.fields}
+			newEvent, err := processor.Run(e)
+			if !assert.NoError(t, err) {
+				return
+			}
+
+			for field, value := range test.values {
+				v, err := newEvent.GetValue(field)
+				if assert.NoError(t, err) {
+					assert.Equal(t, value, v)
+				}
+			}
+		})
+	}
+}
+
+func TestFieldDoesNotExist(t *testing.T) {
+	c, err := common.NewConfigFrom(map[string]interface{}{"tokenizer": "%{key1} %{key2}"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	processor, err := newProcessor(c)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	e := &beat.Event{Fields: common.MapStr{"hello": "world"}}
+	_, err = processor.Run(e)
+	assert.Error(t, err)
+}
+
+func TestFieldAlreadyExist(t *testing.T) {
+	c, err := common.NewConfigFrom(map[string]interface{}{
+		"tokenizer":     "%{key} %{message}",
+		"target_prefix": "",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	processor, err := newProcessor(c)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	e := &beat.Event{Fields: common.MapStr{"message": "hello world"}}
+	_, err = processor.Run(e)
+	assert.Error(t, err)
+	assert.Equal(t, "hello world", e.Fields["message"])
+}
+
+func TestTagsOnParsingFailure(t *testing.T) {
+	c, err := common.NewConfigFrom(map[string]interface{}{"tokenizer": "[%{level}] %{msg}"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	processor, err := newProcessor(c)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	e := &beat.Event{Fields: common.MapStr{"message": "hello world"}}
+	newEvent, err := processor.Run(e)
+	assert.Error(t, err)
+
+	tags, err := newEvent.GetValue("tags")
+	if assert.NoError(t, err) {
+		assert.Equal(t, []string{flagParsingError}, tags)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	c, err := common.NewConfigFrom(map[string]interface{}{"tokenizer": "%{+&key}"})
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	_, err = newProcessor(c)
+	assert.Error(t, err)
+}
@@ -0,0 +1,42 @@
+package fingerprint
+
+import (
+	"fmt"
+	"strings"
+)
+
+type config struct {
+	Fields        []string `config:"fields" validate:"required"`
+	Method        method   `config:"method"`
+	Encoding      encoding `config:"encoding"`
+	TargetField   string   `config:"target_field"`
+	IgnoreMissing bool     `config:"ignore_missing"`
+}
+
+func defaultConfig() config {
+	return config{
+		Method:      methods["sha1"],
+		Encoding:    encodings["hex"],
+		TargetField: "@metadata._id",
+	}
+}
+
+// Unpack selects the hash method by name.
+func (m *method) Unpack(s string) error {
+	found, exists := methods[strings.ToLower(s)]
+	if !exists {
+		return fmt.Errorf("invalid method '%v', must be one of %v", s, names(methods))
+	}
+	*m = found
+	return nil
+}
+
+// Unpack selects the encoding by name.
+func (e *encoding) Unpack(s string) error {
+	found, exists := encodings[strings.ToLower(s)]
+	if !exists {
+		return fmt.Errorf("invalid encoding '%v', must be one of %v", s, names(encodings))
+	}
+	*e = found
+	return nil
+}
@@ -0,0 +1,122 @@
+package fingerprint
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+const processorName = "fingerprint"
+
+type fingerprint struct {
+	config config
+	fields []string
+}
+
+func init() {
+	processors.RegisterPlugin(processorName, newFromConfig)
+}
+
+func newFromConfig(c *common.Config) (processors.Processor, error) {
+	config := defaultConfig()
+	if err := c.Unpack(&config); err != nil {
+		return nil, errors.Wrap(err, "fail to unpack the fingerprint configuration")
+	}
+
+	return newFingerprint(config)
+}
+
+func newFingerprint(c config) (*fingerprint, error) {
+	cfgwarn.Beta("The fingerprint processor is beta.")
+
+	if len(c.Fields) == 0 {
+		return nil, errors.New("fingerprint requires at least one field")
+	}
+
+	// Sort the fields so the fingerprint doesn't depend on the order they
+	// are configured in.
+	fields := make([]string, len(c.Fields))
+	copy(fields, c.Fields)
+	sort.Strings(fields)
+
+	return &fingerprint{config: c, fields: fields}, nil
+}
+
+// Run hashes the configured fields and writes the encoded hash to the target
+// field.
+func (p *fingerprint) Run(event *beat.Event) (*beat.Event, error) {
+	h := p.config.Method.newHash()
+	if err := p.writeFields(h, event); err != nil {
+		return event, errors.Wrap(err, "failed to compute fingerprint")
+	}
+
+	value := p.config.Encoding.encode(h.Sum(nil))
+	if _, err := event.PutValue(p.config.TargetField, value); err != nil {
+		return event, errors.Wrapf(err, "failed to put fingerprint into %v", p.config.TargetField)
+	}
+	return event, nil
+}
+
+// writeFields writes every field as '|field|value' followed by a terminating
+// '|' to the hash. Missing fields are skipped when ignore_missing is set.
+func (p *fingerprint) writeFields(w io.Writer, event *beat.Event) error {
+	for _, field := range p.fields {
+		value, err := event.GetValue(field)
+		if err != nil {
+			if p.config.IgnoreMissing && errors.Cause(err) == common.ErrKeyNotFound {
+				continue
+			}
+			return errors.Wrapf(err, "failed to find field %v in event", field)
+		}
+
+		s, err := formatValue(value)
+		if err != nil {
+			return errors.Wrapf(err, "cannot use field %v", field)
+		}
+		fmt.Fprintf(w, "|%v|%v", field, s)
+	}
+	io.WriteString(w, "|")
+	return nil
+}
+
+// formatValue returns a deterministic string representation of a scalar
+// value or a list of scalar values.
+func formatValue(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano), nil
+	case common.Time:
+		return time.Time(v).UTC().Format(time.RFC3339Nano), nil
+	case common.MapStr, map[string]interface{}:
+		return "", errors.New("non-scalar fields are not supported")
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, elem := range v {
+			s, err := formatValue(elem)
+			if err != nil {
+				return "", err
+			}
+			parts[i] = s
+		}
+		return "[" + strings.Join(parts, ",") + "]", nil
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
+
+func (p *fingerprint) String() string {
+	return fmt.Sprintf("%v=[method=%v, encoding=%v, fields=%v, target_field=%v, ignore_missing=%v]",
+		processorName, p.config.Method.name, p.config.Encoding.name,
+		strings.Join(p.fields, ","), p.config.TargetField, p.config.IgnoreMissing)
+}
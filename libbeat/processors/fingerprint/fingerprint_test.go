@@ -0,0 +1,167 @@
+package fingerprint
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func newTestFingerprint(t *testing.T, settings map[string]interface{}) *fingerprint {
+	c, err := common.NewConfigFrom(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newFromConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*fingerprint)
+}
+
+func newTestEvent() *beat.Event {
+	return &beat.Event{
+		Fields: common.MapStr{
+			"field1": "foo",
+			"field2": 42,
+			"nested": common.MapStr{"key": "value"},
+		},
+	}
+}
+
+func TestMethods(t *testing.T) {
+	tests := []struct {
+		settings map[string]interface{}
+		expected string
+	}{
+		{
+			map[string]interface{}{},
+			"b60a03d615880ee4530dc10a4f5109b0766e3ea7",
+		},
+		{
+			map[string]interface{}{"method": "sha256"},
+			"53dfa2a1614febb8180d7c5360b4ee524084fe2d61751fa0ca646096ad1cd019",
+		},
+		{
+			map[string]interface{}{"encoding": "base64"},
+			"tgoD1hWIDuRTDcEKT1EJsHZuPqc=",
+		},
+	}
+
+	for _, test := range tests {
+		settings := test.settings
+		settings["fields"] = []string{"field2", "field1"}
+		p := newTestFingerprint(t, settings)
+
+		event, err := p.Run(newTestEvent())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		id, err := event.GetValue("@metadata._id")
+		if assert.NoError(t, err) {
+			assert.Equal(t, test.expected, id, "%v", p)
+		}
+	}
+}
+
+func TestXXHash(t *testing.T) {
+	p := newTestFingerprint(t, map[string]interface{}{
+		"fields": []string{"field1", "field2"},
+		"method": "xxhash",
+	})
+
+	first, err := p.Run(newTestEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := p.Run(newTestEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	id := first.Meta["_id"]
+	assert.Len(t, id, 16)
+	assert.Equal(t, id, second.Meta["_id"])
+}
+
+func TestDifferentValues(t *testing.T) {
+	p := newTestFingerprint(t, map[string]interface{}{
+		"fields": []string{"field1"},
+	})
+
+	a, err := p.Run(&beat.Event{Fields: common.MapStr{"field1": "a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := p.Run(&beat.Event{Fields: common.MapStr{"field1": "b"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.NotEqual(t, a.Meta["_id"], b.Meta["_id"])
+}
+
+func TestTargetField(t *testing.T) {
+	p := newTestFingerprint(t, map[string]interface{}{
+		"fields":       []string{"field1"},
+		"target_field": "fingerprint",
+	})
+
+	event, err := p.Run(newTestEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Nil(t, event.Meta)
+	v, err := event.GetValue("fingerprint")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, v)
+}
+
+func TestMissingField(t *testing.T) {
+	settings := map[string]interface{}{
+		"fields": []string{"field1", "missing"},
+	}
+
+	p := newTestFingerprint(t, settings)
+	_, err := p.Run(newTestEvent())
+	assert.Error(t, err)
+
+	settings["ignore_missing"] = true
+	p = newTestFingerprint(t, settings)
+	event, err := p.Run(newTestEvent())
+	if assert.NoError(t, err) {
+		assert.NotEmpty(t, event.Meta["_id"])
+	}
+}
+
+func TestNonScalarField(t *testing.T) {
+	p := newTestFingerprint(t, map[string]interface{}{
+		"fields": []string{"nested"},
+	})
+
+	_, err := p.Run(newTestEvent())
+	assert.Error(t, err)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []map[string]interface{}{
+		{},
+		{"fields": []string{"field1"}, "method": "crc32"},
+		{"fields": []string{"field1"}, "encoding": "base85"},
+	}
+
+	for _, settings := range tests {
+		c, err := common.NewConfigFrom(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = newFromConfig(c)
+		assert.Error(t, err, "%v", settings)
+	}
+}
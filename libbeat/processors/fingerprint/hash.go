@@ -0,0 +1,52 @@
+package fingerprint
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/base64"
+	"encoding/hex"
+	"hash"
+	"reflect"
+	"sort"
+
+	"github.com/cespare/xxhash"
+)
+
+type method struct {
+	name    string
+	newHash func() hash.Hash
+}
+
+type encoding struct {
+	name   string
+	encode func([]byte) string
+}
+
+var methods = map[string]method{
+	"md5":    {"md5", md5.New},
+	"sha1":   {"sha1", sha1.New},
+	"sha256": {"sha256", sha256.New},
+	"sha384": {"sha384", sha512.New384},
+	"sha512": {"sha512", sha512.New},
+	"xxhash": {"xxhash", func() hash.Hash { return xxhash.New() }},
+}
+
+var encodings = map[string]encoding{
+	"hex":    {"hex", hex.EncodeToString},
+	"base32": {"base32", base32.StdEncoding.EncodeToString},
+	"base64": {"base64", base64.StdEncoding.EncodeToString},
+}
+
+// names returns the sorted keys of a map of methods or encodings.
+func names(m interface{}) []string {
+	keys := reflect.ValueOf(m).MapKeys()
+	out := make([]string, len(keys))
+	for i, k := range keys {
+		out[i] = k.String()
+	}
+	sort.Strings(out)
+	return out
+}
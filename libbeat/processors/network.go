@@ -0,0 +1,147 @@
+package processors
+
+import (
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// netContainsFunc reports whether an IP address is part of a network.
+type netContainsFunc func(ip net.IP) bool
+
+// namedNetwork is a network referenced in a network condition. It's either a
+// CIDR, a single IP address or one of the named ranges.
+type namedNetwork struct {
+	name     string
+	contains netContainsFunc
+}
+
+func (n namedNetwork) String() string {
+	return n.name
+}
+
+var privateNetworks = mustParseCIDRs(
+	// RFC1918
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	// RFC4193
+	"fc00::/7",
+)
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	nets := make([]*net.IPNet, len(cidrs))
+	for i, cidr := range cidrs {
+		_, n, err := net.ParseCIDR(cidr)
+		if err != nil {
+			panic(err)
+		}
+		nets[i] = n
+	}
+	return nets
+}
+
+func isPrivateNetwork(ip net.IP) bool {
+	for _, n := range privateNetworks {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+func isLinkLocal(ip net.IP) bool {
+	return ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast()
+}
+
+func isPublicNetwork(ip net.IP) bool {
+	return ip.IsGlobalUnicast() && !isPrivateNetwork(ip)
+}
+
+// namedNetworks contains the ranges that can be referenced by name in the
+// network condition.
+var namedNetworks = map[string]netContainsFunc{
+	"loopback":                  net.IP.IsLoopback,
+	"global_unicast":            net.IP.IsGlobalUnicast,
+	"unicast":                   func(ip net.IP) bool { return ip.IsGlobalUnicast() || ip.IsLinkLocalUnicast() },
+	"link_local_unicast":        net.IP.IsLinkLocalUnicast,
+	"interface_local_multicast": net.IP.IsInterfaceLocalMulticast,
+	"link_local_multicast":      net.IP.IsLinkLocalMulticast,
+	"link_local":                isLinkLocal,
+	"multicast":                 net.IP.IsMulticast,
+	"unspecified":               net.IP.IsUnspecified,
+	"private":                   isPrivateNetwork,
+	"public":                    isPublicNetwork,
+}
+
+// parseNetwork parses a CIDR, an IP address or a named range.
+func parseNetwork(s string) (namedNetwork, error) {
+	if contains, found := namedNetworks[s]; found {
+		return namedNetwork{name: s, contains: contains}, nil
+	}
+
+	if strings.Contains(s, "/") {
+		_, n, err := net.ParseCIDR(s)
+		if err != nil {
+			return namedNetwork{}, fmt.Errorf("invalid network '%v': %v", s, err)
+		}
+		return namedNetwork{name: n.String(), contains: n.Contains}, nil
+	}
+
+	ip := net.ParseIP(s)
+	if ip == nil {
+		return namedNetwork{}, fmt.Errorf("invalid network '%v': not a CIDR, IP "+
+			"address or named network", s)
+	}
+	return namedNetwork{name: ip.String(), contains: ip.Equal}, nil
+}
+
+// networkFields groups the values of a ConditionFields by field name. Lists
+// of networks get expanded to indexed keys (e.g. 'source.ip.0') by
+// ConditionFields, so numeric suffixes are removed again.
+func networkFields(fields map[string]interface{}) (map[string][]string, error) {
+	out := map[string][]string{}
+	for key, value := range fields {
+		s, err := extractString(value)
+		if err != nil {
+			return nil, fmt.Errorf("unexpected type %T of %v in network condition", value, value)
+		}
+
+		if idx := strings.LastIndex(key, "."); idx > 0 {
+			if _, err := strconv.Atoi(key[idx+1:]); err == nil {
+				key = key[:idx]
+			}
+		}
+		out[key] = append(out[key], s)
+	}
+	return out, nil
+}
+
+// extractIPs returns the IP addresses contained in a field value. Values that
+// are not valid IP addresses are ignored.
+func extractIPs(value interface{}) []net.IP {
+	switch v := value.(type) {
+	case net.IP:
+		return []net.IP{v}
+	case string:
+		if ip := net.ParseIP(v); ip != nil {
+			return []net.IP{ip}
+		}
+	case []string:
+		var ips []net.IP
+		for _, s := range v {
+			if ip := net.ParseIP(s); ip != nil {
+				ips = append(ips, ip)
+			}
+		}
+		return ips
+	case []interface{}:
+		var ips []net.IP
+		for _, elem := range v {
+			ips = append(ips, extractIPs(elem)...)
+		}
+		return ips
+	}
+	return nil
+}
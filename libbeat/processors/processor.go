@@ -23,6 +23,18 @@ func New(config PluginConfig) (*Processors, error) {
 
 	for _, processor := range config {
 
+		// The if/then/else processor is the only processor defined by multiple
+		// top-level keys.
+		if _, ok := processor["if"]; ok {
+			plugin, err := NewIfThenElseProcessor(processor)
+			if err != nil {
+				return nil, err
+			}
+
+			procs.add(plugin)
+			continue
+		}
+
 		if len(processor) != 1 {
 			return nil, fmt.Errorf("each processor needs to have exactly one action, but found %d actions",
 				len(processor))
@@ -601,3 +601,158 @@ func TestDropMissingFields(t *testing.T) {
 
 	assert.Equal(t, expectedEvent, processedEvent.Fields)
 }
+
+func TestIfThenElse(t *testing.T) {
+	logp.TestingSetup()
+
+	yml := []map[string]interface{}{
+		{
+			"if": map[string]interface{}{
+				"equals": map[string]string{
+					"type": "process",
+				},
+			},
+			"then": []map[string]interface{}{
+				{
+					"drop_fields": map[string]interface{}{
+						"fields": []string{"proc.cpu"},
+					},
+				},
+				{
+					"rename": map[string]interface{}{
+						"fields": []map[string]string{{"from": "proc", "to": "process"}},
+					},
+				},
+			},
+			"else": []map[string]interface{}{
+				{
+					"drop_event": map[string]interface{}{},
+				},
+			},
+		},
+	}
+
+	processors := GetProcessors(t, yml)
+
+	event := &beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"proc": common.MapStr{
+				"cpu": common.MapStr{
+					"total": 79390,
+				},
+				"cmdline": "/sbin/launchd",
+			},
+			"type": "process",
+		},
+	}
+
+	processedEvent := processors.Run(event)
+
+	expectedEvent := common.MapStr{
+		"process": common.MapStr{
+			"cmdline": "/sbin/launchd",
+		},
+		"type": "process",
+	}
+	assert.Equal(t, expectedEvent, processedEvent.Fields)
+
+	otherEvent := &beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"type": "filesystem",
+		},
+	}
+	assert.Nil(t, processors.Run(otherEvent))
+}
+
+func TestIfThenWithoutElse(t *testing.T) {
+	logp.TestingSetup()
+
+	yml := []map[string]interface{}{
+		{
+			"if": map[string]interface{}{
+				"has_fields": []string{"proc"},
+			},
+			"then": map[string]interface{}{
+				"drop_event": map[string]interface{}{},
+			},
+		},
+	}
+
+	processors := GetProcessors(t, yml)
+
+	event := &beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"type": "filesystem",
+		},
+	}
+
+	dropped := &beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"proc": common.MapStr{
+				"cmdline": "/sbin/launchd",
+			},
+		},
+	}
+	assert.Nil(t, processors.Run(dropped))
+
+	processedEvent := processors.Run(event)
+	assert.Equal(t, common.MapStr{"type": "filesystem"}, processedEvent.Fields)
+}
+
+func TestBadIfThenElseConfig(t *testing.T) {
+	logp.TestingSetup()
+
+	tests := map[string]map[string]interface{}{
+		"missing then": {
+			"if": map[string]interface{}{
+				"has_fields": []string{"proc"},
+			},
+		},
+		"invalid condition": {
+			"if": map[string]interface{}{
+				"unknown": []string{"proc"},
+			},
+			"then": []map[string]interface{}{
+				{"drop_event": map[string]interface{}{}},
+			},
+		},
+		"unknown processor": {
+			"if": map[string]interface{}{
+				"has_fields": []string{"proc"},
+			},
+			"then": []map[string]interface{}{
+				{"unknown": map[string]interface{}{}},
+			},
+		},
+		"unexpected option": {
+			"if": map[string]interface{}{
+				"has_fields": []string{"proc"},
+			},
+			"then": []map[string]interface{}{
+				{"drop_event": map[string]interface{}{}},
+			},
+			"drop_fields": map[string]interface{}{
+				"fields": []string{"proc"},
+			},
+		},
+	}
+
+	for name, yml := range tests {
+		t.Run(name, func(t *testing.T) {
+			c := map[string]*common.Config{}
+			for name, actionYml := range yml {
+				actionConfig, err := common.NewConfigFrom(actionYml)
+				assert.Nil(t, err)
+
+				c[name] = actionConfig
+			}
+
+			_, err := processors.New(processors.PluginConfig{c})
+			assert.NotNil(t, err)
+		})
+	}
+}
@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type rateLimitConfig struct {
+	Limit  rate     `config:"limit" validate:"required"`
+	Burst  int      `config:"burst" validate:"min=0"`
+	Fields []string `config:"fields"`
+	GC     gcConfig `config:"gc"`
+}
+
+type gcConfig struct {
+	Interval time.Duration `config:"interval" validate:"positive"`
+}
+
+func defaultRateLimitConfig() rateLimitConfig {
+	return rateLimitConfig{
+		GC: gcConfig{
+			Interval: 1 * time.Minute,
+		},
+	}
+}
+
+type sampleConfig struct {
+	Every      int      `config:"every" validate:"min=0"`
+	Percentage float64  `config:"percentage" validate:"min=0, max=100"`
+	Fields     []string `config:"fields"`
+}
+
+func (c *sampleConfig) Validate() error {
+	switch {
+	case c.Every > 0 && c.Percentage > 0:
+		return errors.New("only one of every and percentage can be set")
+	case c.Every == 0 && c.Percentage == 0:
+		return errors.New("one of every or percentage must be set")
+	case c.Percentage > 0 && len(c.Fields) == 0:
+		return errors.New("percentage sampling requires fields to hash")
+	}
+	return nil
+}
+
+var rateUnits = map[string]time.Duration{
+	"s": time.Second,
+	"m": time.Minute,
+	"h": time.Hour,
+}
+
+// rate is the number of events allowed per time unit, configured as
+// '<value>/<unit>' (e.g. '1000/m').
+type rate struct {
+	value float64
+	unit  time.Duration
+	raw   string
+}
+
+// Unpack parses a rate like '100/s', '1000/m' or '50000/h'.
+func (r *rate) Unpack(s string) error {
+	parts := strings.Split(s, "/")
+	if len(parts) != 2 {
+		return fmt.Errorf("invalid rate '%v', expected format <value>/<unit>", s)
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(parts[0]), 64)
+	if err != nil || value <= 0 {
+		return fmt.Errorf("invalid rate value '%v', must be a positive number", parts[0])
+	}
+
+	unit, found := rateUnits[strings.TrimSpace(parts[1])]
+	if !found {
+		return fmt.Errorf("invalid rate unit '%v', must be one of s, m or h", parts[1])
+	}
+
+	*r = rate{value: value, unit: unit, raw: s}
+	return nil
+}
+
+// perSecond returns the number of events allowed per second.
+func (r rate) perSecond() float64 {
+	return r.value / r.unit.Seconds()
+}
+
+func (r rate) String() string {
+	return r.raw
+}
@@ -0,0 +1,28 @@
+package ratelimit
+
+import (
+	"fmt"
+
+	"github.com/cespare/xxhash"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// hashFields computes a hash of the values of fields. Missing fields are
+// hashed as empty values, so all events without the fields share a key.
+func hashFields(event *beat.Event, fields []string) (uint64, error) {
+	if len(fields) == 0 {
+		return 0, nil
+	}
+
+	h := xxhash.New()
+	for _, field := range fields {
+		value, err := event.GetValue(field)
+		if err != nil && err != common.ErrKeyNotFound {
+			return 0, err
+		}
+		fmt.Fprintf(h, "|%v|%v", field, value)
+	}
+	return h.Sum64(), nil
+}
@@ -0,0 +1,30 @@
+package ratelimit
+
+import (
+	"github.com/elastic/beats/libbeat/monitoring"
+)
+
+var (
+	rateLimitMetrics = newMetricSet(monitoring.Default.NewRegistry("processor.rate_limit"))
+	sampleMetrics    = newMetricSet(monitoring.Default.NewRegistry("processor.sample"))
+)
+
+// metricSet contains the counters reported for all processors of a type.
+type metricSet struct {
+	processed *monitoring.Int // events passed to the processor
+	dropped   *monitoring.Int // events dropped by the processor
+}
+
+func newMetricSet(reg *monitoring.Registry) *metricSet {
+	return &metricSet{
+		processed: monitoring.NewInt(reg, "events.processed"),
+		dropped:   monitoring.NewInt(reg, "events.dropped"),
+	}
+}
+
+func (m *metricSet) record(dropped bool) {
+	m.processed.Inc()
+	if dropped {
+		m.dropped.Inc()
+	}
+}
@@ -0,0 +1,68 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+type rateLimit struct {
+	config rateLimitConfig
+	bucket *tokenBucket
+	clock  func() time.Time
+	log    *logp.Logger
+}
+
+func init() {
+	processors.RegisterPlugin("rate_limit", newRateLimitFromConfig)
+}
+
+func newRateLimitFromConfig(c *common.Config) (processors.Processor, error) {
+	config := defaultRateLimitConfig()
+	if err := c.Unpack(&config); err != nil {
+		return nil, errors.Wrap(err, "fail to unpack the rate_limit configuration")
+	}
+
+	return newRateLimit(config, time.Now)
+}
+
+func newRateLimit(c rateLimitConfig, clock func() time.Time) (*rateLimit, error) {
+	cfgwarn.Beta("The rate_limit processor is beta.")
+
+	return &rateLimit{
+		config: c,
+		bucket: newTokenBucket(c.Limit, c.Burst, c.GC.Interval, clock()),
+		clock:  clock,
+		log:    logp.NewLogger("rate_limit"),
+	}, nil
+}
+
+// Run drops the event if the bucket of the events key is empty.
+func (p *rateLimit) Run(event *beat.Event) (*beat.Event, error) {
+	key, err := hashFields(event, p.config.Fields)
+	if err != nil {
+		return event, errors.Wrap(err, "failed to compute rate limit key")
+	}
+
+	if p.bucket.allow(key, p.clock()) {
+		rateLimitMetrics.record(false)
+		return event, nil
+	}
+
+	rateLimitMetrics.record(true)
+	p.log.Debugf("event dropped by rate limit %v", p.config.Limit)
+	return nil, nil
+}
+
+func (p *rateLimit) String() string {
+	return fmt.Sprintf("rate_limit=[limit=%v, burst=%v, fields=%v]",
+		p.config.Limit, p.bucket.burst, strings.Join(p.config.Fields, ","))
+}
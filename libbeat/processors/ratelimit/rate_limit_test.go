@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func newTestRateLimit(t *testing.T, settings map[string]interface{}, clock func() time.Time) *rateLimit {
+	c, err := common.NewConfigFrom(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config := defaultRateLimitConfig()
+	if err := c.Unpack(&config); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newRateLimit(config, clock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	p := newTestRateLimit(t, map[string]interface{}{
+		"limit":  "2/m",
+		"fields": []string{"service"},
+	}, clock)
+
+	run := func(service string) bool {
+		event, err := p.Run(&beat.Event{Fields: common.MapStr{"service": service}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return event != nil
+	}
+
+	dropped := rateLimitMetrics.dropped.Get()
+
+	assert.True(t, run("a"))
+	assert.True(t, run("a"))
+	assert.False(t, run("a"))
+	assert.True(t, run("b"))
+	assert.Equal(t, dropped+1, rateLimitMetrics.dropped.Get())
+
+	now = now.Add(30 * time.Second)
+	assert.True(t, run("a"))
+	assert.False(t, run("a"))
+}
+
+func TestRateLimitWithoutFields(t *testing.T) {
+	now := time.Now()
+	clock := func() time.Time { return now }
+
+	p := newTestRateLimit(t, map[string]interface{}{
+		"limit": "1/s",
+	}, clock)
+
+	event, _ := p.Run(&beat.Event{Fields: common.MapStr{"service": "a"}})
+	assert.NotNil(t, event)
+	event, _ = p.Run(&beat.Event{Fields: common.MapStr{"service": "b"}})
+	assert.Nil(t, event)
+}
+
+func TestRateLimitInvalidConfig(t *testing.T) {
+	tests := []map[string]interface{}{
+		{},
+		{"limit": "10"},
+		{"limit": "10/s", "burst": -1},
+	}
+
+	for _, settings := range tests {
+		c, err := common.NewConfigFrom(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = newRateLimitFromConfig(c)
+		assert.Error(t, err, "%v", settings)
+	}
+}
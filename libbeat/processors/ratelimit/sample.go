@@ -0,0 +1,77 @@
+package ratelimit
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/atomic"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+// percentageScale is the resolution used to compare the hash of an event
+// with the configured percentage.
+const percentageScale = 10000
+
+type sample struct {
+	config sampleConfig
+	count  atomic.Uint64
+}
+
+func init() {
+	processors.RegisterPlugin("sample", newSampleFromConfig)
+}
+
+func newSampleFromConfig(c *common.Config) (processors.Processor, error) {
+	var config sampleConfig
+	if err := c.Unpack(&config); err != nil {
+		return nil, errors.Wrap(err, "fail to unpack the sample configuration")
+	}
+
+	return newSample(config)
+}
+
+func newSample(c sampleConfig) (*sample, error) {
+	cfgwarn.Beta("The sample processor is beta.")
+	return &sample{config: c}, nil
+}
+
+// Run keeps the first of every N events, or the events whose hashed fields
+// fall into the configured percentage. Other events are dropped.
+func (p *sample) Run(event *beat.Event) (*beat.Event, error) {
+	keep, err := p.keep(event)
+	if err != nil {
+		return event, err
+	}
+
+	sampleMetrics.record(!keep)
+	if !keep {
+		return nil, nil
+	}
+	return event, nil
+}
+
+func (p *sample) keep(event *beat.Event) (bool, error) {
+	if p.config.Every > 0 {
+		n := p.count.Inc()
+		return (n-1)%uint64(p.config.Every) == 0, nil
+	}
+
+	key, err := hashFields(event, p.config.Fields)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to compute sample key")
+	}
+	return float64(key%percentageScale) < p.config.Percentage*percentageScale/100, nil
+}
+
+func (p *sample) String() string {
+	if p.config.Every > 0 {
+		return fmt.Sprintf("sample=[every=%v]", p.config.Every)
+	}
+	return fmt.Sprintf("sample=[percentage=%v, fields=%v]",
+		p.config.Percentage, strings.Join(p.config.Fields, ","))
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func newTestSample(t *testing.T, settings map[string]interface{}) *sample {
+	c, err := common.NewConfigFrom(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newSampleFromConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*sample)
+}
+
+func TestSampleEvery(t *testing.T) {
+	p := newTestSample(t, map[string]interface{}{"every": 3})
+
+	var kept []int
+	for i := 0; i < 9; i++ {
+		event, err := p.Run(&beat.Event{Fields: common.MapStr{"i": i}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if event != nil {
+			kept = append(kept, i)
+		}
+	}
+	assert.Equal(t, []int{0, 3, 6}, kept)
+}
+
+func TestSamplePercentage(t *testing.T) {
+	p := newTestSample(t, map[string]interface{}{
+		"percentage": 25,
+		"fields":     []string{"trace.id"},
+	})
+
+	kept := map[string]bool{}
+	for i := 0; i < 1000; i++ {
+		id := fmt.Sprintf("trace-%d", i)
+		event, err := p.Run(&beat.Event{Fields: common.MapStr{"trace": common.MapStr{"id": id}}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		kept[id] = event != nil
+	}
+
+	var count int
+	for _, k := range kept {
+		if k {
+			count++
+		}
+	}
+	assert.InDelta(t, 250, count, 60)
+
+	// The decision is deterministic for the same values.
+	for id, k := range kept {
+		event, _ := p.Run(&beat.Event{Fields: common.MapStr{"trace": common.MapStr{"id": id}}})
+		assert.Equal(t, k, event != nil, id)
+	}
+}
+
+func TestSampleInvalidConfig(t *testing.T) {
+	tests := []map[string]interface{}{
+		{},
+		{"every": 2, "percentage": 10, "fields": []string{"a"}},
+		{"percentage": 10},
+		{"percentage": 110, "fields": []string{"a"}},
+	}
+
+	for _, settings := range tests {
+		c, err := common.NewConfigFrom(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = newSampleFromConfig(c)
+		assert.Error(t, err, "%v", settings)
+	}
+}
@@ -0,0 +1,99 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+type bucket struct {
+	tokens        float64
+	lastReplenish time.Time
+}
+
+// tokenBucket keeps a token bucket per key. Each bucket holds up to burst
+// tokens and is replenished continuously at the configured rate. Buckets that
+// have been refilled completely are removed by the garbage collection, as
+// they are equivalent to a new bucket.
+type tokenBucket struct {
+	mu sync.Mutex
+
+	perSecond float64
+	burst     float64
+	buckets   map[uint64]*bucket
+
+	gcInterval time.Duration
+	lastGC     time.Time
+}
+
+func newTokenBucket(r rate, burst int, gcInterval time.Duration, now time.Time) *tokenBucket {
+	b := float64(burst)
+	if b == 0 {
+		b = r.value
+	}
+	if b < 1 {
+		b = 1
+	}
+
+	return &tokenBucket{
+		perSecond:  r.perSecond(),
+		burst:      b,
+		buckets:    map[uint64]*bucket{},
+		gcInterval: gcInterval,
+		lastGC:     now,
+	}
+}
+
+// allow takes a token from the bucket of key. It returns false if the bucket
+// is empty.
+func (t *tokenBucket) allow(key uint64, now time.Time) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if now.Sub(t.lastGC) >= t.gcInterval {
+		t.runGC(now)
+	}
+
+	b, found := t.buckets[key]
+	if !found {
+		b = &bucket{tokens: t.burst, lastReplenish: now}
+		t.buckets[key] = b
+	} else {
+		t.replenish(b, now)
+	}
+
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (t *tokenBucket) replenish(b *bucket, now time.Time) {
+	elapsed := now.Sub(b.lastReplenish).Seconds()
+	if elapsed <= 0 {
+		return
+	}
+
+	b.tokens += elapsed * t.perSecond
+	if b.tokens > t.burst {
+		b.tokens = t.burst
+	}
+	b.lastReplenish = now
+}
+
+func (t *tokenBucket) runGC(now time.Time) {
+	for key, b := range t.buckets {
+		t.replenish(b, now)
+		if b.tokens >= t.burst {
+			delete(t.buckets, key)
+		}
+	}
+	t.lastGC = now
+}
+
+// size returns the number of active buckets.
+func (t *tokenBucket) size() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.buckets)
+}
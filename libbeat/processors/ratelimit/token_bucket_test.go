@@ -0,0 +1,92 @@
+package ratelimit
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func mustRate(t *testing.T, s string) rate {
+	var r rate
+	if err := r.Unpack(s); err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func TestRateUnpack(t *testing.T) {
+	tests := []struct {
+		in        string
+		perSecond float64
+		err       bool
+	}{
+		{in: "10/s", perSecond: 10},
+		{in: "120/m", perSecond: 2},
+		{in: "3600/h", perSecond: 1},
+		{in: "0.5/s", perSecond: 0.5},
+		{in: "10", err: true},
+		{in: "10/d", err: true},
+		{in: "-1/s", err: true},
+		{in: "abc/s", err: true},
+	}
+
+	for _, test := range tests {
+		var r rate
+		err := r.Unpack(test.in)
+		if test.err {
+			assert.Error(t, err, test.in)
+			continue
+		}
+		if assert.NoError(t, err, test.in) {
+			assert.Equal(t, test.perSecond, r.perSecond(), test.in)
+		}
+	}
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(mustRate(t, "2/s"), 0, time.Hour, now)
+
+	// The bucket starts full.
+	assert.True(t, b.allow(1, now))
+	assert.True(t, b.allow(1, now))
+	assert.False(t, b.allow(1, now))
+
+	// Other keys have their own bucket.
+	assert.True(t, b.allow(2, now))
+
+	// Tokens are replenished over time.
+	now = now.Add(500 * time.Millisecond)
+	assert.True(t, b.allow(1, now))
+	assert.False(t, b.allow(1, now))
+
+	// Replenishment is capped by the burst.
+	now = now.Add(time.Minute)
+	assert.True(t, b.allow(1, now))
+	assert.True(t, b.allow(1, now))
+	assert.False(t, b.allow(1, now))
+}
+
+func TestTokenBucketBurst(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(mustRate(t, "1/s"), 5, time.Hour, now)
+
+	for i := 0; i < 5; i++ {
+		assert.True(t, b.allow(1, now))
+	}
+	assert.False(t, b.allow(1, now))
+}
+
+func TestTokenBucketGC(t *testing.T) {
+	now := time.Now()
+	b := newTokenBucket(mustRate(t, "1/s"), 0, time.Minute, now)
+
+	b.allow(1, now)
+	b.allow(2, now.Add(59*time.Second+500*time.Millisecond))
+	assert.Equal(t, 2, b.size())
+
+	// Bucket 1 is full again and gets removed, bucket 2 is still in use.
+	b.allow(3, now.Add(time.Minute))
+	assert.Equal(t, 2, b.size())
+}
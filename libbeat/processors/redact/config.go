@@ -0,0 +1,155 @@
+package redact
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"fmt"
+	"hash"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+)
+
+type config struct {
+	HMAC          *hmacConfig    `config:"hmac"`
+	Mask          *maskConfig    `config:"mask"`
+	Encrypt       *encryptConfig `config:"encrypt"`
+	IgnoreMissing bool           `config:"ignore_missing"`
+}
+
+type hmacConfig struct {
+	Fields []string `config:"fields" validate:"required"`
+	Method method   `config:"method"`
+	KeyID  string   `config:"key_id" validate:"required"`
+	Key    string   `config:"key" validate:"required"`
+}
+
+type maskConfig struct {
+	Fields     []string `config:"fields" validate:"required"`
+	Char       string   `config:"char"`
+	KeepPrefix int      `config:"keep_prefix" validate:"min=0"`
+	KeepSuffix int      `config:"keep_suffix" validate:"min=0"`
+}
+
+type encryptConfig struct {
+	Fields []string `config:"fields" validate:"required"`
+	KeyID  string   `config:"key_id" validate:"required"`
+	Key    string   `config:"key" validate:"required"`
+}
+
+type method struct {
+	name    string
+	newHash func() hash.Hash
+}
+
+var methods = map[string]method{
+	"sha256": {"sha256", sha256.New},
+	"sha384": {"sha384", sha512.New384},
+	"sha512": {"sha512", sha512.New},
+}
+
+var defaultMethod = methods["sha256"]
+
+// Unpack selects the HMAC hash method by name.
+func (m *method) Unpack(s string) error {
+	found, exists := methods[strings.ToLower(s)]
+	if !exists {
+		names := make([]string, 0, len(methods))
+		for name := range methods {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return fmt.Errorf("invalid method '%v', must be one of %v", s, names)
+	}
+	*m = found
+	return nil
+}
+
+// Validate checks at least one action is configured and no field is
+// redacted by multiple actions.
+func (c *config) Validate() error {
+	if c.HMAC == nil && c.Mask == nil && c.Encrypt == nil {
+		return errors.New("at least one of hmac, mask or encrypt must be configured")
+	}
+
+	var fields []string
+	if c.HMAC != nil {
+		fields = append(fields, c.HMAC.Fields...)
+	}
+	if c.Mask != nil {
+		fields = append(fields, c.Mask.Fields...)
+	}
+	if c.Encrypt != nil {
+		fields = append(fields, c.Encrypt.Fields...)
+	}
+
+	seen := map[string]bool{}
+	for _, field := range fields {
+		if seen[field] {
+			return fmt.Errorf("field '%v' is configured for multiple actions", field)
+		}
+		seen[field] = true
+	}
+	return nil
+}
+
+// Validate checks the HMAC key can be decoded.
+func (c *hmacConfig) Validate() error {
+	if err := validateKeyID(c.KeyID); err != nil {
+		return err
+	}
+	key, err := decodeKey(c.Key)
+	if err != nil {
+		return err
+	}
+	if len(key) == 0 {
+		return errors.New("hmac key must not be empty")
+	}
+	return nil
+}
+
+// Validate checks the mask character is a single character.
+func (c *maskConfig) Validate() error {
+	if c.Char != "" && utf8.RuneCountInString(c.Char) != 1 {
+		return fmt.Errorf("mask char '%v' must be a single character", c.Char)
+	}
+	return nil
+}
+
+// Validate checks the encryption key is a valid AES key.
+func (c *encryptConfig) Validate() error {
+	if err := validateKeyID(c.KeyID); err != nil {
+		return err
+	}
+	key, err := decodeKey(c.Key)
+	if err != nil {
+		return err
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return nil
+	default:
+		return fmt.Errorf("encryption key must be 16, 24 or 32 bytes long, got %v bytes", len(key))
+	}
+}
+
+// validateKeyID checks the key ID can be embedded into redacted values. The
+// key ID is separated from the value by a colon.
+func validateKeyID(id string) error {
+	if strings.Contains(id, ":") {
+		return fmt.Errorf("key_id '%v' must not contain ':'", id)
+	}
+	return nil
+}
+
+// decodeKey decodes a base64 encoded key.
+func decodeKey(s string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.Wrap(err, "key must be base64 encoded")
+	}
+	return key, nil
+}
@@ -0,0 +1,246 @@
+package redact
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/joeshaw/multierror"
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+const processorName = "redact_fields"
+
+// redactFields replaces the values of the configured fields with a keyed
+// hash (HMAC), a masked string or the AES-GCM encrypted value. Keyed values
+// are prefixed with the key ID, such that keys can be rotated.
+type redactFields struct {
+	config  config
+	actions []fieldAction
+}
+
+type fieldAction struct {
+	field  string
+	name   string
+	redact func(value interface{}) (interface{}, error)
+}
+
+func init() {
+	processors.RegisterPlugin(processorName, newFromConfig)
+}
+
+func newFromConfig(c *common.Config) (processors.Processor, error) {
+	var config config
+	if err := c.Unpack(&config); err != nil {
+		return nil, errors.Wrap(err, "fail to unpack the redact_fields configuration")
+	}
+
+	return newRedactFields(config)
+}
+
+func newRedactFields(c config) (*redactFields, error) {
+	cfgwarn.Beta("The redact_fields processor is beta.")
+
+	p := &redactFields{config: c}
+
+	if c.HMAC != nil {
+		fn, err := newHMAC(c.HMAC)
+		if err != nil {
+			return nil, err
+		}
+		p.add("hmac", c.HMAC.Fields, fn)
+	}
+
+	if c.Mask != nil {
+		p.add("mask", c.Mask.Fields, newMask(c.Mask))
+	}
+
+	if c.Encrypt != nil {
+		fn, err := newEncrypt(c.Encrypt)
+		if err != nil {
+			return nil, err
+		}
+		p.add("encrypt", c.Encrypt.Fields, fn)
+	}
+
+	return p, nil
+}
+
+func (p *redactFields) add(name string, fields []string, fn func(interface{}) (interface{}, error)) {
+	for _, field := range fields {
+		p.actions = append(p.actions, fieldAction{field: field, name: name, redact: fn})
+	}
+}
+
+// Run redacts the configured fields. Fields that can not be redacted are
+// removed from the event, so the original value is never published.
+func (p *redactFields) Run(event *beat.Event) (*beat.Event, error) {
+	var errs multierror.Errors
+
+	for _, action := range p.actions {
+		value, err := event.GetValue(action.field)
+		if err != nil {
+			if p.config.IgnoreMissing && errors.Cause(err) == common.ErrKeyNotFound {
+				continue
+			}
+			errs = append(errs, errors.Wrapf(err, "failed to find field %v in event", action.field))
+			continue
+		}
+
+		redacted, err := action.redact(value)
+		if err == nil {
+			_, err = event.PutValue(action.field, redacted)
+		}
+		if err != nil {
+			event.Delete(action.field)
+			errs = append(errs, errors.Wrapf(err, "failed to %v field %v, field removed", action.name, action.field))
+		}
+	}
+
+	return event, errs.Err()
+}
+
+func (p *redactFields) String() string {
+	var parts []string
+	if c := p.config.HMAC; c != nil {
+		parts = append(parts, fmt.Sprintf("hmac=[method=%v, key_id=%v, fields=%v]",
+			c.Method.name, c.KeyID, strings.Join(c.Fields, ",")))
+	}
+	if c := p.config.Mask; c != nil {
+		parts = append(parts, fmt.Sprintf("mask=[keep_prefix=%v, keep_suffix=%v, fields=%v]",
+			c.KeepPrefix, c.KeepSuffix, strings.Join(c.Fields, ",")))
+	}
+	if c := p.config.Encrypt; c != nil {
+		parts = append(parts, fmt.Sprintf("encrypt=[key_id=%v, fields=%v]",
+			c.KeyID, strings.Join(c.Fields, ",")))
+	}
+	return fmt.Sprintf("%v=[%v, ignore_missing=%v]",
+		processorName, strings.Join(parts, ", "), p.config.IgnoreMissing)
+}
+
+// newHMAC returns a function replacing a value with '<key_id>:<hex encoded HMAC>'.
+func newHMAC(c *hmacConfig) (func(interface{}) (interface{}, error), error) {
+	if c.Method.newHash == nil {
+		c.Method = defaultMethod
+	}
+
+	key, err := decodeKey(c.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value interface{}) (interface{}, error) {
+		b, err := valueBytes(value)
+		if err != nil {
+			return nil, err
+		}
+
+		mac := hmac.New(c.Method.newHash, key)
+		mac.Write(b)
+		return c.KeyID + ":" + hex.EncodeToString(mac.Sum(nil)), nil
+	}, nil
+}
+
+// newMask returns a function replacing all characters of a value with the
+// mask character, except for the configured number of leading and trailing
+// characters. Values not longer than the kept characters are masked
+// completely, so short values are never published in clear text.
+func newMask(c *maskConfig) func(interface{}) (interface{}, error) {
+	char := '*'
+	if c.Char != "" {
+		char = []rune(c.Char)[0]
+	}
+
+	return func(value interface{}) (interface{}, error) {
+		s, err := valueString(value)
+		if err != nil {
+			return nil, err
+		}
+
+		runes := []rune(s)
+		prefix, suffix := c.KeepPrefix, c.KeepSuffix
+		if prefix+suffix >= len(runes) {
+			prefix, suffix = 0, 0
+		}
+		for i := range runes {
+			if i >= prefix && i < len(runes)-suffix {
+				runes[i] = char
+			}
+		}
+		return string(runes), nil
+	}
+}
+
+// newEncrypt returns a function replacing a value with
+// '<key_id>:<base64 encoded nonce and ciphertext>'. The value is encrypted
+// using AES-GCM with a random nonce.
+func newEncrypt(c *encryptConfig) (func(interface{}) (interface{}, error), error) {
+	key, err := decodeKey(c.Key)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(value interface{}) (interface{}, error) {
+		b, err := valueBytes(value)
+		if err != nil {
+			return nil, err
+		}
+
+		nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(b)+aead.Overhead())
+		if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+			return nil, errors.Wrap(err, "failed to create nonce")
+		}
+
+		sealed := aead.Seal(nonce, nonce, b, nil)
+		return c.KeyID + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+	}, nil
+}
+
+// valueBytes returns the bytes of a string value, or the JSON encoding of
+// any other value.
+func valueBytes(value interface{}) ([]byte, error) {
+	if s, ok := value.(string); ok {
+		return []byte(s), nil
+	}
+	return json.Marshal(value)
+}
+
+// valueString returns a string representation of a scalar value.
+func valueString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case nil:
+		return "", nil
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano), nil
+	case common.Time:
+		return time.Time(v).UTC().Format(time.RFC3339Nano), nil
+	case common.MapStr, map[string]interface{}, []interface{}, []string:
+		return "", errors.New("non-scalar fields are not supported")
+	default:
+		return fmt.Sprint(v), nil
+	}
+}
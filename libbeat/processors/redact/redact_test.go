@@ -0,0 +1,282 @@
+// +build !integration
+
+package redact
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+var (
+	testHMACKey    = base64.StdEncoding.EncodeToString([]byte("hmac-secret-key"))
+	testEncryptKey = base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef"))
+)
+
+func newTestRedact(t *testing.T, settings map[string]interface{}) *redactFields {
+	c, err := common.NewConfigFrom(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newFromConfig(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p.(*redactFields)
+}
+
+func newTestEvent() *beat.Event {
+	return &beat.Event{
+		Fields: common.MapStr{
+			"user": common.MapStr{
+				"email": "jane@example.com",
+				"id":    42,
+			},
+			"card":    "4111111111111111",
+			"message": "hello",
+		},
+	}
+}
+
+func decrypt(t *testing.T, key, value string) (string, string) {
+	parts := strings.SplitN(value, ":", 2)
+	if len(parts) != 2 {
+		t.Fatalf("missing key ID in %v", value)
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	block, err := aes.NewCipher(raw)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n := aead.NonceSize()
+	plain, err := aead.Open(nil, data[:n], data[n:], nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parts[0], string(plain)
+}
+
+func TestHMAC(t *testing.T) {
+	p := newTestRedact(t, map[string]interface{}{
+		"hmac.fields": []string{"user.email", "user.id"},
+		"hmac.key_id": "k1",
+		"hmac.key":    testHMACKey,
+	})
+
+	event, err := p.Run(newTestEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	email, _ := event.GetValue("user.email")
+	assert.Equal(t, "k1:50d1126c8c7551d44bc48123c6ebd0e39659c25036e87800899058c4609ae7f1", email)
+
+	id, _ := event.GetValue("user.id")
+	assert.True(t, strings.HasPrefix(id.(string), "k1:"))
+	assert.Len(t, id, len("k1:")+64)
+
+	// the HMAC is stable for the same value
+	again, err := p.Run(newTestEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	emailAgain, _ := again.GetValue("user.email")
+	assert.Equal(t, email, emailAgain)
+
+	p = newTestRedact(t, map[string]interface{}{
+		"hmac.fields": []string{"user.email"},
+		"hmac.key_id": "k1",
+		"hmac.key":    testHMACKey,
+		"hmac.method": "sha512",
+	})
+	event, err = p.Run(newTestEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	email, _ = event.GetValue("user.email")
+	assert.Len(t, email, len("k1:")+128)
+}
+
+func TestMask(t *testing.T) {
+	tests := []struct {
+		settings map[string]interface{}
+		expected string
+	}{
+		{
+			map[string]interface{}{},
+			"****************",
+		},
+		{
+			map[string]interface{}{"mask.keep_suffix": 4},
+			"************1111",
+		},
+		{
+			map[string]interface{}{"mask.keep_prefix": 2, "mask.keep_suffix": 2, "mask.char": "#"},
+			"41############11",
+		},
+		{
+			map[string]interface{}{"mask.keep_prefix": 10, "mask.keep_suffix": 10},
+			"****************",
+		},
+		{
+			map[string]interface{}{"mask.keep_prefix": 8, "mask.keep_suffix": 8},
+			"****************",
+		},
+		{
+			map[string]interface{}{"mask.keep_prefix": 8, "mask.keep_suffix": 7},
+			"41111111*1111111",
+		},
+	}
+
+	for _, test := range tests {
+		settings := test.settings
+		settings["mask.fields"] = []string{"card"}
+		p := newTestRedact(t, settings)
+
+		event, err := p.Run(newTestEvent())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		card, _ := event.GetValue("card")
+		assert.Equal(t, test.expected, card, "%v", p)
+	}
+}
+
+func TestEncrypt(t *testing.T) {
+	p := newTestRedact(t, map[string]interface{}{
+		"encrypt.fields": []string{"user.email", "user.id"},
+		"encrypt.key_id": "2018-10",
+		"encrypt.key":    testEncryptKey,
+	})
+
+	event, err := p.Run(newTestEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	email, _ := event.GetValue("user.email")
+	keyID, plain := decrypt(t, testEncryptKey, email.(string))
+	assert.Equal(t, "2018-10", keyID)
+	assert.Equal(t, "jane@example.com", plain)
+
+	id, _ := event.GetValue("user.id")
+	_, plain = decrypt(t, testEncryptKey, id.(string))
+	assert.Equal(t, "42", plain)
+
+	// random nonces result in different ciphertexts for the same value
+	again, err := p.Run(newTestEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	emailAgain, _ := again.GetValue("user.email")
+	assert.NotEqual(t, email, emailAgain)
+}
+
+func TestMissingFields(t *testing.T) {
+	settings := map[string]interface{}{
+		"mask.fields": []string{"missing", "card"},
+	}
+
+	event, err := newTestRedact(t, settings).Run(newTestEvent())
+	assert.Error(t, err)
+	card, _ := event.GetValue("card")
+	assert.Equal(t, "****************", card)
+
+	settings["ignore_missing"] = true
+	_, err = newTestRedact(t, settings).Run(newTestEvent())
+	assert.NoError(t, err)
+}
+
+func TestFailedFieldsAreRemoved(t *testing.T) {
+	p := newTestRedact(t, map[string]interface{}{
+		"mask.fields": []string{"user", "message"},
+	})
+
+	event, err := p.Run(newTestEvent())
+	assert.Error(t, err)
+
+	has, _ := event.Fields.HasKey("user")
+	assert.False(t, has)
+	message, _ := event.GetValue("message")
+	assert.Equal(t, "*****", message)
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"no action": {
+			"ignore_missing": true,
+		},
+		"duplicate field": {
+			"mask.fields":    []string{"card"},
+			"encrypt.fields": []string{"card"},
+			"encrypt.key_id": "k1",
+			"encrypt.key":    testEncryptKey,
+		},
+		"missing key": {
+			"hmac.fields": []string{"user.email"},
+			"hmac.key_id": "k1",
+		},
+		"missing key_id": {
+			"hmac.fields": []string{"user.email"},
+			"hmac.key":    testHMACKey,
+		},
+		"colon in key_id": {
+			"hmac.fields": []string{"user.email"},
+			"hmac.key_id": "k:1",
+			"hmac.key":    testHMACKey,
+		},
+		"key not base64": {
+			"hmac.fields": []string{"user.email"},
+			"hmac.key_id": "k1",
+			"hmac.key":    "not base64!",
+		},
+		"invalid aes key length": {
+			"encrypt.fields": []string{"user.email"},
+			"encrypt.key_id": "k1",
+			"encrypt.key":    testHMACKey,
+		},
+		"invalid method": {
+			"hmac.fields": []string{"user.email"},
+			"hmac.key_id": "k1",
+			"hmac.key":    testHMACKey,
+			"hmac.method": "md5",
+		},
+		"invalid mask char": {
+			"mask.fields": []string{"card"},
+			"mask.char":   "**",
+		},
+	}
+
+	for name, settings := range tests {
+		c, err := common.NewConfigFrom(settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		_, err = newFromConfig(c)
+		assert.Error(t, err, name)
+	}
+}
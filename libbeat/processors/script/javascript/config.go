@@ -0,0 +1,35 @@
+package javascript
+
+import (
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Config defines the Javascript source files to use for the processor.
+type Config struct {
+	Tag               string                 `config:"tag"`                      // Processor ID for debug and metrics.
+	Source            string                 `config:"source"`                   // Inline script to execute.
+	File              string                 `config:"file"`                     // Source file.
+	Params            map[string]interface{} `config:"params"`                   // Parameters to pass to script.
+	Timeout           time.Duration          `config:"timeout" validate:"min=0"` // Execution timeout.
+	MaxCachedSessions int                    `config:"max_cached_sessions" validate:"min=0"`
+}
+
+// Validate returns an error if one (and only one) option is not set.
+func (c Config) Validate() error {
+	switch {
+	case c.Source == "" && c.File == "":
+		return errors.New("javascript must be defined via 'file' or inline using 'source'")
+	case c.Source != "" && c.File != "":
+		return errors.New("javascript can be defined in only one of 'file' or 'source'")
+	}
+	return nil
+}
+
+func defaultConfig() Config {
+	return Config{
+		Timeout:           time.Second,
+		MaxCachedSessions: 4,
+	}
+}
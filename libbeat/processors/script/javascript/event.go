@@ -0,0 +1,178 @@
+package javascript
+
+import (
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// beatEvent is the javascript representation of a beat.Event. A single
+// instance is bound to each session and reset for every processed event.
+type beatEvent struct {
+	vm        *goja.Runtime
+	obj       *goja.Object
+	inner     *beat.Event
+	cancelled bool
+}
+
+func newBeatEvent(vm *goja.Runtime) *beatEvent {
+	e := &beatEvent{
+		vm:  vm,
+		obj: vm.NewObject(),
+	}
+	e.init()
+	return e
+}
+
+func (e *beatEvent) init() {
+	e.obj.Set("Get", e.get)
+	e.obj.Set("Put", e.put)
+	e.obj.Set("Delete", e.delete)
+	e.obj.Set("Rename", e.rename)
+	e.obj.Set("Cancel", e.cancel)
+	e.obj.Set("Tag", e.tag)
+	e.obj.Set("AppendTo", e.appendTo)
+}
+
+// reset binds the given event to the javascript object.
+func (e *beatEvent) reset(b *beat.Event) {
+	e.inner = b
+	e.cancelled = false
+}
+
+// JSObject returns the javascript object passed to the process function.
+func (e *beatEvent) JSObject() goja.Value {
+	return e.obj
+}
+
+// get returns the value of the given key or null if the key does not exist.
+// If no key is given all fields of the event are returned.
+func (e *beatEvent) get(call goja.FunctionCall) goja.Value {
+	a0 := call.Argument(0)
+	if goja.IsUndefined(a0) {
+		return e.vm.ToValue(map[string]interface{}(e.inner.Fields))
+	}
+
+	v, err := e.inner.GetValue(a0.String())
+	if err != nil {
+		return goja.Null()
+	}
+	return e.vm.ToValue(v)
+}
+
+// put writes a value to the event and returns the previous value.
+func (e *beatEvent) put(call goja.FunctionCall) goja.Value {
+	if len(call.Arguments) != 2 {
+		panic(e.vm.NewGoError(errors.New("Put requires two arguments (key and value)")))
+	}
+
+	key := call.Argument(0).String()
+	value := call.Argument(1).Export()
+
+	old, err := e.inner.PutValue(key, value)
+	if err != nil {
+		panic(e.vm.NewGoError(err))
+	}
+	return e.vm.ToValue(old)
+}
+
+// delete removes a key from the event and returns true on success.
+func (e *beatEvent) delete(call goja.FunctionCall) goja.Value {
+	if len(call.Arguments) != 1 {
+		panic(e.vm.NewGoError(errors.New("Delete requires one argument (key)")))
+	}
+
+	if err := e.inner.Delete(call.Argument(0).String()); err != nil {
+		return e.vm.ToValue(false)
+	}
+	return e.vm.ToValue(true)
+}
+
+// rename moves the value of a key to a new key. The target key must not
+// exist. It returns true on success.
+func (e *beatEvent) rename(call goja.FunctionCall) goja.Value {
+	if len(call.Arguments) != 2 {
+		panic(e.vm.NewGoError(errors.New("Rename requires two arguments (from and to)")))
+	}
+
+	from := call.Argument(0).String()
+	to := call.Argument(1).String()
+
+	if exists, _ := e.inner.Fields.HasKey(to); exists {
+		return e.vm.ToValue(false)
+	}
+
+	value, err := e.inner.GetValue(from)
+	if err != nil {
+		return e.vm.ToValue(false)
+	}
+
+	// Deletion must happen first to support cases where a becomes a.b.
+	if err = e.inner.Delete(from); err != nil {
+		return e.vm.ToValue(false)
+	}
+
+	if _, err = e.inner.PutValue(to, value); err != nil {
+		// Restore original event.
+		e.inner.PutValue(from, value)
+		return e.vm.ToValue(false)
+	}
+	return e.vm.ToValue(true)
+}
+
+// cancel marks the event as dropped.
+func (e *beatEvent) cancel(call goja.FunctionCall) goja.Value {
+	e.cancelled = true
+	return goja.Undefined()
+}
+
+// tag adds a tag to the tags field if it is not already present.
+func (e *beatEvent) tag(call goja.FunctionCall) goja.Value {
+	if len(call.Arguments) != 1 {
+		panic(e.vm.NewGoError(errors.New("Tag requires one argument (tag)")))
+	}
+
+	e.appendString(common.TagsKey, call.Argument(0).String())
+	return goja.Undefined()
+}
+
+// appendTo appends a string to a field, converting the field into an array
+// if needed. Values already present are not added again.
+func (e *beatEvent) appendTo(call goja.FunctionCall) goja.Value {
+	if len(call.Arguments) != 2 {
+		panic(e.vm.NewGoError(errors.New("AppendTo requires two arguments (field and value)")))
+	}
+
+	e.appendString(call.Argument(0).String(), call.Argument(1).String())
+	return goja.Undefined()
+}
+
+func (e *beatEvent) appendString(field, value string) {
+	list, _ := e.inner.GetValue(field)
+	switch v := list.(type) {
+	case nil:
+		e.inner.PutValue(field, []string{value})
+	case string:
+		if v != value {
+			e.inner.PutValue(field, []string{v, value})
+		}
+	case []string:
+		for _, s := range v {
+			if s == value {
+				return
+			}
+		}
+		e.inner.PutValue(field, append(v, value))
+	case []interface{}:
+		for _, s := range v {
+			if s == value {
+				return
+			}
+		}
+		e.inner.PutValue(field, append(v, value))
+	default:
+		panic(e.vm.NewGoError(errors.Errorf("unexpected type %T found for %v field", list, field)))
+	}
+}
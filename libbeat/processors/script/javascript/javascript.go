@@ -0,0 +1,96 @@
+package javascript
+
+import (
+	"fmt"
+	"io/ioutil"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/paths"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+type jsProcessor struct {
+	Config
+	sessionPool *sessionPool
+	sourceFile  string
+	stats       *processorStats
+}
+
+// New constructs a new Javascript processor.
+func New(c *common.Config) (processors.Processor, error) {
+	config := defaultConfig()
+	if err := c.Unpack(&config); err != nil {
+		return nil, errors.Wrap(err, "fail to unpack the javascript configuration")
+	}
+
+	return NewFromConfig(config)
+}
+
+// NewFromConfig constructs a new Javascript processor from the given config
+// object. It loads and compiles the sources, and creates a first session to
+// validate the script.
+func NewFromConfig(c Config) (processors.Processor, error) {
+	cfgwarn.Beta("The javascript processor is beta.")
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var sourceFile, sourceCode string
+	switch {
+	case c.Source != "":
+		sourceFile = "inline.js"
+		sourceCode = c.Source
+	case c.File != "":
+		sourceFile = paths.Resolve(paths.Config, c.File)
+		data, err := ioutil.ReadFile(sourceFile)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read script file %v", sourceFile)
+		}
+		sourceCode = string(data)
+	}
+
+	prog, err := goja.Compile(sourceFile, sourceCode, true)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to compile script")
+	}
+
+	pool, err := newSessionPool(prog, c)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jsProcessor{
+		Config:      c,
+		sessionPool: pool,
+		sourceFile:  sourceFile,
+		stats:       newProcessorStats(c.Tag),
+	}, nil
+}
+
+// Run executes the processor on the given event. It returns nil if the
+// script cancelled the event.
+func (p *jsProcessor) Run(event *beat.Event) (*beat.Event, error) {
+	s, err := p.sessionPool.Get()
+	if err != nil {
+		return event, errors.Wrap(err, "failed to create javascript session")
+	}
+	defer p.sessionPool.Put(s)
+
+	start := time.Now()
+	rtn, err := s.runProcessFunc(event)
+	_, timeout := errors.Cause(err).(*goja.InterruptedError)
+	p.stats.record(time.Since(start), err == nil && rtn == nil, err != nil, timeout)
+
+	return rtn, err
+}
+
+func (p *jsProcessor) String() string {
+	return fmt.Sprintf("script=[type=javascript, id=%v, sources=%v]", p.Tag, p.sourceFile)
+}
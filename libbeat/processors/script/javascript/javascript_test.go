@@ -0,0 +1,274 @@
+package javascript
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/monitoring"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+func newTestProcessor(t *testing.T, c map[string]interface{}) processors.Processor {
+	cfg, err := common.NewConfigFrom(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := New(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+var tagCounter int64
+
+// uniqueTag returns a processor tag not used by any other test, such that
+// the metrics registries are not shared when tests run multiple times.
+func uniqueTag(name string) string {
+	return fmt.Sprintf("%s-%d", name, atomic.AddInt64(&tagCounter, 1))
+}
+
+func testEvent() *beat.Event {
+	return &beat.Event{
+		Timestamp: time.Now(),
+		Fields: common.MapStr{
+			"source": common.MapStr{
+				"ip": "192.0.2.1",
+			},
+			"message": "hello world",
+		},
+	}
+}
+
+func TestEventAPI(t *testing.T) {
+	p := newTestProcessor(t, map[string]interface{}{
+		"source": `
+function process(event) {
+    event.Put("lowercase", event.Get("message").toLowerCase());
+    event.Put("nested", {"count": 2});
+    event.Rename("source.ip", "client.ip");
+    event.Delete("message");
+    event.Tag("js");
+    event.Tag("js");
+    event.AppendTo("list", "a");
+    event.AppendTo("list", "b");
+}`,
+	})
+
+	evt, err := p.Run(testEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "hello world", evt.Fields["lowercase"])
+	assert.Equal(t, map[string]interface{}{"count": int64(2)}, evt.Fields["nested"])
+	assert.Equal(t, []string{"js"}, evt.Fields["tags"])
+	assert.Equal(t, []string{"a", "b"}, evt.Fields["list"])
+
+	ip, _ := evt.GetValue("client.ip")
+	assert.Equal(t, "192.0.2.1", ip)
+
+	_, err = evt.GetValue("source.ip")
+	assert.Error(t, err)
+	_, err = evt.GetValue("message")
+	assert.Error(t, err)
+}
+
+func TestGetMissingKey(t *testing.T) {
+	p := newTestProcessor(t, map[string]interface{}{
+		"source": `
+function process(event) {
+    event.Put("missing", event.Get("does.not.exist") === null);
+}`,
+	})
+
+	evt, err := p.Run(testEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, evt.Fields["missing"])
+}
+
+func TestCancel(t *testing.T) {
+	p := newTestProcessor(t, map[string]interface{}{
+		"source": `function process(event) { event.Cancel(); }`,
+	})
+
+	evt, err := p.Run(testEvent())
+	assert.NoError(t, err)
+	assert.Nil(t, evt)
+}
+
+func TestParams(t *testing.T) {
+	p := newTestProcessor(t, map[string]interface{}{
+		"params": map[string]interface{}{"field": "added"},
+		"source": `
+var params;
+function register(p) {
+    params = p;
+}
+
+function process(event) {
+    event.Put(params.field, true);
+}`,
+	})
+
+	evt, err := p.Run(testEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, true, evt.Fields["added"])
+}
+
+func TestParamsWithoutRegister(t *testing.T) {
+	cfg, err := common.NewConfigFrom(map[string]interface{}{
+		"params": map[string]interface{}{"field": "added"},
+		"source": `function process(event) {}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = New(cfg)
+	assert.Error(t, err)
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := map[string]map[string]interface{}{
+		"no source": {},
+		"source and file": {
+			"source": `function process(event) {}`,
+			"file":   "processor.js",
+		},
+		"syntax error": {
+			"source": `function process(event) {`,
+		},
+		"no process function": {
+			"source": `function foo(event) {}`,
+		},
+	}
+
+	for name, c := range tests {
+		t.Run(name, func(t *testing.T) {
+			cfg, err := common.NewConfigFrom(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = New(cfg)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "javascript")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	file := filepath.Join(dir, "processor.js")
+	err = ioutil.WriteFile(file, []byte(`function process(event) { event.Tag("from_file"); }`), 0600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p := newTestProcessor(t, map[string]interface{}{"file": file})
+
+	evt, err := p.Run(testEvent())
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, []string{"from_file"}, evt.Fields["tags"])
+}
+
+func TestException(t *testing.T) {
+	tag := uniqueTag("exception")
+	p := newTestProcessor(t, map[string]interface{}{
+		"tag":    tag,
+		"source": `function process(event) { throw "oops"; }`,
+	})
+
+	evt, err := p.Run(testEvent())
+	assert.Error(t, err)
+	assert.NotNil(t, evt)
+	assert.EqualValues(t, 1, jsMetrics.Get(tag+".errors").(*monitoring.Int).Get())
+}
+
+func TestTimeout(t *testing.T) {
+	tag := uniqueTag("timeout")
+	p := newTestProcessor(t, map[string]interface{}{
+		"tag":     tag,
+		"timeout": "50ms",
+		"source":  `function process(event) { while (true) {} }`,
+	})
+
+	_, err := p.Run(testEvent())
+	assert.Error(t, err)
+	assert.EqualValues(t, 1, jsMetrics.Get(tag+".timeouts").(*monitoring.Int).Get())
+
+	// The session must be usable after a timeout.
+	_, err = p.Run(testEvent())
+	assert.Error(t, err)
+	assert.EqualValues(t, 2, jsMetrics.Get(tag+".timeouts").(*monitoring.Int).Get())
+}
+
+func TestMetrics(t *testing.T) {
+	tag := uniqueTag("metrics")
+	p := newTestProcessor(t, map[string]interface{}{
+		"tag":    tag,
+		"source": `function process(event) { if (event.Get("drop")) { event.Cancel(); } }`,
+	})
+
+	for i := 0; i < 3; i++ {
+		if _, err := p.Run(testEvent()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	evt := testEvent()
+	evt.Fields["drop"] = true
+	if _, err := p.Run(evt); err != nil {
+		t.Fatal(err)
+	}
+
+	get := func(name string) int64 {
+		return jsMetrics.Get(tag + "." + name).(*monitoring.Int).Get()
+	}
+	assert.EqualValues(t, 4, get("events.processed"))
+	assert.EqualValues(t, 1, get("events.dropped"))
+	assert.EqualValues(t, 0, get("errors"))
+}
+
+func TestConcurrentSessions(t *testing.T) {
+	p := newTestProcessor(t, map[string]interface{}{
+		"max_cached_sessions": 2,
+		"source":              `function process(event) { event.Put("done", true); }`,
+	})
+
+	done := make(chan error)
+	for i := 0; i < 10; i++ {
+		go func() {
+			evt, err := p.Run(testEvent())
+			if err == nil && evt.Fields["done"] != true {
+				err = assert.AnError
+			}
+			done <- err
+		}()
+	}
+
+	for i := 0; i < 10; i++ {
+		assert.NoError(t, <-done)
+	}
+}
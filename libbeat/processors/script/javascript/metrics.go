@@ -0,0 +1,82 @@
+package javascript
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/libbeat/monitoring"
+)
+
+var (
+	// metricsMutex protects the creation of the per processor registries,
+	// processors with the same tag share their metrics.
+	metricsMutex sync.Mutex
+
+	jsMetrics = monitoring.Default.NewRegistry("processor.javascript")
+	total     = newMetricSet(jsMetrics)
+)
+
+// metricSet contains the counters reported for a processor.
+type metricSet struct {
+	processed   *monitoring.Int // events passed to the process function
+	dropped     *monitoring.Int // events cancelled by the script
+	errors      *monitoring.Int // process function failures, including timeouts
+	timeouts    *monitoring.Int // process function executions interrupted by the timeout
+	processTime *monitoring.Int // accumulated processing time in nanoseconds
+}
+
+func newMetricSet(reg *monitoring.Registry) *metricSet {
+	return &metricSet{
+		processed:   getOrCreateInt(reg, "events.processed"),
+		dropped:     getOrCreateInt(reg, "events.dropped"),
+		errors:      getOrCreateInt(reg, "errors"),
+		timeouts:    getOrCreateInt(reg, "timeouts"),
+		processTime: getOrCreateInt(reg, "process_time.ns"),
+	}
+}
+
+func getOrCreateInt(reg *monitoring.Registry, name string) *monitoring.Int {
+	if v, ok := reg.Get(name).(*monitoring.Int); ok {
+		return v
+	}
+	return monitoring.NewInt(reg, name)
+}
+
+// processorStats reports the metrics of a processor to the totals and, if the
+// processor has a tag, to the registry of the tag.
+type processorStats struct {
+	sets []*metricSet
+}
+
+func newProcessorStats(tag string) *processorStats {
+	stats := &processorStats{sets: []*metricSet{total}}
+	if tag == "" {
+		return stats
+	}
+
+	metricsMutex.Lock()
+	defer metricsMutex.Unlock()
+
+	reg := jsMetrics.GetRegistry(tag)
+	if reg == nil {
+		reg = jsMetrics.NewRegistry(tag)
+	}
+	stats.sets = append(stats.sets, newMetricSet(reg))
+	return stats
+}
+
+func (s *processorStats) record(took time.Duration, dropped, failed, timeout bool) {
+	for _, m := range s.sets {
+		m.processed.Inc()
+		m.processTime.Add(int64(took))
+		if dropped {
+			m.dropped.Inc()
+		}
+		if failed {
+			m.errors.Inc()
+		}
+		if timeout {
+			m.timeouts.Inc()
+		}
+	}
+}
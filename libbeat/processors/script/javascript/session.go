@@ -0,0 +1,148 @@
+package javascript
+
+import (
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/beat"
+)
+
+const (
+	entryPointFunction = "process"
+	registerFunction   = "register"
+	timeoutError       = "javascript processor execution timeout"
+)
+
+// session is a javascript runtime with the compiled program loaded. A
+// session must not be used concurrently.
+type session struct {
+	vm          *goja.Runtime
+	processFunc goja.Callable
+	evt         *beatEvent
+	timeout     time.Duration
+}
+
+func newSession(p *goja.Program, conf Config) (*session, error) {
+	vm := goja.New()
+
+	// Execute the script to define the functions.
+	if _, err := vm.RunProgram(p); err != nil {
+		return nil, err
+	}
+
+	s := &session{
+		vm:      vm,
+		evt:     newBeatEvent(vm),
+		timeout: conf.Timeout,
+	}
+
+	processFunc, ok := goja.AssertFunction(vm.Get(entryPointFunction))
+	if !ok {
+		return nil, errors.Errorf("%v function not found", entryPointFunction)
+	}
+	s.processFunc = processFunc
+
+	if err := s.registerScript(conf.Params); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// registerScript calls the register function of the script, if defined, with
+// the configured params.
+func (s *session) registerScript(params map[string]interface{}) error {
+	registerFunc, ok := goja.AssertFunction(s.vm.Get(registerFunction))
+	if !ok {
+		if len(params) > 0 {
+			return errors.Errorf("params were provided but no %v function was found", registerFunction)
+		}
+		return nil
+	}
+
+	if _, err := registerFunc(goja.Undefined(), s.vm.ToValue(params)); err != nil {
+		return errors.Wrap(err, "failed to register script")
+	}
+	return nil
+}
+
+// runProcessFunc executes the process function of the script with the given
+// event. It returns nil if the script cancelled the event.
+func (s *session) runProcessFunc(b *beat.Event) (out *beat.Event, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.Errorf("unexpected panic in javascript processor: %v", r)
+		}
+	}()
+
+	s.evt.reset(b)
+	defer s.evt.reset(nil)
+
+	if s.timeout > 0 {
+		timer := time.AfterFunc(s.timeout, func() {
+			s.vm.Interrupt(timeoutError)
+		})
+		defer func() {
+			timer.Stop()
+			s.vm.ClearInterrupt()
+		}()
+	}
+
+	if _, err = s.processFunc(goja.Undefined(), s.evt.JSObject()); err != nil {
+		return b, errors.Wrap(err, "failed in process function")
+	}
+
+	if s.evt.cancelled {
+		return nil, nil
+	}
+	return b, nil
+}
+
+// sessionPool caches sessions so the program does not need to be loaded for
+// every event.
+type sessionPool struct {
+	program *goja.Program
+	config  Config
+	C       chan *session
+}
+
+func newSessionPool(p *goja.Program, c Config) (*sessionPool, error) {
+	// Create a session up front to validate the script.
+	s, err := newSession(p, c)
+	if err != nil {
+		return nil, err
+	}
+
+	pool := &sessionPool{
+		program: p,
+		config:  c,
+		C:       make(chan *session, c.MaxCachedSessions),
+	}
+	pool.Put(s)
+	return pool, nil
+}
+
+// Get returns a cached session or creates a new one.
+func (p *sessionPool) Get() (*session, error) {
+	select {
+	case s := <-p.C:
+		return s, nil
+	default:
+		return newSession(p.program, p.config)
+	}
+}
+
+// Put returns a session to the pool. The session is discarded if the pool is
+// full.
+func (p *sessionPool) Put(s *session) {
+	if s == nil {
+		return
+	}
+
+	select {
+	case p.C <- s:
+	default:
+	}
+}
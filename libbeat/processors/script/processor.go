@@ -0,0 +1,34 @@
+package script
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/processors"
+	"github.com/elastic/beats/libbeat/processors/script/javascript"
+)
+
+func init() {
+	processors.RegisterPlugin("script", New)
+}
+
+// New constructs a new script processor. The `lang` setting selects the
+// scripting language used to run the user supplied code.
+func New(c *common.Config) (processors.Processor, error) {
+	var config = struct {
+		Lang string `config:"lang" validate:"required"`
+	}{}
+	if err := c.Unpack(&config); err != nil {
+		return nil, errors.Wrap(err, "fail to unpack the script configuration")
+	}
+
+	switch strings.ToLower(config.Lang) {
+	case "javascript", "js":
+		return javascript.New(c)
+	default:
+		return nil, errors.Errorf("script lang must be declared (e.g. lang: javascript), "+
+			"'%s' is not supported", config.Lang)
+	}
+}
@@ -0,0 +1,37 @@
+package script
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+func TestNew(t *testing.T) {
+	cfg, err := common.NewConfigFrom(map[string]interface{}{
+		"lang":   "javascript",
+		"source": `function process(event) {}`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := New(cfg)
+	if assert.NoError(t, err) {
+		assert.Contains(t, p.String(), "type=javascript")
+	}
+}
+
+func TestUnsupportedLang(t *testing.T) {
+	cfg, err := common.NewConfigFrom(map[string]interface{}{
+		"lang":   "lua",
+		"source": `function process(event) end`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = New(cfg)
+	assert.Error(t, err)
+}
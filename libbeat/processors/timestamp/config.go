@@ -0,0 +1,46 @@
+package timestamp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+type config struct {
+	Field         string   `config:"field" validate:"required"`
+	TargetField   string   `config:"target_field"`
+	Layouts       []string `config:"layouts" validate:"required"`
+	Timezone      string   `config:"timezone"`
+	IgnoreMissing bool     `config:"ignore_missing"`
+}
+
+func defaultConfig() config {
+	return config{
+		TargetField: "@timestamp",
+		Timezone:    "UTC",
+	}
+}
+
+// loadLocation returns the location for a timezone name from the IANA Time
+// Zone database (e.g. America/New_York), "Local", or a fixed offset like
+// "+05:00" or "-0700".
+func loadLocation(timezone string) (*time.Location, error) {
+	if strings.HasPrefix(timezone, "+") || strings.HasPrefix(timezone, "-") {
+		for _, layout := range []string{"-07:00", "-0700", "-07"} {
+			t, err := time.Parse(layout, timezone)
+			if err == nil {
+				_, offset := t.Zone()
+				return time.FixedZone(timezone, offset), nil
+			}
+		}
+		return nil, fmt.Errorf("invalid timezone offset '%v'", timezone)
+	}
+
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load timezone '%v'", timezone)
+	}
+	return loc, nil
+}
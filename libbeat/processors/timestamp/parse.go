@@ -0,0 +1,159 @@
+package timestamp
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/elastic/beats/libbeat/common"
+)
+
+// Special layout names.
+const (
+	unixLayout    = "UNIX"
+	unixMSLayout  = "UNIX_MS"
+	iso8601Layout = "ISO8601"
+)
+
+// iso8601Layouts are the Go layouts tried for the ISO8601 layout name. A
+// missing timezone is interpreted in the configured timezone.
+var iso8601Layouts = []string{
+	"2006-01-02T15:04:05.999999999Z07:00",
+	"2006-01-02T15:04:05.999999999Z0700",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999Z07:00",
+	"2006-01-02 15:04:05.999999999Z0700",
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02T15:04Z07:00",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// parseValue parses a field value using the given layout.
+func parseValue(value interface{}, layout string, loc *time.Location) (time.Time, error) {
+	switch v := value.(type) {
+	case time.Time:
+		return v, nil
+	case common.Time:
+		return time.Time(v), nil
+	}
+
+	switch layout {
+	case unixLayout:
+		return parseUnix(value, time.Second)
+	case unixMSLayout:
+		return parseUnix(value, time.Millisecond)
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return time.Time{}, fmt.Errorf("unexpected type %T for layout '%v'", value, layout)
+	}
+
+	if layout == iso8601Layout {
+		for _, l := range iso8601Layouts {
+			if t, err := time.ParseInLocation(l, s, loc); err == nil {
+				return t, nil
+			}
+		}
+		return time.Time{}, fmt.Errorf("'%v' is not a valid ISO8601 timestamp", s)
+	}
+
+	t, err := time.ParseInLocation(layout, s, loc)
+	if err != nil {
+		return t, err
+	}
+
+	// Layouts without a year (e.g. syslog timestamps) default to year 0.
+	if t.Year() == 0 {
+		t = addCurrentYear(t, time.Now().In(loc))
+	}
+	return t, nil
+}
+
+// addCurrentYear sets the year of a timestamp parsed without a year. Timestamps
+// that would be in the future, like a December timestamp parsed in January, are
+// set to the previous year. A day of tolerance accounts for clock skew.
+func addCurrentYear(t, now time.Time) time.Time {
+	t = t.AddDate(now.Year(), 0, 0)
+	if t.Sub(now) > 24*time.Hour {
+		t = t.AddDate(-1, 0, 0)
+	}
+	return t
+}
+
+// parseUnix converts a numeric value or a string containing a number to a
+// time using the given unit. Fractional values are supported.
+func parseUnix(value interface{}, unit time.Duration) (time.Time, error) {
+	switch v := value.(type) {
+	case string:
+		return parseUnixString(v, unit)
+	case int:
+		return unixTime(int64(v), 0, unit), nil
+	case int32:
+		return unixTime(int64(v), 0, unit), nil
+	case int64:
+		return unixTime(v, 0, unit), nil
+	case uint:
+		return unixTime(int64(v), 0, unit), nil
+	case uint32:
+		return unixTime(int64(v), 0, unit), nil
+	case uint64:
+		return unixTime(int64(v), 0, unit), nil
+	case float32:
+		return parseUnixFloat(float64(v), unit), nil
+	case float64:
+		return parseUnixFloat(v, unit), nil
+	case common.Float:
+		return parseUnixFloat(float64(v), unit), nil
+	default:
+		return time.Time{}, fmt.Errorf("unexpected type %T for a UNIX timestamp", value)
+	}
+}
+
+// parseUnixString parses the integer and fractional part of a number
+// separately to avoid floating point rounding errors.
+func parseUnixString(s string, unit time.Duration) (time.Time, error) {
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	n, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("'%v' is not a valid UNIX timestamp", s)
+	}
+
+	var frac int64
+	if len(fracPart) > 0 {
+		if len(fracPart) > 9 {
+			fracPart = fracPart[:9]
+		}
+		frac, err = strconv.ParseInt(fracPart+strings.Repeat("0", 9-len(fracPart)), 10, 64)
+		if err != nil || frac < 0 {
+			return time.Time{}, fmt.Errorf("'%v' is not a valid UNIX timestamp", s)
+		}
+		if strings.HasPrefix(intPart, "-") {
+			frac = -frac
+		}
+	}
+
+	return unixTime(n, frac, unit), nil
+}
+
+// parseUnixFloat converts a float to a time. The fraction is rounded to
+// microseconds, as more digits are not representable for current timestamps.
+func parseUnixFloat(f float64, unit time.Duration) time.Time {
+	n, frac := math.Modf(f)
+	micros := math.Floor(frac*1e6 + 0.5)
+	return unixTime(int64(n), int64(micros)*1000, unit)
+}
+
+// unixTime returns the time for n units since the UNIX epoch plus a fraction
+// of a unit given in billionths.
+func unixTime(n, frac int64, unit time.Duration) time.Time {
+	nanos := n*int64(unit) + frac*int64(unit)/int64(time.Second)
+	return time.Unix(0, nanos).UTC()
+}
@@ -0,0 +1,91 @@
+package timestamp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/processors"
+)
+
+const flagParsingError = "timestamp_parsing_error"
+
+type processor struct {
+	config
+	loc *time.Location
+}
+
+func init() {
+	processors.RegisterPlugin("timestamp", newFromConfig)
+}
+
+func newFromConfig(c *common.Config) (processors.Processor, error) {
+	config := defaultConfig()
+	if err := c.Unpack(&config); err != nil {
+		return nil, errors.Wrap(err, "fail to unpack the timestamp configuration")
+	}
+
+	return newProcessor(config)
+}
+
+func newProcessor(c config) (*processor, error) {
+	cfgwarn.Beta("The timestamp processor is beta.")
+
+	loc, err := loadLocation(c.Timezone)
+	if err != nil {
+		return nil, err
+	}
+
+	return &processor{config: c, loc: loc}, nil
+}
+
+// Run parses the configured field with the first matching layout and writes
+// the result to the target field. Events that cannot be parsed are tagged.
+func (p *processor) Run(event *beat.Event) (*beat.Event, error) {
+	value, err := event.GetValue(p.Field)
+	if err != nil {
+		if p.IgnoreMissing && errors.Cause(err) == common.ErrKeyNotFound {
+			return event, nil
+		}
+		return event, errors.Wrapf(err, "failed to get time field %v", p.Field)
+	}
+
+	ts, err := p.parse(value)
+	if err != nil {
+		if err := common.AddTags(event.Fields, []string{flagParsingError}); err != nil {
+			return event, errors.Wrap(err, "cannot add new flag to the event")
+		}
+		return event, err
+	}
+
+	if p.TargetField == "@timestamp" {
+		event.Timestamp = ts
+		return event, nil
+	}
+
+	if _, err := event.PutValue(p.TargetField, common.Time(ts)); err != nil {
+		return event, errors.Wrapf(err, "failed to put timestamp into %v", p.TargetField)
+	}
+	return event, nil
+}
+
+func (p *processor) parse(value interface{}) (time.Time, error) {
+	for _, layout := range p.Layouts {
+		ts, err := parseValue(value, layout, p.loc)
+		if err == nil {
+			return ts.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("failed parsing time field %v='%v' with layouts %v",
+		p.Field, value, strings.Join(p.Layouts, ", "))
+}
+
+func (p *processor) String() string {
+	return fmt.Sprintf("timestamp=[field=%s, target_field=%s, timezone=%v]",
+		p.Field, p.TargetField, p.loc)
+}
@@ -0,0 +1,193 @@
+package timestamp
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+var expected = time.Date(2018, 6, 14, 18, 39, 43, 123000000, time.UTC)
+
+func newTestProcessor(t *testing.T, layouts ...string) *processor {
+	c := defaultConfig()
+	c.Field = "ts"
+	c.Layouts = layouts
+
+	p, err := newProcessor(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestParseLayouts(t *testing.T) {
+	tests := []struct {
+		layout string
+		value  interface{}
+	}{
+		{"2006-01-02T15:04:05.999Z07:00", "2018-06-14T18:39:43.123Z"},
+		{"Jan _2 15:04:05.000 2006", "Jun 14 18:39:43.123 2018"},
+		{"UNIX", "1529001583.123"},
+		{"UNIX", 1529001583.123},
+		{"UNIX_MS", "1529001583123"},
+		{"UNIX_MS", int64(1529001583123)},
+		{"ISO8601", "2018-06-14T18:39:43.123Z"},
+		{"ISO8601", "2018-06-14T20:39:43.123+02:00"},
+		{"ISO8601", "2018-06-14T20:39:43.123+0200"},
+		{"ISO8601", "2018-06-14 18:39:43.123"},
+		{"ISO8601", expected},
+	}
+
+	for _, test := range tests {
+		t.Run(test.layout, func(t *testing.T) {
+			p := newTestProcessor(t, test.layout)
+
+			evt := &beat.Event{Fields: common.MapStr{"ts": test.value}}
+			evt, err := p.Run(evt)
+			if assert.NoError(t, err) {
+				assert.True(t, expected.Equal(evt.Timestamp), "expected %v, got %v", expected, evt.Timestamp)
+			}
+		})
+	}
+}
+
+func TestLayoutFallback(t *testing.T) {
+	p := newTestProcessor(t, "2006-01-02", "UNIX_MS", "ISO8601")
+
+	evt := &beat.Event{Fields: common.MapStr{"ts": "2018-06-14T18:39:43.123Z"}}
+	evt, err := p.Run(evt)
+	if assert.NoError(t, err) {
+		assert.True(t, expected.Equal(evt.Timestamp))
+	}
+}
+
+func TestLayoutWithoutYear(t *testing.T) {
+	p := newTestProcessor(t, "Jan _2 15:04:05")
+
+	now := time.Now().UTC().Add(-time.Hour).Truncate(time.Second)
+	evt := &beat.Event{Fields: common.MapStr{"ts": now.Format("Jan _2 15:04:05")}}
+	evt, err := p.Run(evt)
+	if assert.NoError(t, err) {
+		assert.Equal(t, now, evt.Timestamp)
+	}
+}
+
+func TestAddCurrentYear(t *testing.T) {
+	now := time.Date(2018, 1, 2, 10, 0, 0, 0, time.UTC)
+	tests := map[time.Time]time.Time{
+		time.Date(0, 1, 1, 23, 0, 0, 0, time.UTC):   time.Date(2018, 1, 1, 23, 0, 0, 0, time.UTC),
+		time.Date(0, 1, 3, 8, 0, 0, 0, time.UTC):    time.Date(2018, 1, 3, 8, 0, 0, 0, time.UTC),
+		time.Date(0, 12, 31, 23, 0, 0, 0, time.UTC): time.Date(2017, 12, 31, 23, 0, 0, 0, time.UTC),
+		time.Date(0, 6, 14, 18, 0, 0, 0, time.UTC):  time.Date(2017, 6, 14, 18, 0, 0, 0, time.UTC),
+	}
+
+	for ts, expected := range tests {
+		assert.Equal(t, expected, addCurrentYear(ts, now), ts.String())
+	}
+}
+
+func TestTimezone(t *testing.T) {
+	for _, tz := range []string{"America/New_York", "-04:00", "-0400"} {
+		t.Run(tz, func(t *testing.T) {
+			c := defaultConfig()
+			c.Field = "ts"
+			c.Layouts = []string{"2006-01-02 15:04:05.000"}
+			c.Timezone = tz
+
+			p, err := newProcessor(c)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			evt := &beat.Event{Fields: common.MapStr{"ts": "2018-06-14 14:39:43.123"}}
+			evt, err = p.Run(evt)
+			if assert.NoError(t, err) {
+				assert.True(t, expected.Equal(evt.Timestamp), "expected %v, got %v", expected, evt.Timestamp)
+			}
+		})
+	}
+}
+
+func TestInvalidTimezone(t *testing.T) {
+	for _, tz := range []string{"Mars/Olympus_Mons", "+25:00"} {
+		c := defaultConfig()
+		c.Field = "ts"
+		c.Layouts = []string{"UNIX"}
+		c.Timezone = tz
+
+		_, err := newProcessor(c)
+		assert.Error(t, err, tz)
+	}
+}
+
+func TestTargetField(t *testing.T) {
+	c := defaultConfig()
+	c.Field = "ts"
+	c.TargetField = "event.created"
+	c.Layouts = []string{"UNIX_MS"}
+
+	p, err := newProcessor(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evt := &beat.Event{Fields: common.MapStr{"ts": int64(1529001583123)}}
+	evt, err = p.Run(evt)
+	if assert.NoError(t, err) {
+		v, err := evt.GetValue("event.created")
+		if assert.NoError(t, err) {
+			assert.Equal(t, common.Time(expected), v)
+		}
+		assert.True(t, evt.Timestamp.IsZero())
+	}
+}
+
+func TestParseFailure(t *testing.T) {
+	p := newTestProcessor(t, "UNIX", "ISO8601")
+
+	evt := &beat.Event{Fields: common.MapStr{"ts": "yesterday"}}
+	evt, err := p.Run(evt)
+	assert.Error(t, err)
+	assert.Equal(t, []string{flagParsingError}, evt.Fields["tags"])
+	assert.True(t, evt.Timestamp.IsZero())
+}
+
+func TestIgnoreMissing(t *testing.T) {
+	p := newTestProcessor(t, "UNIX")
+
+	evt := &beat.Event{Fields: common.MapStr{}}
+	_, err := p.Run(evt)
+	assert.Error(t, err)
+
+	p.IgnoreMissing = true
+	_, err = p.Run(evt)
+	assert.NoError(t, err)
+	assert.NotContains(t, evt.Fields, "tags")
+}
+
+func TestConfig(t *testing.T) {
+	cfg, err := common.NewConfigFrom(map[string]interface{}{
+		"field":   "ts",
+		"layouts": []string{"UNIX"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := newFromConfig(cfg)
+	if assert.NoError(t, err) {
+		assert.Equal(t, "timestamp=[field=ts, target_field=@timestamp, timezone=UTC]", p.String())
+	}
+
+	cfg, err = common.NewConfigFrom(map[string]interface{}{"field": "ts"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = newFromConfig(cfg)
+	assert.Error(t, err)
+}
@@ -9,11 +9,16 @@ import (
 	_ "github.com/elastic/beats/libbeat/outputs/console"
 	_ "github.com/elastic/beats/libbeat/outputs/elasticsearch"
 	_ "github.com/elastic/beats/libbeat/outputs/fileout"
+	_ "github.com/elastic/beats/libbeat/outputs/httpout"
 	_ "github.com/elastic/beats/libbeat/outputs/kafka"
 	_ "github.com/elastic/beats/libbeat/outputs/logstash"
 	_ "github.com/elastic/beats/libbeat/outputs/redis"
+	_ "github.com/elastic/beats/libbeat/outputs/syslog"
 
 	// load support output codec
+	_ "github.com/elastic/beats/libbeat/outputs/codec/avro"
+	_ "github.com/elastic/beats/libbeat/outputs/codec/cbor"
 	_ "github.com/elastic/beats/libbeat/outputs/codec/format"
 	_ "github.com/elastic/beats/libbeat/outputs/codec/json"
+	_ "github.com/elastic/beats/libbeat/outputs/codec/msgpack"
 )
@@ -6,7 +6,9 @@ import (
 
 	"github.com/elastic/beats/libbeat/beat"
 	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/outputs/dlq"
 	"github.com/elastic/beats/libbeat/processors"
+	"github.com/elastic/beats/libbeat/publisher/queue/spool"
 )
 
 // Config object for loading a pipeline instance via Load.
@@ -17,6 +19,91 @@ type Config struct {
 
 	// Event queue
 	Queue common.ConfigNamespace `config:"queue"`
+
+	// Named outputs, used instead of the single `output` namespace
+	Outputs []OutputConfig `config:"outputs"`
+}
+
+// OutputConfig configures a named output. Each named output has its own queue.
+// Events are forwarded to the output if the condition matches, or if the
+// output is listed in the events `@metadata.outputs` field.
+type OutputConfig struct {
+	Name      string                      `config:"name"   validate:"required"`
+	Condition *processors.ConditionConfig `config:"when"`
+	Queue     common.ConfigNamespace      `config:"queue"`
+	Output    common.ConfigNamespace      `config:"output"`
+}
+
+// Validate checks an output type is configured for the named output.
+func (c *OutputConfig) Validate() error {
+	if !c.Output.IsSet() {
+		return fmt.Errorf("no output configured for output '%v'", c.Name)
+	}
+	return nil
+}
+
+// Validate checks the named outputs have unique names and do not share a
+// spool file or a dead letter queue.
+func (c *Config) Validate() error {
+	spoolFiles := map[string]string{}
+	addSpool := func(name string, queue common.ConfigNamespace) error {
+		if queue.Name() != "spool" {
+			return nil
+		}
+
+		path, err := spool.FilePath(queue.Config())
+		if err != nil {
+			return err
+		}
+		if other, exists := spoolFiles[path]; exists {
+			return fmt.Errorf("the queues of %v and %v use the same spool file %v, configure a different file.path", other, name, path)
+		}
+		spoolFiles[path] = name
+		return nil
+	}
+	if err := addSpool("the pipeline", c.Queue); err != nil {
+		return err
+	}
+
+	names := map[string]bool{}
+	deadLetter := map[string]string{}
+	for _, out := range c.Outputs {
+		if names[out.Name] {
+			return fmt.Errorf("output name '%v' is used multiple times", out.Name)
+		}
+		names[out.Name] = true
+
+		if err := addSpool(fmt.Sprintf("output '%v'", out.Name), out.Queue); err != nil {
+			return err
+		}
+
+		path, err := deadLetterQueuePath(out.Output)
+		if err != nil {
+			return err
+		}
+		if other, exists := deadLetter[path]; exists && path != "" {
+			return fmt.Errorf("outputs '%v' and '%v' use the same dead letter queue, configure a different dead_letter_queue.path or dead_letter_queue.filename", other, out.Name)
+		}
+		deadLetter[path] = out.Name
+	}
+	return nil
+}
+
+// deadLetterQueuePath returns the dead letter queue file of an output, or an
+// empty string if the dead letter queue is disabled. The beat name is not
+// known yet, outputs using the default file name return the same path.
+func deadLetterQueuePath(out common.ConfigNamespace) (string, error) {
+	settings := struct {
+		DeadLetterQueue dlq.Config `config:"dead_letter_queue"`
+	}{dlq.DefaultConfig()}
+	if err := out.Config().Unpack(&settings); err != nil {
+		return "", err
+	}
+
+	if !settings.DeadLetterQueue.Enabled {
+		return "", nil
+	}
+	return settings.DeadLetterQueue.FilePath("beat"), nil
 }
 
 // validateClientConfig checks a ClientConfig can be used with (*Pipeline).ConnectWith.
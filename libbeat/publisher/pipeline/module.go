@@ -68,7 +68,15 @@ func Load(
 		return nil, err
 	}
 
-	out, err := loadOutput(beatInfo, reg, outcfg)
+	var out outputs.Group
+	if len(config.Outputs) > 0 {
+		if outcfg.IsSet() {
+			return nil, errors.New("'output' and 'outputs' can not be configured at the same time")
+		}
+		out, err = loadOutputRouter(beatInfo, reg, config.Outputs)
+	} else {
+		out, err = loadOutput(beatInfo, reg, outcfg)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -101,23 +109,108 @@ func loadOutput(
 
 	var (
 		outReg   *monitoring.Registry
-		outStats outputs.Observer
+		outStats *outputs.Stats
 	)
 	if reg != nil {
 		outReg = reg.NewRegistry("output")
 		outStats = outputs.NewStats(outReg)
 	}
 
-	out, err := outputs.Load(beatInfo, outStats, outcfg.Name(), outcfg.Config())
+	out, err := outputs.Load(beatInfo, statsObserver(outStats), outcfg.Name(), outcfg.Config())
 	if err != nil {
 		return outputs.Fail(err)
 	}
 
 	if outReg != nil {
 		monitoring.NewString(outReg, "type").Set(outcfg.Name())
+		outputs.RegisterHealth(outcfg.Name(), outStats.Health())
+	}
+
+	return outputs.WithHealth(out, outStats.Health()), nil
+}
+
+// loadOutputRouter loads all named outputs. The outputs are combined into a
+// single output group, forwarding events to the outputs queues.
+func loadOutputRouter(
+	beatInfo beat.Info,
+	reg *monitoring.Registry,
+	configs []OutputConfig,
+) (outputs.Group, error) {
+	if publishDisabled {
+		return outputs.Group{}, nil
+	}
+
+	var outReg *monitoring.Registry
+	if reg != nil {
+		outReg = reg.NewRegistry("outputs")
+	}
+
+	log := logp.NewLogger("publish")
+	routed := make([]*routedOutput, 0, len(configs))
+	fail := func(err error) (outputs.Group, error) {
+		for _, out := range routed {
+			out.close()
+		}
+		return outputs.Fail(err)
 	}
 
-	return out, nil
+	for _, config := range configs {
+		var condition *processors.Condition
+		if config.Condition != nil {
+			var err error
+			condition, err = processors.NewCondition(config.Condition)
+			if err != nil {
+				return fail(fmt.Errorf("invalid condition for output '%v': %v", config.Name, err))
+			}
+		}
+
+		queueBuilder, err := createQueueBuilder(config.Queue)
+		if err != nil {
+			return fail(err)
+		}
+
+		var stats *outputs.Stats
+		var statsReg *monitoring.Registry
+		if outReg != nil {
+			statsReg = outReg.NewRegistry(config.Name)
+			stats = outputs.NewStats(statsReg)
+		}
+
+		outName := config.Output.Name()
+		out, err := outputs.Load(beatInfo, statsObserver(stats), outName, config.Output.Config())
+		if err != nil {
+			return fail(fmt.Errorf("failed to load output '%v': %v", config.Name, err))
+		}
+
+		if statsReg != nil {
+			monitoring.NewString(statsReg, "type").Set(outName)
+			outputs.RegisterHealth(config.Name, stats.Health())
+		}
+		out = outputs.WithHealth(out, stats.Health())
+
+		o, err := newRoutedOutput(log, config.Name, condition, queueBuilder, out)
+		if err != nil {
+			return fail(err)
+		}
+		routed = append(routed, o)
+
+		logp.Info("Output '%v' of type %v loaded", config.Name, outName)
+	}
+
+	return outputs.Group{
+		Clients:   []outputs.Client{newOutputRouter(log, routed)},
+		BatchSize: 0,  // batch size is decided by the queue
+		Retry:     -1, // the router never fails events
+	}, nil
+}
+
+// statsObserver returns stats as outputs.Observer, such that outputs can detect
+// missing stats by comparing the observer with nil.
+func statsObserver(stats *outputs.Stats) outputs.Observer {
+	if stats == nil {
+		return nil
+	}
+	return stats
 }
 
 func createQueueBuilder(config common.ConfigNamespace) (func(queue.Eventer) (queue.Queue, error), error) {
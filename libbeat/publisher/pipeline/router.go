@@ -0,0 +1,248 @@
+package pipeline
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common/atomic"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/processors"
+	"github.com/elastic/beats/libbeat/publisher"
+	"github.com/elastic/beats/libbeat/publisher/queue"
+)
+
+// outputRouter forwards events to multiple named outputs. Each output has its
+// own queue and output controller, retrying and dropping events independently
+// of the other outputs.
+// The router is installed as the only output client of the pipeline. A batch
+// of the pipeline queue is ACKed, once all outputs targeted by the events in
+// the batch have ACKed the events. This way clients receive ACKs only after
+// the events have been published to all outputs.
+type outputRouter struct {
+	logger  *logp.Logger
+	outputs []*routedOutput
+}
+
+// routedOutput is a named output with its own queue.
+type routedOutput struct {
+	name      string
+	condition *processors.Condition
+
+	queue      queue.Queue
+	producer   queue.Producer
+	controller *outputController
+
+	// batches waiting for ACKs from the outputs queue, in publish order
+	mutex   sync.Mutex
+	pending []routedACK
+}
+
+type routedACK struct {
+	batch *routedBatch
+	count int
+}
+
+// routedBatch tracks the number of events not yet ACKed by the outputs.
+type routedBatch struct {
+	batch   publisher.Batch
+	pending atomic.Int32
+}
+
+var errRouterClosed = errors.New("output router closed")
+
+// metadataOutputsKey is the @metadata field listing the outputs an event
+// is forwarded to. If set, the output conditions are ignored.
+const metadataOutputsKey = "outputs"
+
+func newOutputRouter(log *logp.Logger, outputs []*routedOutput) *outputRouter {
+	return &outputRouter{logger: log, outputs: outputs}
+}
+
+func newRoutedOutput(
+	log *logp.Logger,
+	name string,
+	condition *processors.Condition,
+	queueFactory queueFactory,
+	out outputs.Group,
+) (*routedOutput, error) {
+	o := &routedOutput{
+		name:      name,
+		condition: condition,
+	}
+
+	var err error
+	o.queue, err = queueFactory(&pipelineEventer{observer: nilObserver})
+	if err != nil {
+		return nil, err
+	}
+
+	o.producer = o.queue.Producer(queue.ProducerConfig{ACK: o.onACK})
+	o.controller = newOutputController(log, nilObserver, o.queue)
+	o.controller.Set(out)
+	return o, nil
+}
+
+// Publish forwards the events of the batch to the outputs queues. Publish
+// blocks if an outputs queue is full.
+func (r *outputRouter) Publish(batch publisher.Batch) error {
+	events := batch.Events()
+
+	targets := make([][]publisher.Event, len(r.outputs))
+	total := 0
+	for i := range events {
+		event := &events[i]
+
+		names, explicit := metadataOutputs(&event.Content)
+		first := true
+		for j, out := range r.outputs {
+			if !out.accepts(&event.Content, names, explicit) {
+				continue
+			}
+
+			// outputs might modify the event, so every output but the first one
+			// gets a copy of the event
+			e := *event
+			if !first {
+				e.Content = copyEvent(&event.Content)
+			}
+			first = false
+
+			targets[j] = append(targets[j], e)
+			total++
+		}
+	}
+
+	if total == 0 {
+		batch.ACK()
+		return nil
+	}
+
+	routed := &routedBatch{batch: batch}
+	routed.pending.Store(int32(total))
+
+	// register the batch with all targeted outputs, before publishing any
+	// event, so ACKs can not be received for not yet registered batches
+	for i, out := range r.outputs {
+		if n := len(targets[i]); n > 0 {
+			out.addPending(routed, n)
+		}
+	}
+
+	for i, out := range r.outputs {
+		for _, event := range targets[i] {
+			if !out.producer.Publish(event) {
+				return errRouterClosed
+			}
+		}
+	}
+	return nil
+}
+
+// Close stops all outputs and closes the outputs queues.
+func (r *outputRouter) Close() error {
+	for _, out := range r.outputs {
+		out.close()
+	}
+	return nil
+}
+
+func (o *routedOutput) accepts(event *beat.Event, names []string, explicit bool) bool {
+	if explicit {
+		for _, name := range names {
+			if name == o.name {
+				return true
+			}
+		}
+		return false
+	}
+
+	return o.condition == nil || o.condition.Check(event)
+}
+
+func (o *routedOutput) addPending(batch *routedBatch, count int) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.pending = append(o.pending, routedACK{batch: batch, count: count})
+}
+
+// onACK is called by the outputs queue with the number of events ACKed, in
+// the order the events have been published.
+func (o *routedOutput) onACK(n int) {
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+
+	for n > 0 && len(o.pending) > 0 {
+		head := &o.pending[0]
+
+		count := head.count
+		if n < count {
+			count = n
+		}
+		head.count -= count
+		n -= count
+
+		head.batch.ack(count)
+		if head.count == 0 {
+			o.pending[0] = routedACK{}
+			o.pending = o.pending[1:]
+		}
+	}
+}
+
+func (o *routedOutput) close() {
+	o.controller.Close()
+	o.producer.Cancel()
+	if err := o.queue.Close(); err != nil {
+		logp.Err("Failed to close queue of output %v: %v", o.name, err)
+	}
+}
+
+// ack ACKs the original batch, once all events have been ACKed by all
+// targeted outputs.
+func (b *routedBatch) ack(n int) {
+	if b.pending.Sub(int32(n)) == 0 {
+		b.batch.ACK()
+	}
+}
+
+// metadataOutputs returns the output names listed in @metadata.outputs. The
+// field can be a string or a list of strings.
+func metadataOutputs(event *beat.Event) ([]string, bool) {
+	if event.Meta == nil {
+		return nil, false
+	}
+
+	v, ok := event.Meta[metadataOutputsKey]
+	if !ok {
+		return nil, false
+	}
+
+	switch names := v.(type) {
+	case string:
+		return []string{names}, true
+	case []string:
+		return names, true
+	case []interface{}:
+		lst := make([]string, 0, len(names))
+		for _, name := range names {
+			if s, ok := name.(string); ok {
+				lst = append(lst, s)
+			}
+		}
+		return lst, true
+	}
+	return nil, false
+}
+
+func copyEvent(event *beat.Event) beat.Event {
+	e := *event
+	if event.Fields != nil {
+		e.Fields = event.Fields.Clone()
+	}
+	if event.Meta != nil {
+		e.Meta = event.Meta.Clone()
+	}
+	return e
+}
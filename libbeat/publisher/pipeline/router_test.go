@@ -0,0 +1,331 @@
+// +build !integration
+
+package pipeline
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/common/atomic"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/outputs"
+	"github.com/elastic/beats/libbeat/processors"
+	"github.com/elastic/beats/libbeat/publisher"
+	"github.com/elastic/beats/libbeat/publisher/queue"
+	"github.com/elastic/beats/libbeat/publisher/queue/memqueue"
+)
+
+// collectingClient records all events published and ACKs batches, once
+// the hold channel is closed.
+type collectingClient struct {
+	mutex  sync.Mutex
+	events []beat.Event
+	hold   chan struct{}
+}
+
+func newCollectingClient(hold bool) *collectingClient {
+	c := &collectingClient{hold: make(chan struct{})}
+	if !hold {
+		close(c.hold)
+	}
+	return c
+}
+
+func (c *collectingClient) Close() error { return nil }
+
+func (c *collectingClient) Publish(batch publisher.Batch) error {
+	c.mutex.Lock()
+	for _, event := range batch.Events() {
+		c.events = append(c.events, event.Content)
+	}
+	c.mutex.Unlock()
+
+	<-c.hold
+	batch.ACK()
+	return nil
+}
+
+func (c *collectingClient) published() []beat.Event {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return append([]beat.Event(nil), c.events...)
+}
+
+func testQueueFactory(eventer queue.Eventer) (queue.Queue, error) {
+	return memqueue.NewBroker(memqueue.Settings{
+		Eventer:        eventer,
+		Events:         64,
+		FlushMinEvents: 1,
+	}), nil
+}
+
+func newTestRoutedOutput(t *testing.T, name string, cond map[string]interface{}, client outputs.Client) *routedOutput {
+	var condition *processors.Condition
+	if cond != nil {
+		cfg, err := common.NewConfigFrom(cond)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var condCfg processors.ConditionConfig
+		if err := cfg.Unpack(&condCfg); err != nil {
+			t.Fatal(err)
+		}
+
+		condition, err = processors.NewCondition(&condCfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	out, err := newRoutedOutput(logp.NewLogger("test"), name, condition, testQueueFactory, outputs.Group{
+		Clients: []outputs.Client{client},
+		Retry:   3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func newTestRouterPipeline(t *testing.T, routed ...*routedOutput) (*Pipeline, beat.Client, *atomic.Int32) {
+	group := outputs.Group{
+		Clients: []outputs.Client{newOutputRouter(logp.NewLogger("test"), routed)},
+		Retry:   -1,
+	}
+
+	p, err := New(beat.Info{}, nil, testQueueFactory, group, Settings{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	acked := atomic.NewInt32(0)
+	client, err := p.ConnectWith(beat.ClientConfig{
+		ACKCount: func(n int) { acked.Add(int32(n)) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, client, acked
+}
+
+func waitFor(t *testing.T, msg string, check func() bool) {
+	for start := time.Now(); time.Since(start) < 5*time.Second; {
+		if check() {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal(msg)
+}
+
+func testEvent(typ string) beat.Event {
+	return beat.Event{
+		Timestamp: time.Now(),
+		Fields:    common.MapStr{"type": typ},
+	}
+}
+
+func TestRouterConditions(t *testing.T) {
+	all := newCollectingClient(false)
+	archive := newCollectingClient(false)
+
+	p, client, acked := newTestRouterPipeline(t,
+		newTestRoutedOutput(t, "all", nil, all),
+		newTestRoutedOutput(t, "archive", map[string]interface{}{
+			"equals.type": "archive",
+		}, archive),
+	)
+	defer p.Close()
+	defer client.Close()
+
+	client.Publish(testEvent("log"))
+	client.Publish(testEvent("archive"))
+	client.Publish(testEvent("log"))
+
+	waitFor(t, "events not ACKed", func() bool { return acked.Load() == 3 })
+	assert.Len(t, all.published(), 3)
+	if events := archive.published(); assert.Len(t, events, 1) {
+		assert.Equal(t, "archive", events[0].Fields["type"])
+	}
+}
+
+func TestRouterMetadataOutputs(t *testing.T) {
+	first := newCollectingClient(false)
+	second := newCollectingClient(false)
+
+	p, client, acked := newTestRouterPipeline(t,
+		newTestRoutedOutput(t, "first", nil, first),
+		newTestRoutedOutput(t, "second", nil, second),
+	)
+	defer p.Close()
+	defer client.Close()
+
+	event := testEvent("log")
+	event.Meta = common.MapStr{"outputs": []string{"second"}}
+	client.Publish(event)
+
+	event = testEvent("log")
+	event.Meta = common.MapStr{"outputs": "unknown"}
+	client.Publish(event)
+
+	client.Publish(testEvent("log"))
+
+	waitFor(t, "events not ACKed", func() bool { return acked.Load() == 3 })
+	assert.Len(t, first.published(), 1)
+	assert.Len(t, second.published(), 2)
+}
+
+func TestRouterACKAfterAllOutputs(t *testing.T) {
+	fast := newCollectingClient(false)
+	slow := newCollectingClient(true)
+
+	p, client, acked := newTestRouterPipeline(t,
+		newTestRoutedOutput(t, "fast", nil, fast),
+		newTestRoutedOutput(t, "slow", nil, slow),
+	)
+	defer p.Close()
+	defer client.Close()
+
+	client.Publish(testEvent("log"))
+	client.Publish(testEvent("log"))
+
+	waitFor(t, "events not published", func() bool {
+		return len(fast.published()) == 2 && len(slow.published()) > 0
+	})
+
+	// give the pipeline a chance to (wrongly) forward the ACK of the first output
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, int32(0), acked.Load())
+
+	close(slow.hold)
+	waitFor(t, "events not ACKed", func() bool { return acked.Load() == 2 })
+}
+
+func TestRouterEventsAreCopied(t *testing.T) {
+	first := newCollectingClient(false)
+	second := newCollectingClient(false)
+
+	router := newOutputRouter(logp.NewLogger("test"), []*routedOutput{
+		newTestRoutedOutput(t, "first", nil, first),
+		newTestRoutedOutput(t, "second", nil, second),
+	})
+	defer router.Close()
+
+	batch := &testBatch{events: []publisher.Event{{Content: testEvent("log")}}}
+	assert.NoError(t, router.Publish(batch))
+
+	waitFor(t, "batch not ACKed", batch.isACKed)
+	events := append(first.published(), second.published()...)
+	if assert.Len(t, events, 2) {
+		events[0].Fields["type"] = "modified"
+		assert.Equal(t, "log", events[1].Fields["type"])
+	}
+}
+
+type testBatch struct {
+	events []publisher.Event
+	acked  atomic.Bool
+}
+
+func (b *testBatch) Events() []publisher.Event               { return b.events }
+func (b *testBatch) ACK()                                     { b.acked.Store(true) }
+func (b *testBatch) Drop()                                    {}
+func (b *testBatch) Retry()                                   {}
+func (b *testBatch) Cancelled()                               {}
+func (b *testBatch) RetryEvents(events []publisher.Event)     {}
+func (b *testBatch) CancelledEvents(events []publisher.Event) {}
+func (b *testBatch) isACKed() bool                            { return b.acked.Load() }
+
+func TestConfigSharedDeadLetterQueue(t *testing.T) {
+	tests := []struct {
+		name   string
+		second map[string]interface{}
+		valid  bool
+	}{
+		{"same path", map[string]interface{}{"dead_letter_queue.enabled": true}, false},
+		{"different path", map[string]interface{}{"dead_letter_queue.enabled": true, "dead_letter_queue.filename": "b.dlq"}, true},
+		{"disabled", map[string]interface{}{}, true},
+	}
+
+	for _, test := range tests {
+		cfg, err := common.NewConfigFrom(map[string]interface{}{
+			"outputs": []map[string]interface{}{
+				{"name": "a", "output.elasticsearch.dead_letter_queue.enabled": true},
+				{"name": "b", "output.elasticsearch": test.second},
+			},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var config Config
+		err = cfg.Unpack(&config)
+		if test.valid {
+			assert.NoError(t, err, test.name)
+		} else {
+			assert.Error(t, err, test.name)
+		}
+	}
+}
+
+func TestConfigSharedSpoolFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]interface{}
+		valid    bool
+	}{
+		{
+			"default paths",
+			map[string]interface{}{
+				"outputs": []map[string]interface{}{
+					{"name": "a", "queue.spool": nil, "output.console": nil},
+					{"name": "b", "queue.spool": nil, "output.console": nil},
+				},
+			},
+			false,
+		},
+		{
+			"pipeline queue",
+			map[string]interface{}{
+				"queue.spool.file.path": "/tmp/a.dat",
+				"outputs": []map[string]interface{}{
+					{"name": "a", "queue.spool.file.path": "/tmp/a.dat", "output.console": nil},
+				},
+			},
+			false,
+		},
+		{
+			"different paths",
+			map[string]interface{}{
+				"queue.spool": nil,
+				"outputs": []map[string]interface{}{
+					{"name": "a", "queue.spool.file.path": "/tmp/a.dat", "output.console": nil},
+					{"name": "b", "queue.spool.file.path": "/tmp/b.dat", "output.console": nil},
+					{"name": "c", "output.console": nil},
+				},
+			},
+			true,
+		},
+	}
+
+	for _, test := range tests {
+		cfg, err := common.NewConfigFrom(test.settings)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var config Config
+		err = cfg.Unpack(&config)
+		if test.valid {
+			assert.NoError(t, err, test.name)
+		} else {
+			assert.Error(t, err, test.name)
+		}
+	}
+}
@@ -25,6 +25,7 @@ type pathConfig struct {
 	MaxSize     cfgtype.ByteSize `config:"size"`
 	PageSize    cfgtype.ByteSize `config:"page_size"`
 	Prealloc    bool             `config:"prealloc"`
+	Recover     bool             `config:"recover"`
 }
 
 type writeConfig struct {
@@ -32,6 +33,7 @@ type writeConfig struct {
 	FlushEvents  time.Duration    `config:"flush.events"`
 	FlushTimeout time.Duration    `config:"flush.timeout"`
 	Codec        codecID          `config:"codec"`
+	FullPolicy   fullPolicy       `config:"full_policy"`
 }
 
 type readConfig struct {
@@ -46,12 +48,14 @@ func defaultConfig() config {
 			MaxSize:     100 * humanize.MiByte,
 			PageSize:    4 * humanize.KiByte,
 			Prealloc:    true,
+			Recover:     true,
 		},
 		Write: writeConfig{
 			BufferSize:   1 * humanize.MiByte,
 			FlushTimeout: 1 * time.Second,
 			FlushEvents:  16 * 1024,
 			Codec:        codecCBORL,
+			FullPolicy:   fullPolicyBlock,
 		},
 		Read: readConfig{
 			FlushTimeout: 0,
@@ -110,3 +114,18 @@ func (c *codecID) Unpack(value string) error {
 	*c = id
 	return nil
 }
+
+func (p *fullPolicy) Unpack(value string) error {
+	policies := map[string]fullPolicy{
+		"block":       fullPolicyBlock,
+		"drop_oldest": fullPolicyDropOldest,
+	}
+
+	policy, exists := policies[strings.ToLower(value)]
+	if !exists {
+		return fmt.Errorf("full_policy '%v' not available", value)
+	}
+
+	*p = policy
+	return nil
+}
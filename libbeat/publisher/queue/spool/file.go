@@ -0,0 +1,144 @@
+package spool
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/elastic/go-txfile"
+	"github.com/elastic/go-txfile/pq"
+)
+
+// spoolFile is an opened spool file with the initialized queue.
+type spoolFile struct {
+	file  *txfile.File
+	queue *pq.Queue
+}
+
+const newFileSuffix = ".new"
+
+// openSpoolFile opens the spool file at path. A new file is created in a
+// temporary location first and moved into place once the queue root has been
+// committed, so a crash during file creation never leaves a partial file
+// behind.
+// If recover is set and the existing file or queue can not be read, the file
+// is moved to '<path>.corrupted-<timestamp>' and a new file is created.
+func openSpoolFile(
+	log logger,
+	path string,
+	mode os.FileMode,
+	opts txfile.Options,
+	settings pq.Settings,
+	recover bool,
+) (*spoolFile, error) {
+	if _, err := os.Lstat(path); os.IsNotExist(err) {
+		if err := createSpoolFile(path, mode, opts); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, err
+	}
+
+	f, err := tryOpenSpoolFile(path, mode, opts, settings)
+	if err == nil || !recover || !isCorruptedFileErr(err) {
+		return f, err
+	}
+
+	backup := fmt.Sprintf("%v.corrupted-%v", path, time.Now().Unix())
+	log.Errorf("Spool file %v can not be opened (%v). Moving the file to %v and creating a new spool file.",
+		path, err, backup)
+	if err := os.Rename(path, backup); err != nil {
+		return nil, errors.Wrapf(err, "failed to move corrupted spool file to %v", backup)
+	}
+
+	if err := createSpoolFile(path, mode, opts); err != nil {
+		return nil, err
+	}
+	return tryOpenSpoolFile(path, mode, opts, settings)
+}
+
+func tryOpenSpoolFile(
+	path string,
+	mode os.FileMode,
+	opts txfile.Options,
+	settings pq.Settings,
+) (*spoolFile, error) {
+	f, err := txfile.Open(path, mode, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	ok := false
+	defer ifNotOK(&ok, ignoreErr(f.Close))
+
+	delegate, err := pq.NewStandaloneDelegate(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// pq.New validates the queue root
+	queue, err := pq.New(delegate, settings)
+	if err != nil {
+		return nil, err
+	}
+
+	ok = true
+	return &spoolFile{file: f, queue: queue}, nil
+}
+
+// createSpoolFile initializes a new spool file in a temporary file and moves
+// it to path.
+func createSpoolFile(path string, mode os.FileMode, opts txfile.Options) error {
+	tmpPath := path + newFileSuffix
+	if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	f, err := txfile.Open(tmpPath, mode, opts)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create spool file '%v'", tmpPath)
+	}
+
+	_, err = pq.NewStandaloneDelegate(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return errors.Wrapf(err, "failed to initialize spool file '%v'", tmpPath)
+	}
+
+	return os.Rename(tmpPath, path)
+}
+
+// corruptedFileErrs lists the messages of the go-txfile and pq errors caused
+// by invalid file contents. The errors are not exported by go-txfile, so they
+// are matched by message.
+var corruptedFileErrs = map[string]bool{
+	// txfile meta page validation
+	"invalid magic number":   true,
+	"invalid version number": true,
+	"checksum mismatch":      true,
+	"out of bounds page id":  true,
+
+	// pq root validation
+	"no queue root":         true,
+	"incomplete queue root": true,
+	"invalid queue version": true,
+}
+
+// isCorruptedFileErr checks if an error returned when opening the spool file
+// is caused by invalid file contents. Only known corruption errors are
+// reported, so a healthy file is never moved if it can not be opened for
+// other reasons, like I/O errors or the file being locked. A file too short
+// to hold the meta pages is considered corrupted.
+func isCorruptedFileErr(err error) bool {
+	cause := errors.Cause(err)
+	if cause == io.EOF || cause == io.ErrUnexpectedEOF {
+		return true
+	}
+	return corruptedFileErrs[cause.Error()]
+}
@@ -0,0 +1,337 @@
+package spool
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	humanize "github.com/dustin/go-humanize"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/publisher"
+	"github.com/elastic/beats/libbeat/publisher/queue"
+	"github.com/elastic/go-txfile"
+)
+
+func TestOpenSpoolFileRecover(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool-recover")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "spool.dat")
+	garbage := make([]byte, 16*humanize.KiByte)
+	for i := range garbage {
+		garbage[i] = 0xff
+	}
+	if err := ioutil.WriteFile(path, garbage, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = NewSpool(&testLogger{t}, path, makeTestSettings(false))
+	assert.Error(t, err, "corrupted file must not be opened without recover")
+
+	spool, err := NewSpool(&testLogger{t}, path, makeTestSettings(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := spool.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	backups, err := filepath.Glob(path + ".corrupted-*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if assert.Len(t, backups, 1) {
+		content, err := ioutil.ReadFile(backups[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+		assert.Equal(t, garbage, content)
+	}
+
+	_, err = os.Stat(path + newFileSuffix)
+	assert.True(t, os.IsNotExist(err), "temporary file must be removed")
+}
+
+func TestIsCorruptedFileErr(t *testing.T) {
+	tests := []struct {
+		err       error
+		corrupted bool
+	}{
+		{errors.New("invalid magic number"), true},
+		{errors.New("checksum mismatch"), true},
+		{errors.Wrap(errors.New("no queue root"), "failed to open"), true},
+		{io.EOF, true},
+		{fmt.Errorf("file %v is already locked", "spool.dat"), false},
+		{fmt.Errorf("truncation failed with %v", "no space left on device"), false},
+		{fmt.Errorf("initializing data file failed with %v", "permission denied"), false},
+		{&os.PathError{Op: "open", Path: "spool.dat", Err: os.ErrPermission}, false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.corrupted, isCorruptedFileErr(test.err), test.err.Error())
+	}
+}
+
+func TestInspect(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool-inspect")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "spool.dat")
+	spool, err := NewSpool(&testLogger{t}, path, makeTestSettings(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	const total = 10
+	producer := spool.Producer(queue.ProducerConfig{})
+	for i := 0; i < total; i++ {
+		producer.Publish(publisher.Event{
+			Content: beat.Event{
+				Timestamp: time.Now(),
+				Fields:    common.MapStr{"i": i},
+			},
+		})
+	}
+
+	// wait for all events to be flushed to the file
+	for start := time.Now(); ; time.Sleep(10 * time.Millisecond) {
+		events, _ := spool.stats()
+		if events == total {
+			break
+		}
+		if time.Since(start) > 5*time.Second {
+			t.Fatalf("timeout waiting for events to be flushed (events=%v)", events)
+		}
+	}
+	if err := spool.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var fields []common.MapStr
+	info, err := Inspect(path, 3, func(event beat.Event, err error) error {
+		if err != nil {
+			t.Fatal(err)
+		}
+		fields = append(fields, event.Fields)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, path, info.Path)
+	assert.Equal(t, uint64(total), info.Events)
+	assert.True(t, info.FileSize > 0)
+	if assert.Len(t, fields, 3) {
+		for i, f := range fields {
+			v, err := f.GetValue("i")
+			if err != nil {
+				t.Fatal(err)
+			}
+			assert.EqualValues(t, i, v)
+		}
+	}
+}
+
+func TestFullPolicyUnpack(t *testing.T) {
+	tests := map[string]struct {
+		policy   fullPolicy
+		expected bool
+	}{
+		"block":       {fullPolicyBlock, true},
+		"drop_oldest": {fullPolicyDropOldest, true},
+		"Drop_Oldest": {fullPolicyDropOldest, true},
+		"drop":        {0, false},
+	}
+
+	for value, test := range tests {
+		var p fullPolicy
+		err := p.Unpack(value)
+		if !test.expected {
+			assert.Error(t, err, value)
+			continue
+		}
+		if assert.NoError(t, err, value) {
+			assert.Equal(t, test.policy, p, value)
+		}
+	}
+}
+
+func TestAgeTracker(t *testing.T) {
+	var tracker ageTracker
+	t0 := time.Unix(1000, 0)
+
+	_, ok := tracker.oldest()
+	assert.False(t, ok)
+
+	tracker.add(2, t0)
+	tracker.add(3, t0.Add(time.Second))
+	tracker.add(0, t0.Add(2*time.Second))
+
+	ts, ok := tracker.oldest()
+	assert.True(t, ok)
+	assert.Equal(t, t0, ts)
+
+	tracker.remove(1)
+	ts, _ = tracker.oldest()
+	assert.Equal(t, t0, ts)
+
+	tracker.remove(2)
+	ts, _ = tracker.oldest()
+	assert.Equal(t, t0.Add(time.Second), ts)
+
+	tracker.remove(10)
+	_, ok = tracker.oldest()
+	assert.False(t, ok)
+}
+
+func makeTestSettings(recover bool) Settings {
+	return Settings{
+		WriteBuffer:       4 * humanize.KiByte,
+		WriteFlushTimeout: 10 * time.Millisecond,
+		Codec:             codecCBORL,
+		Recover:           recover,
+		Mode:              0600,
+		File: txfile.Options{
+			MaxSize:  128 * humanize.KiByte,
+			PageSize: 4 * humanize.KiByte,
+		},
+	}
+}
+
+func TestDropOldestDoesNotBlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool-drop")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	settings := makeTestSettings(true)
+	settings.FullPolicy = fullPolicyDropOldest
+	settings.File.MaxSize = 256 * humanize.KiByte
+
+	spool, err := NewSpool(&testLogger{t}, filepath.Join(dir, "spool.dat"), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+
+	const total = 10000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		producer := spool.Producer(queue.ProducerConfig{})
+		for i := 0; i < total; i++ {
+			producer.Publish(publisher.Event{
+				Content: beat.Event{
+					Timestamp: time.Now(),
+					Fields:    common.MapStr{"i": i, "message": "some message to fill the spool file"},
+				},
+			})
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("producer blocked on full spool file")
+	}
+
+	events, _ := spool.stats()
+	assert.True(t, events < total, "oldest events must be dropped")
+}
+
+func TestDropOldestWithPendingBatch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "spool-drop-pending")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	settings := makeTestSettings(true)
+	settings.FullPolicy = fullPolicyDropOldest
+	settings.File.MaxSize = 256 * humanize.KiByte
+
+	spool, err := NewSpool(&testLogger{t}, filepath.Join(dir, "spool.dat"), settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer spool.Close()
+
+	producer := spool.Producer(queue.ProducerConfig{})
+	publish := func(i int) {
+		producer.Publish(publisher.Event{
+			Content: beat.Event{
+				Timestamp: time.Now(),
+				Fields:    common.MapStr{"i": i, "message": "some message to fill the spool file"},
+			},
+		})
+	}
+
+	const batchSize = 10
+	for i := 0; i < batchSize; i++ {
+		publish(i)
+	}
+
+	// The consumer holds the batch without ACKing it, like an output
+	// failing to publish the events.
+	consumer := spool.Consumer()
+	pending, err := consumer.Get(batchSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	held := len(pending.Events())
+	if held == 0 {
+		t.Fatal("no events received")
+	}
+
+	const total = 10000
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := batchSize; i < total; i++ {
+			publish(i)
+		}
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("producer blocked on full spool file while a batch is not ACKed")
+	}
+
+	// The pending batch is still valid and can be ACKed later on.
+	assert.Len(t, pending.Events(), held)
+	pending.ACK()
+
+	batch, err := consumer.Get(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := batch.Events()[0].Content.Fields.GetValue("i")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the decoded integer type depends on the value
+	i, err := strconv.Atoi(fmt.Sprint(v))
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.True(t, i > held, "oldest unread events must be dropped")
+	batch.ACK()
+}
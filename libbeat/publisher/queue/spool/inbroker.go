@@ -45,6 +45,10 @@ type inBroker struct {
 	timer       *timer
 	flushEvents uint
 
+	// dropOldest asks the output broker to remove the n oldest unread events,
+	// if the queue is full. Producers are blocked if dropOldest is nil.
+	dropOldest func(n uint)
+
 	enc *encoder
 }
 
@@ -60,6 +64,7 @@ func newInBroker(
 	codec codecID,
 	flushTimeout time.Duration,
 	flushEvents uint,
+	dropOldest func(uint),
 ) (*inBroker, error) {
 	enc, err := newEncoder(codec)
 	if err != nil {
@@ -87,6 +92,7 @@ func newInBroker(
 		// internal
 		timer:       newTimer(flushTimeout),
 		flushEvents: flushEvents,
+		dropOldest:  dropOldest,
 		enc:         enc,
 	}
 
@@ -119,9 +125,16 @@ func (b *inBroker) onFlush(n uint) {
 // events and freed pages will is reported.
 // Flush events are forward to the brokers eventloop, so to give the broker a
 // chance to retry writing in case it has been blocked on a full queue.
+// If events are dropped when the queue is full, every ACK is forwarded, so the
+// broker can request more events to be dropped if no page has been freed yet.
 func (b *inBroker) onACK(events, pages uint) {
 	if pages > 0 {
 		b.sigACK <- struct{}{}
+	} else if b.dropOldest != nil {
+		select {
+		case b.sigACK <- struct{}{}:
+		default:
+		}
 	}
 }
 
@@ -254,7 +267,7 @@ func (b *inBroker) stateEmpty() bool {
 		err = b.addEvent(buf, st)
 		if err != nil {
 			log.Debug("  inbroker: append failed, blocking")
-			b.state = (*inBroker).stateBlocked
+			b.block()
 			break
 		}
 
@@ -264,7 +277,7 @@ func (b *inBroker) stateEmpty() bool {
 			err := b.flushBuffer()
 			if err != nil {
 				log.Debug("  inbroker (stateEmpty): flush failed, blocking")
-				b.state = (*inBroker).stateBlocked
+				b.block()
 			}
 			break
 
@@ -317,7 +330,7 @@ func (b *inBroker) stateWithTimer() bool {
 		err = b.addEvent(buf, st)
 		if err != nil {
 			log.Debug("  inbroker (stateWithTimer): append failed, blocking")
-			b.state = (*inBroker).stateBlocked
+			b.block()
 			break
 		}
 
@@ -326,7 +339,7 @@ func (b *inBroker) stateWithTimer() bool {
 			err := b.flushBuffer()
 			if err != nil {
 				log.Debug("  inbroker (stateWithTimer): flush failed, blocking")
-				b.state = (*inBroker).stateBlocked
+				b.block()
 				break
 			}
 
@@ -360,7 +373,7 @@ func (b *inBroker) stateWithTimer() bool {
 		err := b.flushBuffer()
 		if err != nil {
 			log.Debug("  inbroker (stateWithTimer): flush failed, blocking")
-			b.state = (*inBroker).stateBlocked
+			b.block()
 			break
 		}
 
@@ -391,6 +404,9 @@ func (b *inBroker) stateWithTimer() bool {
 // be accepted. Thusly all producers will block. Closing a producer, unblocks
 // the producer. The producers event (after close) might be processed or
 // ignored in the future.
+// If the full policy is drop_oldest, the broker asks the output broker to drop
+// unread events each time flushing fails. Events already forwarded to the
+// outputs are removed from the queue, but are still published by the outputs.
 //
 // stateBlocked transitions:
 //   -> stateEmpty if flush was successfull and write buffer is empty
@@ -417,6 +433,7 @@ func (b *inBroker) stateBlocked() bool {
 		err := b.flushBuffer()
 		if err != nil {
 			log.Debug("  inbroker (stateBlocked): flush failed, blocking")
+			b.requestDrop()
 			break
 		}
 
@@ -426,6 +443,7 @@ func (b *inBroker) stateBlocked() bool {
 			err := b.writeEvent(tmp)
 			if err != nil || len(b.pending) > 0 {
 				log.Debug("writing pending event failed: ", err)
+				b.requestDrop()
 				break
 			}
 		}
@@ -443,6 +461,29 @@ func (b *inBroker) stateBlocked() bool {
 	return true
 }
 
+// block switches the broker to stateBlocked, after a write or flush failed
+// due to the queue being full.
+func (b *inBroker) block() {
+	b.state = (*inBroker).stateBlocked
+	b.requestDrop()
+}
+
+// requestDrop asks the output broker to drop old events, so to make space for
+// the currently buffered events. No events are dropped if the spool is
+// configured to block.
+func (b *inBroker) requestDrop() {
+	if b.dropOldest == nil {
+		return
+	}
+
+	n := b.bufferedEvents
+	if n == 0 {
+		n = 1
+	}
+	b.ctx.logger.Debugf("inbroker: queue full, request to drop %v events", n)
+	b.dropOldest(n)
+}
+
 func (b *inBroker) handleCancel(req *producerCancelRequest) {
 	// mark state as cancelled, so to not accept any new events
 	// from the state object.
@@ -0,0 +1,110 @@
+package spool
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/go-txfile"
+	"github.com/elastic/go-txfile/pq"
+)
+
+// FileInfo describes the state of a spool file.
+type FileInfo struct {
+	Path     string
+	PageSize int
+	FileSize int64  // size of the file on disk
+	Events   uint64 // number of events not yet ACKed by the outputs
+}
+
+var errFileInUse = errors.New("spool file is in use by another process")
+
+// FilePath returns the spool file path configured in the spool settings.
+func FilePath(cfg *common.Config) (string, error) {
+	config := defaultConfig()
+	if cfg != nil {
+		if err := cfg.Unpack(&config); err != nil {
+			return "", err
+		}
+	}
+	return resolvePath(config.File.Path), nil
+}
+
+// Inspect reads the spool file at path, without modifying it. The file must
+// not be in use by a running beat.
+// fn is called for up to limit events in the queue, oldest event first. All
+// events are passed to fn if limit is negative. Events that can not be decoded
+// are reported with the decoding error.
+func Inspect(
+	path string,
+	limit int,
+	fn func(event beat.Event, err error) error,
+) (FileInfo, error) {
+	info := FileInfo{Path: path}
+
+	stat, err := os.Stat(path)
+	if err != nil {
+		return info, err
+	}
+	info.FileSize = stat.Size()
+	if err := checkNotLocked(path); err != nil {
+		return info, err
+	}
+
+	f, err := txfile.Open(path, 0, txfile.Options{Readonly: true})
+	if err != nil {
+		return info, fmt.Errorf("failed to open spool file '%v': %v", path, err)
+	}
+	defer f.Close()
+
+	delegate, err := pq.NewStandaloneDelegate(f)
+	if err != nil {
+		return info, err
+	}
+
+	// The queue is not closed, as closing would flush the (empty) write buffer.
+	queue, err := pq.New(delegate, pq.Settings{})
+	if err != nil {
+		return info, err
+	}
+
+	active, err := queue.Active()
+	if err != nil {
+		return info, err
+	}
+
+	info.PageSize = f.PageSize()
+	info.Events = uint64(active)
+	if limit == 0 || fn == nil {
+		return info, nil
+	}
+
+	reader := queue.Reader()
+	if available := reader.Available(); limit < 0 || uint(limit) > available {
+		limit = int(available)
+	}
+	dec := newDecoder()
+	for i := 0; i < limit; i++ {
+		sz, err := reader.Next()
+		if err != nil {
+			return info, err
+		}
+		if sz <= 0 {
+			break
+		}
+
+		buf := dec.Buffer(sz)
+		if _, err := reader.Read(buf); err != nil {
+			return info, err
+		}
+
+		event, err := dec.Decode()
+		if err := fn(event.Content, err); err != nil {
+			return info, err
+		}
+	}
+
+	return info, nil
+}
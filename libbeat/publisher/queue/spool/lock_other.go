@@ -0,0 +1,9 @@
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package spool
+
+// checkNotLocked is not supported on this platform. Opening a file in use
+// blocks until the lock is released.
+func checkNotLocked(path string) error {
+	return nil
+}
@@ -0,0 +1,28 @@
+// +build darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package spool
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// checkNotLocked returns errFileInUse if another process holds a lock on the
+// file at path.
+func checkNotLocked(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	err = unix.Flock(int(f.Fd()), unix.LOCK_EX|unix.LOCK_NB)
+	if err == unix.EWOULDBLOCK {
+		return errFileInUse
+	}
+	if err != nil {
+		return err
+	}
+	return unix.Flock(int(f.Fd()), unix.LOCK_UN)
+}
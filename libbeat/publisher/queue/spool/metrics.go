@@ -0,0 +1,113 @@
+package spool
+
+import (
+	"sync"
+	"time"
+
+	"github.com/elastic/beats/libbeat/monitoring"
+)
+
+// metrics reports the spool state to a monitoring registry. All metrics
+// are optional, a nil registry disables reporting.
+type metrics struct {
+	reg *monitoring.Registry
+
+	dropped *monitoring.Uint // events removed by the drop_oldest policy
+	ages    ageTracker
+
+	// stats returns the number of events not yet ACKed. Returns false if the
+	// spool has been closed already.
+	stats func() (uint, bool)
+}
+
+func newMetrics(reg *monitoring.Registry, stats func() (uint, bool)) *metrics {
+	m := &metrics{
+		reg:   reg,
+		stats: stats,
+	}
+
+	if reg != nil {
+		m.dropped = monitoring.NewUint(reg, "events.dropped")
+		monitoring.NewFunc(reg, "state", m.report, monitoring.Report)
+	}
+	return m
+}
+
+func (m *metrics) onDrop(n uint) {
+	if m.dropped != nil {
+		m.dropped.Add(uint64(n))
+	}
+}
+
+// report reports the number of active events and the age of the oldest
+// active event.
+func (m *metrics) report(_ monitoring.Mode, V monitoring.Visitor) {
+	V.OnRegistryStart()
+	defer V.OnRegistryFinished()
+
+	events, ok := m.stats()
+	if !ok {
+		return
+	}
+
+	monitoring.ReportInt(V, "events", int64(events))
+
+	var age time.Duration
+	if ts, ok := m.ages.oldest(); ok {
+		age = time.Since(ts)
+	}
+	monitoring.ReportInt(V, "oldest_event.age.ms", int64(age/time.Millisecond))
+}
+
+// ageTracker keeps the time events have been flushed to the spool file in
+// order, so to report the age of the oldest event not yet ACKed. Events
+// are tracked in groups per flush.
+type ageTracker struct {
+	mu     sync.Mutex
+	groups []ageGroup
+}
+
+type ageGroup struct {
+	count uint
+	ts    time.Time
+}
+
+// add records n events being flushed at ts.
+func (t *ageTracker) add(n uint, ts time.Time) {
+	if n == 0 {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.groups = append(t.groups, ageGroup{count: n, ts: ts})
+}
+
+// remove removes the n oldest events.
+func (t *ageTracker) remove(n uint) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for n > 0 && len(t.groups) > 0 {
+		g := &t.groups[0]
+		if g.count > n {
+			g.count -= n
+			return
+		}
+
+		n -= g.count
+		t.groups[0] = ageGroup{}
+		t.groups = t.groups[1:]
+	}
+}
+
+// oldest returns the flush time of the oldest tracked event.
+func (t *ageTracker) oldest() (time.Time, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if len(t.groups) == 0 {
+		return time.Time{}, false
+	}
+	return t.groups[0].ts, true
+}
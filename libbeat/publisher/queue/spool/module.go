@@ -3,6 +3,7 @@ package spool
 import (
 	"github.com/elastic/beats/libbeat/common"
 	"github.com/elastic/beats/libbeat/common/cfgwarn"
+	"github.com/elastic/beats/libbeat/monitoring"
 	"github.com/elastic/beats/libbeat/paths"
 	"github.com/elastic/beats/libbeat/publisher/queue"
 	"github.com/elastic/go-txfile"
@@ -20,10 +21,7 @@ func create(eventer queue.Eventer, cfg *common.Config) (queue.Queue, error) {
 		return nil, err
 	}
 
-	path := config.File.Path
-	if path == "" {
-		path = paths.Resolve(paths.Data, "spool.dat")
-	}
+	path := resolvePath(config.File.Path)
 
 	flushEvents := uint(0)
 	if count := config.Write.FlushEvents; count > 0 {
@@ -38,6 +36,9 @@ func create(eventer queue.Eventer, cfg *common.Config) (queue.Queue, error) {
 		WriteFlushEvents:  flushEvents,
 		ReadFlushTimeout:  config.Read.FlushTimeout,
 		Codec:             config.Write.Codec,
+		FullPolicy:        config.Write.FullPolicy,
+		Recover:           config.File.Recover,
+		Metrics:           newMetricsRegistry(),
 		File: txfile.Options{
 			MaxSize:  uint64(config.File.MaxSize),
 			PageSize: uint32(config.File.PageSize),
@@ -46,3 +47,22 @@ func create(eventer queue.Eventer, cfg *common.Config) (queue.Queue, error) {
 		},
 	})
 }
+
+func resolvePath(path string) string {
+	if path == "" {
+		return paths.Resolve(paths.Data, "spool.dat")
+	}
+	return path
+}
+
+// newMetricsRegistry creates the registry for reporting spool metrics in the
+// beats 'libbeat' registry. Metrics of a previous spool instance are removed.
+func newMetricsRegistry() *monitoring.Registry {
+	reg := monitoring.Default.GetRegistry("libbeat")
+	if reg == nil {
+		return nil
+	}
+
+	reg.Remove("queue.spool")
+	return reg.NewRegistry("queue.spool")
+}
@@ -15,6 +15,7 @@ type outBroker struct {
 
 	// internal API
 	sigFlushed chan uint
+	sigDrop    chan uint
 	get        chan getRequest
 
 	// ack signaling
@@ -31,8 +32,9 @@ type outBroker struct {
 	active    getRequest
 
 	// internal
-	timer *timer
-	dec   *decoder
+	timer  *timer
+	dec    *decoder
+	onDrop func(n uint)
 }
 
 type chanList struct {
@@ -43,7 +45,8 @@ type chanList struct {
 type ackChan struct {
 	next  *ackChan
 	ch    chan batchAckMsg
-	total int // total number of events to ACK with this batch
+	total int  // total number of events to ACK with this batch
+	force bool // ACK this batch and all batches before, without waiting for the outputs
 }
 
 const (
@@ -63,13 +66,19 @@ var ackChanPool = sync.Pool{
 
 var errRetry = errors.New("retry")
 
-func newOutBroker(ctx *spoolCtx, qu *pq.Queue, flushTimeout time.Duration) (*outBroker, error) {
+func newOutBroker(
+	ctx *spoolCtx,
+	qu *pq.Queue,
+	flushTimeout time.Duration,
+	onDrop func(uint),
+) (*outBroker, error) {
 	b := &outBroker{
 		ctx:   ctx,
 		state: nil,
 
 		// API
 		sigFlushed: make(chan uint, outSigChannelSize),
+		sigDrop:    make(chan uint, 1),
 		get:        make(chan getRequest),
 
 		// ack signaling
@@ -86,8 +95,9 @@ func newOutBroker(ctx *spoolCtx, qu *pq.Queue, flushTimeout time.Duration) (*out
 		active:    getRequest{},
 
 		// internal
-		timer: newTimer(flushTimeout),
-		dec:   newDecoder(),
+		timer:  newTimer(flushTimeout),
+		dec:    newDecoder(),
+		onDrop: onDrop,
 	}
 
 	b.initState()
@@ -118,6 +128,15 @@ func (b *outBroker) onFlush(n uint) {
 func (b *outBroker) onACK(events, pages uint) {
 }
 
+// requestDrop asks the broker to drop up to n of the oldest unread events.
+// Requests are ignored if a request is still pending.
+func (b *outBroker) requestDrop(n uint) {
+	select {
+	case b.sigDrop <- n:
+	default:
+	}
+}
+
 func (b *outBroker) ackLoop() {
 	log := b.ctx.logger
 
@@ -132,24 +151,62 @@ func (b *outBroker) ackLoop() {
 
 		case lst := <-b.scheduledACKs:
 			ackList.concat(&lst)
+			b.ackForced(&ackList)
 
 		case <-ackList.channel():
 			ackCh := ackList.pop()
+			log.Debugf("receive ACK of %v events\n", ackCh.total)
+			b.ackEvents(uint(ackCh.total))
+			releaseACKChan(ackCh)
+		}
+	}
+}
 
-			for {
-				log.Debugf("receive ACK of %v events\n", ackCh.total)
-				err := b.queue.ACK(uint(ackCh.total))
-				if err != nil {
-					log.Debug("ack failed with:", err)
-					time.Sleep(1 * time.Second)
-					continue
-				}
-
-				log.Debug("ACK succeeded")
-				break
-			}
+// ackForced removes all events up to the last forced drop in lst from the
+// queue, without waiting for the outputs to ACK the batches read before.
+// The batches are kept in the list with a total of 0, such that the ACK
+// channels are not reused before the outputs have ACKed the batches.
+func (b *outBroker) ackForced(lst *chanList) {
+	var last *ackChan
+	for ch := lst.head; ch != nil; ch = ch.next {
+		if ch.force {
+			last = ch
+		}
+	}
+	if last == nil {
+		return
+	}
 
-			releaseACKChan(ackCh)
+	total := 0
+	for ch := lst.head; ; ch = ch.next {
+		total += ch.total
+		ch.total = 0
+		ch.force = false
+		if ch == last {
+			break
+		}
+	}
+
+	b.ctx.logger.Debugf("release %v events from queue for dropping events\n", total)
+	b.ackEvents(uint(total))
+}
+
+// ackEvents ACKs the n oldest events in the queue, retrying until the queue
+// has been updated.
+func (b *outBroker) ackEvents(n uint) {
+	log := b.ctx.logger
+	for {
+		err := b.queue.ACK(n)
+		if err == nil {
+			log.Debug("ACK succeeded")
+			return
+		}
+
+		log.Debug("ack failed with:", err)
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-time.After(1 * time.Second):
 		}
 	}
 }
@@ -195,6 +252,11 @@ func (b *outBroker) stateWaitEvents() bool {
 		b.available += n
 		b.state = (*outBroker).stateActive
 
+	case n := <-b.sigDrop:
+		// no unread events available, but the events held by the outputs are
+		// removed from the queue
+		b.dropEvents(n)
+
 	case b.schedACKs <- b.pendingACKs:
 		b.handleACKsScheduled()
 	}
@@ -222,6 +284,10 @@ func (b *outBroker) stateActive() bool {
 	case n := <-b.sigFlushed:
 		b.available += n
 
+	case n := <-b.sigDrop:
+		b.dropEvents(n)
+		b.initState()
+
 	case b.schedACKs <- b.pendingACKs:
 		b.handleACKsScheduled()
 
@@ -282,6 +348,8 @@ func (b *outBroker) stateActive() bool {
 
 // stateWithTimer is the brokers active state, if the events read is less then
 // the minimal number of requested events.
+// Drop requests are not handled in this state, as the events already read
+// are not part of a batch yet and can not be removed from the queue.
 // Once the timer triggers or more events have been consumed, the get response
 // will be send to the consumer.
 //
@@ -370,6 +438,54 @@ func (b *outBroker) signalDrop(total int) {
 	ackCh.ch <- batchAckMsg{}
 }
 
+// forceDrop forwards an ACK of total dropped events to the ackloop. Other
+// than signalDrop, the dropped events and all events read before are
+// removed from the queue right away, without waiting for the outputs to
+// ACK the batches they are still holding. The outputs continue publishing
+// these batches from memory, but the events are lost on restart.
+func (b *outBroker) forceDrop(total int) {
+	ackCh := b.newACKChan(total)
+	ackCh.force = true
+	ackCh.ch <- batchAckMsg{}
+}
+
+// dropEvents skips up to n unread events and removes the dropped events from
+// the queue. Events already read by the outputs are removed from the queue as
+// well, such that pages can be freed even if the outputs are blocked.
+func (b *outBroker) dropEvents(n uint) {
+	log := b.ctx.logger
+	reader := b.queue.Reader()
+
+	if n > b.available {
+		n = b.available
+	}
+
+	var count uint
+	for count < n {
+		sz, err := reader.Next()
+		if sz <= 0 || err != nil {
+			if err != nil {
+				log.Errorf("Failed to drop events from spool: %v", err)
+			}
+			break
+		}
+		count++
+	}
+
+	// Release the events held by the outputs, even if no unread event is left
+	// to be dropped.
+	b.forceDrop(int(count))
+	if count == 0 {
+		return
+	}
+
+	b.available -= count
+	log.Infof("Spool file is full. Dropped %v events.", count)
+	if b.onDrop != nil {
+		b.onDrop(count)
+	}
+}
+
 func (b *outBroker) returnEvents(req getRequest, events []publisher.Event, total int) {
 	ackCh := b.newACKChan(total)
 	req.resp <- getResponse{
@@ -441,6 +557,7 @@ func newACKChan(total int) *ackChan {
 	c := ackChanPool.Get().(*ackChan)
 	c.next = nil
 	c.total = total
+	c.force = false
 	return c
 }
 
@@ -9,6 +9,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/elastic/beats/libbeat/common/atomic"
+	"github.com/elastic/beats/libbeat/monitoring"
 	"github.com/elastic/beats/libbeat/publisher/queue"
 	"github.com/elastic/go-txfile"
 	"github.com/elastic/go-txfile/pq"
@@ -26,6 +27,13 @@ type Spool struct {
 
 	queue *pq.Queue
 	file  *txfile.File
+
+	// closeMu protects access to the file by the metrics reporter, while
+	// the spool is being closed.
+	closeMu sync.RWMutex
+	closed  bool
+
+	metrics *metrics
 }
 
 type spoolCtx struct {
@@ -53,8 +61,31 @@ type Settings struct {
 	ReadFlushTimeout  time.Duration
 
 	Codec codecID
+
+	// FullPolicy configures the behavior if the file is full.
+	FullPolicy fullPolicy
+
+	// Recover replaces the spool file with a new file, if the existing file
+	// is corrupted. The corrupted file is kept for inspection.
+	Recover bool
+
+	// Metrics is used to report the spool state. Metrics are not reported if
+	// nil.
+	Metrics *monitoring.Registry
 }
 
+// fullPolicy configures how producers are handled if the spool file is full.
+type fullPolicy uint8
+
+const (
+	// fullPolicyBlock blocks producers until the output ACKs events.
+	fullPolicyBlock fullPolicy = iota
+
+	// fullPolicyDropOldest removes the oldest events not yet read by the
+	// output, so to make space for new events.
+	fullPolicyDropOldest
+)
+
 const minInFlushTimeout = 100 * time.Millisecond
 const minOutFlushTimeout = 0 * time.Millisecond
 
@@ -86,56 +117,60 @@ func NewSpool(logger logger, path string, settings Settings) (*Spool, error) {
 		}
 	}
 
-	f, err := txfile.Open(path, mode, settings.File)
-	if err != nil {
-		return nil, errors.Wrapf(err, "spool queue: failed to open file at path '%s'", path)
-	}
-	defer ifNotOK(&ok, ignoreErr(f.Close))
-
-	queueDelegate, err := pq.NewStandaloneDelegate(f)
-	if err != nil {
-		return nil, err
-	}
-
 	spool := &Spool{
 		inCtx:  inCtx,
 		outCtx: outCtx,
 	}
 
-	queue, err := pq.New(queueDelegate, pq.Settings{
+	sf, err := openSpoolFile(logger, path, mode, settings.File, pq.Settings{
 		WriteBuffer: settings.WriteBuffer,
 		Flushed:     spool.onFlush,
 		ACKed:       spool.onACK,
-	})
+	}, settings.Recover)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "spool queue: failed to open file at path '%s'", path)
 	}
+	f, queue := sf.file, sf.queue
+	defer ifNotOK(&ok, ignoreErr(f.Close))
 	defer ifNotOK(&ok, ignoreErr(queue.Close))
 
-	inFlushTimeout := settings.WriteFlushTimeout
-	if inFlushTimeout < minInFlushTimeout {
-		inFlushTimeout = minInFlushTimeout
-	}
-	inBroker, err := newInBroker(inCtx, settings.Eventer, queue, settings.Codec,
-		inFlushTimeout, settings.WriteFlushEvents)
+	active, err := queue.Active()
 	if err != nil {
 		return nil, err
 	}
 
+	spool.file = f
+	spool.queue = queue
+	spool.metrics = newMetrics(settings.Metrics, spool.stats)
+	spool.metrics.ages.add(active, time.Now())
+
 	outFlushTimeout := settings.ReadFlushTimeout
 	if outFlushTimeout < minOutFlushTimeout {
 		outFlushTimeout = minOutFlushTimeout
 	}
-	outBroker, err := newOutBroker(outCtx, queue, outFlushTimeout)
+	outBroker, err := newOutBroker(outCtx, queue, outFlushTimeout, spool.metrics.onDrop)
+	if err != nil {
+		return nil, err
+	}
+
+	var dropOldest func(uint)
+	if settings.FullPolicy == fullPolicyDropOldest {
+		dropOldest = outBroker.requestDrop
+	}
+
+	inFlushTimeout := settings.WriteFlushTimeout
+	if inFlushTimeout < minInFlushTimeout {
+		inFlushTimeout = minInFlushTimeout
+	}
+	inBroker, err := newInBroker(inCtx, settings.Eventer, queue, settings.Codec,
+		inFlushTimeout, settings.WriteFlushEvents, dropOldest)
 	if err != nil {
 		return nil, err
 	}
 
 	ok = true
-	spool.queue = queue
 	spool.inBroker = inBroker
 	spool.outBroker = outBroker
-	spool.file = f
 	return spool, nil
 }
 
@@ -149,11 +184,30 @@ func (s *Spool) Close() error {
 	err := s.queue.Close()
 
 	// finally unmap and close file
+	s.closeMu.Lock()
+	s.closed = true
 	s.file.Close()
+	s.closeMu.Unlock()
 
 	return err
 }
 
+// stats returns the number of events not yet ACKed for reporting.
+func (s *Spool) stats() (uint, bool) {
+	s.closeMu.RLock()
+	defer s.closeMu.RUnlock()
+
+	if s.closed {
+		return 0, false
+	}
+
+	active, err := s.queue.Active()
+	if err != nil {
+		return 0, false
+	}
+	return active, true
+}
+
 // BufferConfig returns the queue initial buffer settings.
 func (s *Spool) BufferConfig() queue.BufferConfig {
 	return queue.BufferConfig{Events: -1}
@@ -174,6 +228,7 @@ func (s *Spool) Consumer() queue.Consumer {
 // The onFlush callback is directly called by the queue writer (same go-routine)
 // on Write or Flush operations.
 func (s *Spool) onFlush(n uint) {
+	s.metrics.ages.add(n, time.Now())
 	s.inBroker.onFlush(n)
 	s.outBroker.onFlush(n)
 }
@@ -182,6 +237,7 @@ func (s *Spool) onFlush(n uint) {
 // the queue.
 // ACK events are forwarded to all workers.
 func (s *Spool) onACK(events, pages uint) {
+	s.metrics.ages.remove(events)
 	s.inBroker.onACK(events, pages)
 }
 
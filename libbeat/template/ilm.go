@@ -0,0 +1,245 @@
+package template
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+	"github.com/elastic/beats/libbeat/logp"
+	"github.com/elastic/beats/libbeat/paths"
+)
+
+// ILMConfig configures index lifecycle management for the Elasticsearch
+// output. If enabled, events are written to a rollover alias instead of
+// daily indices.
+type ILMConfig struct {
+	Enabled       bool   `config:"enabled"`
+	RolloverAlias string `config:"rollover_alias"`
+	Pattern       string `config:"pattern"`
+	PolicyName    string `config:"policy_name"`
+	PolicyFile    string `config:"policy_file"`
+	Overwrite     bool   `config:"overwrite"`
+}
+
+var (
+	// DefaultILMConfig for index lifecycle management
+	DefaultILMConfig = ILMConfig{
+		Pattern: "000001",
+	}
+
+	// DefaultILMPolicy rolls over the write index once it is 30 days old or
+	// grew to 50GB.
+	DefaultILMPolicy = common.MapStr{
+		"policy": common.MapStr{
+			"phases": common.MapStr{
+				"hot": common.MapStr{
+					"actions": common.MapStr{
+						"rollover": common.MapStr{
+							"max_size": "50gb",
+							"max_age":  "30d",
+						},
+					},
+				},
+			},
+		},
+	}
+
+	// minimum Elasticsearch version supporting index lifecycle management
+	ilmMinVersion, _ = common.NewVersion("6.6.0")
+)
+
+func (c *ILMConfig) Validate() error {
+	if c.Pattern == "" {
+		return errors.New("ilm.pattern must not be empty")
+	}
+	if strings.Trim(c.Pattern, "0123456789") != "" {
+		return fmt.Errorf("ilm.pattern '%v' must be numeric", c.Pattern)
+	}
+	return nil
+}
+
+// Alias returns the name of the rollover alias events are written to.
+// The default alias is <beat>-<version>.
+func (c *ILMConfig) Alias(beatInfo beat.Info) string {
+	if c.RolloverAlias != "" {
+		return c.RolloverAlias
+	}
+	return fmt.Sprintf("%s-%s", beatInfo.IndexPrefix, beatInfo.Version)
+}
+
+// Policy returns the name of the lifecycle policy. It defaults to the beat
+// index prefix.
+func (c *ILMConfig) Policy(beatInfo beat.Info) string {
+	if c.PolicyName != "" {
+		return c.PolicyName
+	}
+	return beatInfo.IndexPrefix
+}
+
+// ReadILMConfig reads the `ilm` namespace of the given Elasticsearch output
+// configuration.
+func ReadILMConfig(cfg *common.Config) (ILMConfig, error) {
+	config := DefaultILMConfig
+	if cfg == nil || !cfg.HasField("ilm") {
+		return config, nil
+	}
+
+	sub, err := cfg.Child("ilm", -1)
+	if err != nil {
+		return config, err
+	}
+	if err := sub.Unpack(&config); err != nil {
+		return config, err
+	}
+	return config, nil
+}
+
+// ILMLoader installs the lifecycle policy and bootstraps the rollover alias.
+type ILMLoader struct {
+	config   ILMConfig
+	client   ESClient
+	beatInfo beat.Info
+}
+
+// NewILMLoader creates a new ILMLoader for the given configuration.
+func NewILMLoader(config ILMConfig, client ESClient, beatInfo beat.Info) *ILMLoader {
+	return &ILMLoader{
+		config:   config,
+		client:   client,
+		beatInfo: beatInfo,
+	}
+}
+
+// CheckSupported returns an error if the Elasticsearch version the client is
+// connected to does not support index lifecycle management.
+func (l *ILMLoader) CheckSupported() error {
+	esVersion, err := common.NewVersion(l.client.GetVersion())
+	if err != nil {
+		return fmt.Errorf("failed to parse Elasticsearch version: %v", err)
+	}
+	if esVersion.LessThan(ilmMinVersion) {
+		return fmt.Errorf("index lifecycle management requires Elasticsearch %v or newer, found %v",
+			ilmMinVersion, esVersion)
+	}
+	return nil
+}
+
+// TemplateConfig returns the template configuration merged with the index
+// settings required to manage new indices by the lifecycle policy. The
+// template name and pattern default to the rollover alias.
+func (l *ILMLoader) TemplateConfig(cfg *common.Config) (*common.Config, error) {
+	alias := l.config.Alias(l.beatInfo)
+
+	settings := common.MapStr{
+		"settings": common.MapStr{
+			"index": common.MapStr{
+				"lifecycle": common.MapStr{
+					"name":           l.config.Policy(l.beatInfo),
+					"rollover_alias": alias,
+				},
+			},
+		},
+	}
+	if !cfg.HasField("name") {
+		settings["name"] = alias
+	}
+	if !cfg.HasField("pattern") {
+		settings["pattern"] = alias + "-*"
+	}
+
+	ilmCfg, err := common.NewConfigFrom(settings)
+	if err != nil {
+		return nil, err
+	}
+	return common.MergeConfigs(cfg, ilmCfg)
+}
+
+// LoadPolicy installs the lifecycle policy. An existing policy is only
+// replaced if overwrite is enabled.
+func (l *ILMLoader) LoadPolicy() error {
+	name := l.config.Policy(l.beatInfo)
+
+	if !l.config.Overwrite && l.CheckPolicy(name) {
+		logp.Info("ILM policy %s already exists and will not be overwritten.", name)
+		return nil
+	}
+
+	policy := DefaultILMPolicy
+	if l.config.PolicyFile != "" {
+		var err error
+		policy, err = readPolicy(paths.Resolve(paths.Config, l.config.PolicyFile))
+		if err != nil {
+			return err
+		}
+	}
+
+	body, err := l.client.LoadJSON("/_ilm/policy/"+name, policy)
+	if err != nil {
+		return fmt.Errorf("couldn't load ILM policy: %v. Response body: %s", err, body)
+	}
+	logp.Info("ILM policy with name '%s' loaded", name)
+	return nil
+}
+
+// CheckPolicy checks if the lifecycle policy already exists.
+func (l *ILMLoader) CheckPolicy(name string) bool {
+	status, _, _ := l.client.Request("GET", "/_ilm/policy/"+name, "", nil, nil)
+	return status == 200
+}
+
+// BootstrapAlias creates the first index for the rollover alias and marks it
+// as write index, unless the alias already exists.
+func (l *ILMLoader) BootstrapAlias() error {
+	alias := l.config.Alias(l.beatInfo)
+
+	if l.CheckAlias(alias) {
+		logp.Info("Rollover alias %s already exists.", alias)
+		return nil
+	}
+
+	index := fmt.Sprintf("%s-%s", alias, l.config.Pattern)
+	body := common.MapStr{
+		"aliases": common.MapStr{
+			alias: common.MapStr{
+				"is_write_index": true,
+			},
+		},
+	}
+
+	status, resp, err := l.client.Request("PUT", "/"+index, "", nil, body)
+	if err != nil {
+		// another beat instance might have bootstrapped the alias concurrently
+		if status == 400 && strings.Contains(string(resp), "resource_already_exists_exception") {
+			return nil
+		}
+		return fmt.Errorf("couldn't bootstrap rollover alias %s: %v", alias, err)
+	}
+	logp.Info("Rollover alias %s bootstrapped with index %s", alias, index)
+	return nil
+}
+
+// CheckAlias checks if the given alias already exists.
+func (l *ILMLoader) CheckAlias(alias string) bool {
+	status, _, _ := l.client.Request("HEAD", "/_alias/"+alias, "", nil, nil)
+	return status == 200
+}
+
+func readPolicy(path string) (common.MapStr, error) {
+	contents, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ILM policy file %s: %v", path, err)
+	}
+
+	var policy common.MapStr
+	if err := json.Unmarshal(contents, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse ILM policy file %s: %v", path, err)
+	}
+	if _, ok := policy["policy"]; !ok {
+		return nil, fmt.Errorf("ILM policy file %s is missing the 'policy' object", path)
+	}
+	return policy, nil
+}
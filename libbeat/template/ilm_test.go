@@ -0,0 +1,215 @@
+// +build !integration
+
+package template
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/elastic/beats/libbeat/beat"
+	"github.com/elastic/beats/libbeat/common"
+)
+
+type fakeRequest struct {
+	method string
+	path   string
+	body   interface{}
+}
+
+type fakeESClient struct {
+	version  string
+	existing map[string]bool
+	requests []fakeRequest
+}
+
+func newFakeESClient(version string, existing ...string) *fakeESClient {
+	c := &fakeESClient{version: version, existing: map[string]bool{}}
+	for _, path := range existing {
+		c.existing[path] = true
+	}
+	return c
+}
+
+func (c *fakeESClient) LoadJSON(path string, json map[string]interface{}) ([]byte, error) {
+	_, body, err := c.Request("PUT", path, "", nil, json)
+	return body, err
+}
+
+func (c *fakeESClient) Request(method, path string, pipeline string, params map[string]string, body interface{}) (int, []byte, error) {
+	c.requests = append(c.requests, fakeRequest{method: method, path: path, body: body})
+	if method == "PUT" {
+		c.existing[path] = true
+		return 200, nil, nil
+	}
+	if c.existing[path] {
+		return 200, nil, nil
+	}
+	return 404, nil, errors.New("404 Not Found")
+}
+
+func (c *fakeESClient) GetVersion() string {
+	return c.version
+}
+
+func (c *fakeESClient) writes() []fakeRequest {
+	var writes []fakeRequest
+	for _, r := range c.requests {
+		if r.method == "PUT" {
+			writes = append(writes, r)
+		}
+	}
+	return writes
+}
+
+var ilmTestInfo = beat.Info{Beat: "testbeat", IndexPrefix: "testbeat", Version: "6.3.1"}
+
+func TestILMCheckSupported(t *testing.T) {
+	config := DefaultILMConfig
+
+	assert.NoError(t, NewILMLoader(config, newFakeESClient("6.6.0"), ilmTestInfo).CheckSupported())
+	assert.NoError(t, NewILMLoader(config, newFakeESClient("7.0.0"), ilmTestInfo).CheckSupported())
+	assert.Error(t, NewILMLoader(config, newFakeESClient("6.5.4"), ilmTestInfo).CheckSupported())
+}
+
+func TestILMLoadPolicy(t *testing.T) {
+	client := newFakeESClient("6.6.0")
+	loader := NewILMLoader(DefaultILMConfig, client, ilmTestInfo)
+
+	assert.NoError(t, loader.LoadPolicy())
+	writes := client.writes()
+	if assert.Len(t, writes, 1) {
+		assert.Equal(t, "/_ilm/policy/testbeat", writes[0].path)
+		assert.Equal(t, map[string]interface{}(DefaultILMPolicy), writes[0].body)
+	}
+
+	// existing policy is not overwritten by default
+	assert.NoError(t, loader.LoadPolicy())
+	assert.Len(t, client.writes(), 1)
+
+	config := DefaultILMConfig
+	config.Overwrite = true
+	assert.NoError(t, NewILMLoader(config, client, ilmTestInfo).LoadPolicy())
+	assert.Len(t, client.writes(), 2)
+}
+
+func TestILMLoadPolicyFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ilm")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "policy.json")
+	policy := `{"policy": {"phases": {"delete": {"min_age": "7d", "actions": {"delete": {}}}}}}`
+	if err := ioutil.WriteFile(path, []byte(policy), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	client := newFakeESClient("6.6.0")
+	config := DefaultILMConfig
+	config.PolicyName = "custom"
+	config.PolicyFile = path
+
+	assert.NoError(t, NewILMLoader(config, client, ilmTestInfo).LoadPolicy())
+	writes := client.writes()
+	if assert.Len(t, writes, 1) {
+		assert.Equal(t, "/_ilm/policy/custom", writes[0].path)
+		phases, err := common.MapStr(writes[0].body.(map[string]interface{})).GetValue("policy.phases")
+		assert.NoError(t, err)
+		assert.Contains(t, phases, "delete")
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"phases": {}}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+	config.Overwrite = true
+	assert.Error(t, NewILMLoader(config, client, ilmTestInfo).LoadPolicy())
+}
+
+func TestILMBootstrapAlias(t *testing.T) {
+	client := newFakeESClient("6.6.0")
+	loader := NewILMLoader(DefaultILMConfig, client, ilmTestInfo)
+
+	assert.NoError(t, loader.BootstrapAlias())
+	writes := client.writes()
+	if assert.Len(t, writes, 1) {
+		assert.Equal(t, "/testbeat-6.3.1-000001", writes[0].path)
+		assert.Equal(t, common.MapStr{
+			"aliases": common.MapStr{
+				"testbeat-6.3.1": common.MapStr{"is_write_index": true},
+			},
+		}, writes[0].body)
+	}
+
+	// alias already exists
+	client = newFakeESClient("6.6.0", "/_alias/testbeat-6.3.1")
+	assert.NoError(t, NewILMLoader(DefaultILMConfig, client, ilmTestInfo).BootstrapAlias())
+	assert.Len(t, client.writes(), 0)
+}
+
+func TestILMTemplateConfig(t *testing.T) {
+	config := DefaultILMConfig
+	config.RolloverAlias = "logs"
+	loader := NewILMLoader(config, newFakeESClient("6.6.0"), ilmTestInfo)
+
+	cfg, err := loader.TemplateConfig(common.NewConfig())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tmplCfg := DefaultConfig
+	if err := cfg.Unpack(&tmplCfg); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "logs", tmplCfg.Name)
+	assert.Equal(t, "logs-*", tmplCfg.Pattern)
+	assert.Equal(t, map[string]interface{}{
+		"lifecycle": map[string]interface{}{
+			"name":           "testbeat",
+			"rollover_alias": "logs",
+		},
+	}, tmplCfg.Settings.Index)
+
+	// configured template name and pattern are kept
+	cfg, err = common.NewConfigFrom(map[string]interface{}{
+		"name":    "custom",
+		"pattern": "custom-*",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg, err = loader.TemplateConfig(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmplCfg = DefaultConfig
+	if err := cfg.Unpack(&tmplCfg); err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, "custom", tmplCfg.Name)
+	assert.Equal(t, "custom-*", tmplCfg.Pattern)
+}
+
+func TestILMConfigValidate(t *testing.T) {
+	for pattern, ok := range map[string]bool{
+		"000001": true,
+		"1":      true,
+		"":       false,
+		"{now}":  false,
+	} {
+		cfg, err := common.NewConfigFrom(map[string]interface{}{
+			"ilm.enabled": true,
+			"ilm.pattern": pattern,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = ReadILMConfig(cfg)
+		assert.Equal(t, ok, err == nil, pattern)
+	}
+}